@@ -0,0 +1,120 @@
+// migrate-storage copies the contents of json files and their revisions from one storage backend to another,
+// for example to move existing files from S3 into postgres before setting STORAGE_BACKEND=postgres.
+// Every copy is read back from the destination and compared with the source. Once everything is copied,
+// the url of every json file is pointed at the destination.
+// It reads the same environment variables as the api, and can be run again safely since every copy overwrites.
+//
+//	go run ./cmd/migrate-storage -from s3 -to postgres
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/joho/godotenv"
+	_ "github.com/lib/pq"
+	"github.com/pl3lee/restjson/internal/database"
+	"github.com/pl3lee/restjson/internal/storage"
+)
+
+func main() {
+	from := flag.String("from", "s3", "backend to copy from: s3, filesystem or postgres")
+	to := flag.String("to", "postgres", "backend to copy to: s3, filesystem or postgres")
+	prefix := flag.String("prefix", "", "only copy keys starting with prefix, e.g. a user id")
+	dryRun := flag.Bool("dry-run", false, "list the keys that would be copied without copying them")
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		log.Printf("Cannot read .env file, using the environment: %v\n", err)
+	}
+	if *from == *to {
+		log.Fatal("-from and -to must be different backends")
+	}
+	if *from == "memory" || *to == "memory" {
+		log.Fatal("memory storage only lives as long as the api, there is nothing to migrate")
+	}
+
+	// the database is needed for the urls of the json files, even when neither backend is postgres
+	dbUrl := os.Getenv("DB_URL")
+	if dbUrl == "" {
+		log.Fatal("DB_URL not set")
+	}
+	pgDb, err := sql.Open("postgres", dbUrl)
+	if err != nil {
+		log.Fatalf("cannot open database: %v", err)
+	}
+	defer pgDb.Close()
+	dbQueries := database.New(pgDb)
+
+	ctx := context.Background()
+	source := openStore(ctx, *from, dbQueries)
+	destination := openStore(ctx, *to, dbQueries)
+
+	keys, err := source.List(ctx, *prefix)
+	if err != nil {
+		log.Fatalf("cannot list %s storage: %v", *from, err)
+	}
+	log.Printf("Copying %d keys from %s to %s", len(keys), *from, *to)
+
+	for i, key := range keys {
+		if *dryRun {
+			log.Println(key)
+			continue
+		}
+		data, err := source.Get(ctx, key)
+		if err != nil {
+			log.Fatalf("cannot get %s: %v", key, err)
+		}
+		if _, err := destination.Put(ctx, key, bytes.NewReader(data)); err != nil {
+			log.Fatalf("cannot put %s: %v", key, err)
+		}
+		copied, err := destination.Get(ctx, key)
+		if err != nil {
+			log.Fatalf("cannot read back %s: %v", key, err)
+		}
+		if !bytes.Equal(data, copied) {
+			log.Fatalf("copy of %s differs from the source: read %s from %s, %s from %s", key, storage.Checksum(data), *from, storage.Checksum(copied), *to)
+		}
+		if (i+1)%100 == 0 {
+			log.Printf("Copied %d/%d keys", i+1, len(keys))
+		}
+	}
+	if *dryRun {
+		return
+	}
+	log.Printf("Copied %d keys from %s to %s", len(keys), *from, *to)
+
+	// only once every copy is verified, so that an interrupted run leaves the urls pointing at the source
+	updated := 0
+	for _, key := range keys {
+		_, fileId, ok := storage.ParseFileKey(key)
+		if !ok {
+			continue
+		}
+		err := dbQueries.UpdateJsonFileUrl(ctx, database.UpdateJsonFileUrlParams{
+			ID:  fileId,
+			Url: destination.URL(key),
+		})
+		if err != nil {
+			log.Fatalf("cannot update the url of json file %s: %v", fileId, err)
+		}
+		updated++
+	}
+	log.Printf("Updated the url of %d json files", updated)
+}
+
+func openStore(ctx context.Context, backend string, dbQueries *database.Queries) storage.Store {
+	storageCfg, err := storage.ConfigFromEnv(backend, dbQueries)
+	if err != nil {
+		log.Fatal(err)
+	}
+	store, err := storage.New(ctx, storageCfg)
+	if err != nil {
+		log.Fatalf("cannot open %s storage: %v", backend, err)
+	}
+	return store
+}
@@ -10,8 +10,6 @@ import (
 	"strconv"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/go-chi/cors"
@@ -22,6 +20,7 @@ import (
 	"github.com/pl3lee/restjson/internal/jsonfile"
 	"github.com/pl3lee/restjson/internal/payment"
 	"github.com/pl3lee/restjson/internal/ratelimit"
+	"github.com/pl3lee/restjson/internal/storage"
 	"github.com/pl3lee/restjson/internal/utils"
 	"github.com/redis/go-redis/v9"
 )
@@ -34,12 +33,13 @@ type appConfig struct {
 	googleClientID      string
 	googleClientSecret  string
 	db                  *database.Queries
-	s3Bucket            string
-	s3Region            string
-	s3Client            *s3.Client
+	store               storage.Store
 	rdb                 *redis.Client
 	freeFileLimit       int
 	proFileLimit        int
+	freeMaxFileSize     int64
+	proMaxFileSize      int64
+	maxRevisions        int
 	stripeSecretKey     string
 	stripeWebhookSecret string
 }
@@ -72,14 +72,6 @@ func loadAppConfig() *appConfig {
 	if googleClientSecret == "" {
 		log.Fatal("GOOGLE_CLIENT_SECRET not set")
 	}
-	s3Bucket := os.Getenv("S3_BUCKET")
-	if s3Bucket == "" {
-		log.Fatal("S3_BUCKET not set")
-	}
-	s3Region := os.Getenv("S3_REGION")
-	if s3Region == "" {
-		log.Fatal("S3_REGION not set")
-	}
 	redisUrl := os.Getenv("REDIS_URL")
 	if redisUrl == "" {
 		log.Fatal("REDIS_URL not set")
@@ -104,6 +96,17 @@ func loadAppConfig() *appConfig {
 	if err != nil {
 		log.Fatal("file limit should be an integer")
 	}
+	// 1 MB on the free plan and 10 MB on the pro plan unless configured
+	freeMaxFileSize := maxFileSizeFromEnv("FREE_MAX_FILE_SIZE", 1<<20)
+	proMaxFileSize := maxFileSizeFromEnv("PRO_MAX_FILE_SIZE", 10<<20)
+	// the last 100 revisions of each file are kept unless configured, 0 keeps all of them
+	maxRevisions := 100
+	if maxRevisionsStr := os.Getenv("MAX_REVISIONS"); maxRevisionsStr != "" {
+		maxRevisions, err = strconv.Atoi(maxRevisionsStr)
+		if err != nil || maxRevisions < 0 {
+			log.Fatal("MAX_REVISIONS should be a number of revisions, or 0 to keep all of them")
+		}
+	}
 	stripeSecretKey := os.Getenv("STRIPE_SECRET_KEY")
 	if stripeSecretKey == "" {
 		log.Fatal("STRIPE_SECRET_KEY not set")
@@ -113,12 +116,6 @@ func loadAppConfig() *appConfig {
 		log.Fatal("STRIPE_WEBHOOK_SECRET not set")
 	}
 
-	awsCfg, err := config.LoadDefaultConfig(context.Background())
-	if err != nil {
-		log.Fatalf("cannot load aws config: %v", err)
-	}
-	client := s3.NewFromConfig(awsCfg)
-
 	pgDb, err := sql.Open("postgres", dbUrl)
 	if err != nil {
 		log.Fatalf("cannot open database: %v", err)
@@ -135,18 +132,62 @@ func loadAppConfig() *appConfig {
 		googleClientID:      googleClientID,
 		googleClientSecret:  googleClientSecret,
 		db:                  dbQueries,
-		s3Bucket:            s3Bucket,
-		s3Region:            s3Region,
-		s3Client:            client,
+		store:               loadStore(dbQueries),
 		rdb:                 rdb,
 		freeFileLimit:       freeFileLimit,
 		proFileLimit:        proFileLimit,
+		freeMaxFileSize:     freeMaxFileSize,
+		proMaxFileSize:      proMaxFileSize,
+		maxRevisions:        maxRevisions,
 		stripeSecretKey:     stripeSecretKey,
 		stripeWebhookSecret: stripeWebhookSecret,
 	}
 	return cfg
 }
 
+// maxFileSizeFromEnv reads a maximum file size in bytes from the environment variable name
+func maxFileSizeFromEnv(name string, defaultSize int64) int64 {
+	value := os.Getenv(name)
+	if value == "" {
+		return defaultSize
+	}
+	maxFileSize, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || maxFileSize <= 0 {
+		log.Fatalf("%s should be a positive number of bytes", name)
+	}
+	return maxFileSize
+}
+
+// loadStore creates the store for the contents of json files selected by STORAGE_BACKEND,
+// which is s3 by default, or filesystem, memory or postgres to run without AWS
+func loadStore(dbQueries *database.Queries) storage.Store {
+	backend := os.Getenv("STORAGE_BACKEND")
+	storageCfg, err := storage.ConfigFromEnv(backend, dbQueries)
+	if err != nil {
+		log.Fatal(err)
+	}
+	switch backend {
+	case "", "s3":
+		if storageCfg.S3Bucket == "" {
+			log.Fatal("S3_BUCKET not set")
+		}
+		if storageCfg.S3Region == "" {
+			log.Fatal("S3_REGION not set")
+		}
+	case "filesystem":
+		if storageCfg.Dir == "" {
+			log.Fatal("STORAGE_DIR not set")
+		}
+	case "memory":
+		log.Println("Using in-memory storage, json files will be lost when the server stops")
+	}
+	store, err := storage.New(context.Background(), storageCfg)
+	if err != nil {
+		log.Fatalf("cannot create storage: %v", err)
+	}
+	return store
+}
+
 func loadAuthConfig(cfg *appConfig) *auth.AuthConfig {
 	authConfig := &auth.AuthConfig{
 		Db:                 cfg.db,
@@ -155,24 +196,23 @@ func loadAuthConfig(cfg *appConfig) *auth.AuthConfig {
 		GoogleClientSecret: cfg.googleClientSecret,
 		ClientURL:          cfg.clientURL,
 		Rdb:                cfg.rdb,
-		S3Bucket:           cfg.s3Bucket,
-		S3Region:           cfg.s3Region,
-		S3Client:           cfg.s3Client,
+		Store:              cfg.store,
 	}
 	return authConfig
 }
 
 func loadJsonConfig(cfg *appConfig) *jsonfile.JsonConfig {
 	jsonConfig := &jsonfile.JsonConfig{
-		Db:            cfg.db,
-		BaseURL:       cfg.baseURL,
-		ClientURL:     cfg.clientURL,
-		S3Bucket:      cfg.s3Bucket,
-		S3Region:      cfg.s3Region,
-		S3Client:      cfg.s3Client,
-		Rdb:           cfg.rdb,
-		FreeFileLimit: cfg.freeFileLimit,
-		ProFileLimit:  cfg.proFileLimit,
+		Db:              cfg.db,
+		BaseURL:         cfg.baseURL,
+		ClientURL:       cfg.clientURL,
+		Store:           cfg.store,
+		Rdb:             cfg.rdb,
+		FreeFileLimit:   cfg.freeFileLimit,
+		ProFileLimit:    cfg.proFileLimit,
+		FreeMaxFileSize: cfg.freeMaxFileSize,
+		ProMaxFileSize:  cfg.proMaxFileSize,
+		MaxRevisions:    cfg.maxRevisions,
 	}
 	return jsonConfig
 }
@@ -229,7 +269,8 @@ func webRouter(appConfig *appConfig, authConfig *auth.AuthConfig, jsonConfig *js
 	corsWeb := cors.Handler(cors.Options{
 		AllowedOrigins:   []string{appConfig.clientURL},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "If-None-Match"},
+		ExposedHeaders:   []string{"ETag"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	})
@@ -255,26 +296,12 @@ func webRouter(appConfig *appConfig, authConfig *auth.AuthConfig, jsonConfig *js
 		r.Get("/apikeys", authConfig.HandlerGetAllApiKeys)
 		r.Delete("/apikeys/{keyHash}", authConfig.HandlerDeleteApiKey)
 
-		r.Post("/jsonfiles", jsonConfig.HandlerCreateJson)
-		r.Get("/jsonfiles", jsonConfig.HandlerGetJsonFiles)
+		jsonConfig.WebRoutes(r)
 
 		r.Post("/subscriptions/checkout", paymentConfig.HandlerCheckout)
 		r.Post("/subscriptions/success", paymentConfig.HandlerSuccess)
 		r.Get("/subscriptions", paymentConfig.HandlerGetSubscriptionStatus)
 		r.Get("/subscriptions/manage", paymentConfig.HandlerCustomerPortal)
-
-		r.Group(func(r chi.Router) {
-			r.Use(jsonConfig.JsonFileMiddleware)
-			r.Use(jsonConfig.JsonFileContentMiddleware)
-
-			r.Get("/jsonfiles/{fileId}", jsonConfig.HandlerGetJson)
-			r.Get("/jsonfiles/{fileId}/metadata", jsonConfig.HandlerGetJsonMetadata)
-			r.Patch("/jsonfiles/{fileId}", jsonConfig.HandlerRenameJsonFile)
-			r.Put("/jsonfiles/{fileId}", jsonConfig.HandlerUpdateJson)
-			r.Delete("/jsonfiles/{fileId}", jsonConfig.HandlerDeleteJsonFile)
-
-			r.Get("/jsonfiles/{fileId}/routes", jsonConfig.HandlerGetDynamicRoutes)
-		})
 	})
 
 	return r
@@ -286,7 +313,8 @@ func publicRouter(authConfig *auth.AuthConfig, jsonConfig *jsonfile.JsonConfig)
 	corsPublic := cors.Handler(cors.Options{
 		AllowedOrigins:   []string{"*"},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "If-Match", "If-None-Match"},
+		ExposedHeaders:   []string{"ETag", "Link", "Location", "X-Total-Count"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	})
@@ -296,30 +324,7 @@ func publicRouter(authConfig *auth.AuthConfig, jsonConfig *jsonfile.JsonConfig)
 		r.Use(ratelimit.TokenBucketRateLimiter(jsonConfig.Rdb, 5, 1, 60))
 		r.Use(authConfig.ApiKeyMiddleware)
 
-		r.Group(func(r chi.Router) {
-			r.Use(jsonConfig.JsonFileMiddleware)
-			r.Use(jsonConfig.JsonFileContentMiddleware)
-
-			r.Get("/{fileId}", jsonConfig.HandlerGetJson)
-
-			r.Group(func(r chi.Router) {
-				r.Use(jsonConfig.ResourceMiddleware)
-
-				r.Get("/{fileId}/{resource}", jsonConfig.HandlerGetResource)
-				r.Put("/{fileId}/{resource}", jsonConfig.HandlerUpdateResource)
-				r.Patch("/{fileId}/{resource}", jsonConfig.HandlerPartialUpdateResource)
-
-				r.Group(func(r chi.Router) {
-					r.Use(jsonConfig.ResourceArrayMiddleware)
-
-					r.Get("/{fileId}/{resource}/{id}", jsonConfig.HandlerGetResourceItem)
-					r.Post("/{fileId}/{resource}", jsonConfig.HandlerCreateResourceItem)
-					r.Put("/{fileId}/{resource}/{id}", jsonConfig.HandlerUpdateResourceItem)
-					r.Patch("/{fileId}/{resource}/{id}", jsonConfig.HandlerPartialUpdateResourceItem)
-					r.Delete("/{fileId}/{resource}/{id}", jsonConfig.HandlerDeleteResourceItem)
-				})
-			})
-		})
+		jsonConfig.PublicRoutes(r)
 	})
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
 		utils.RespondWithJSON(w, http.StatusOK, "Hello world from public api!")
@@ -4,6 +4,7 @@ go 1.23.6
 
 require (
 	cloud.google.com/go/compute/metadata v0.3.0 // indirect
+	github.com/alicebob/miniredis/v2 v2.37.0
 	github.com/aws/aws-sdk-go-v2 v1.36.3 // indirect
 	github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream v1.6.10 // indirect
 	github.com/aws/aws-sdk-go-v2/config v1.29.9 // indirect
@@ -31,5 +32,6 @@ require (
 	github.com/lib/pq v1.10.9 // indirect
 	github.com/redis/go-redis/v9 v9.7.1 // indirect
 	github.com/stripe/stripe-go/v82 v82.0.0 // indirect
+	github.com/yuin/gopher-lua v1.1.1 // indirect
 	golang.org/x/oauth2 v0.26.0 // indirect
 )
@@ -1,8 +1,8 @@
 package auth
 
 import (
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/pl3lee/restjson/internal/database"
+	"github.com/pl3lee/restjson/internal/storage"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -13,7 +13,5 @@ type AuthConfig struct {
 	GoogleClientSecret string
 	ClientURL          string
 	Rdb                *redis.Client
-	S3Bucket           string
-	S3Region           string
-	S3Client           *s3.Client
+	Store              storage.Store
 }
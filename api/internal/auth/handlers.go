@@ -3,7 +3,6 @@ package auth
 import (
 	"crypto/sha256"
 	"encoding/hex"
-	"fmt"
 	"log"
 	"net/http"
 	"strings"
@@ -12,7 +11,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/pl3lee/restjson/internal/database"
-	"github.com/pl3lee/restjson/internal/s3util"
+	"github.com/pl3lee/restjson/internal/storage"
 	"github.com/pl3lee/restjson/internal/utils"
 )
 
@@ -254,16 +253,12 @@ func (cfg *AuthConfig) HandlerDeleteAccount(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	// delete all json files from s3 that belongs to user
+	// delete all json files from storage that belongs to user
 	for _, file := range jsonFiles {
-		if err := s3util.DeleteFileFromS3(r.Context(), cfg.S3Client, cfg.Rdb, cfg.S3Bucket, userId, file.ID); err != nil {
-			utils.RespondWithError(w, http.StatusInternalServerError, "error deleting file from s3", err)
+		if err := storage.DeleteJson(r.Context(), cfg.Store, cfg.Rdb, userId, file.ID); err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "error deleting file from storage", err)
 			return
 		}
-
-		// delete from cache
-		cacheKey := fmt.Sprintf("json:%s:%s", userId.String(), file.ID.String())
-		cfg.Rdb.Del(r.Context(), cacheKey)
 	}
 
 	if err := cfg.invalidateAllSessions(r.Context(), userId); err != nil {
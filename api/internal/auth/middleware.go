@@ -14,6 +14,9 @@ type contextKey string
 
 const UserIDContextKey contextKey = "userId"
 
+// ApiKeyContextKey holds the database.ApiKey of requests authenticated with an api key
+const ApiKeyContextKey contextKey = "apiKey"
+
 func (cfg *AuthConfig) SessionMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		sessionCookie, err := r.Cookie("session_token")
@@ -64,6 +67,7 @@ func (cfg *AuthConfig) ApiKeyMiddleware(next http.Handler) http.Handler {
 		}
 
 		ctx := context.WithValue(r.Context(), UserIDContextKey, userId)
+		ctx = context.WithValue(ctx, ApiKeyContextKey, apiKeyEntry)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
@@ -7,6 +7,7 @@ package database
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/google/uuid"
 )
@@ -14,7 +15,7 @@ import (
 const createNewJson = `-- name: CreateNewJson :one
 INSERT INTO json_files (id, user_id, file_name, url)
 VALUES($1, $2, $3, $4)
-RETURNING id, created_at, updated_at, user_id, file_name, url
+RETURNING id, created_at, updated_at, user_id, file_name, url, id_strategy, id_field, resource_id_fields, foreign_key_suffix, relationships
 `
 
 type CreateNewJsonParams struct {
@@ -39,6 +40,11 @@ func (q *Queries) CreateNewJson(ctx context.Context, arg CreateNewJsonParams) (J
 		&i.UserID,
 		&i.FileName,
 		&i.Url,
+		&i.IdStrategy,
+		&i.IdField,
+		&i.ResourceIdFields,
+		&i.ForeignKeySuffix,
+		&i.Relationships,
 	)
 	return i, err
 }
@@ -54,7 +60,7 @@ func (q *Queries) DeleteJsonFile(ctx context.Context, id uuid.UUID) error {
 }
 
 const getJsonFile = `-- name: GetJsonFile :one
-SELECT id, created_at, updated_at, user_id, file_name, url
+SELECT id, created_at, updated_at, user_id, file_name, url, id_strategy, id_field, resource_id_fields, foreign_key_suffix, relationships
 FROM json_files
 WHERE id=$1
 `
@@ -69,12 +75,17 @@ func (q *Queries) GetJsonFile(ctx context.Context, id uuid.UUID) (JsonFile, erro
 		&i.UserID,
 		&i.FileName,
 		&i.Url,
+		&i.IdStrategy,
+		&i.IdField,
+		&i.ResourceIdFields,
+		&i.ForeignKeySuffix,
+		&i.Relationships,
 	)
 	return i, err
 }
 
 const getJsonFiles = `-- name: GetJsonFiles :many
-SELECT id, created_at, updated_at, user_id, file_name, url
+SELECT id, created_at, updated_at, user_id, file_name, url, id_strategy, id_field, resource_id_fields, foreign_key_suffix, relationships
 FROM json_files
 WHERE user_id=$1
 `
@@ -95,6 +106,11 @@ func (q *Queries) GetJsonFiles(ctx context.Context, userID uuid.UUID) ([]JsonFil
 			&i.UserID,
 			&i.FileName,
 			&i.Url,
+			&i.IdStrategy,
+			&i.IdField,
+			&i.ResourceIdFields,
+			&i.ForeignKeySuffix,
+			&i.Relationships,
 		); err != nil {
 			return nil, err
 		}
@@ -113,7 +129,7 @@ const renameJsonFile = `-- name: RenameJsonFile :one
 UPDATE json_files
 SET file_name=$2, updated_at=NOW()
 WHERE id=$1
-RETURNING id, created_at, updated_at, user_id, file_name, url
+RETURNING id, created_at, updated_at, user_id, file_name, url, id_strategy, id_field, resource_id_fields, foreign_key_suffix, relationships
 `
 
 type RenameJsonFileParams struct {
@@ -131,6 +147,98 @@ func (q *Queries) RenameJsonFile(ctx context.Context, arg RenameJsonFileParams)
 		&i.UserID,
 		&i.FileName,
 		&i.Url,
+		&i.IdStrategy,
+		&i.IdField,
+		&i.ResourceIdFields,
+		&i.ForeignKeySuffix,
+		&i.Relationships,
 	)
 	return i, err
 }
+
+const updateJsonFileSettings = `-- name: UpdateJsonFileSettings :one
+UPDATE json_files
+SET id_strategy=$2, id_field=$3, resource_id_fields=$4, foreign_key_suffix=$5, updated_at=NOW()
+WHERE id=$1
+RETURNING id, created_at, updated_at, user_id, file_name, url, id_strategy, id_field, resource_id_fields, foreign_key_suffix, relationships
+`
+
+type UpdateJsonFileSettingsParams struct {
+	ID               uuid.UUID
+	IdStrategy       string
+	IdField          string
+	ResourceIdFields json.RawMessage
+	ForeignKeySuffix string
+}
+
+func (q *Queries) UpdateJsonFileSettings(ctx context.Context, arg UpdateJsonFileSettingsParams) (JsonFile, error) {
+	row := q.db.QueryRowContext(ctx, updateJsonFileSettings,
+		arg.ID,
+		arg.IdStrategy,
+		arg.IdField,
+		arg.ResourceIdFields,
+		arg.ForeignKeySuffix,
+	)
+	var i JsonFile
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FileName,
+		&i.Url,
+		&i.IdStrategy,
+		&i.IdField,
+		&i.ResourceIdFields,
+		&i.ForeignKeySuffix,
+		&i.Relationships,
+	)
+	return i, err
+}
+
+const updateJsonFileRelationships = `-- name: UpdateJsonFileRelationships :one
+UPDATE json_files
+SET relationships=$2, updated_at=NOW()
+WHERE id=$1
+RETURNING id, created_at, updated_at, user_id, file_name, url, id_strategy, id_field, resource_id_fields, foreign_key_suffix, relationships
+`
+
+type UpdateJsonFileRelationshipsParams struct {
+	ID            uuid.UUID
+	Relationships json.RawMessage
+}
+
+func (q *Queries) UpdateJsonFileRelationships(ctx context.Context, arg UpdateJsonFileRelationshipsParams) (JsonFile, error) {
+	row := q.db.QueryRowContext(ctx, updateJsonFileRelationships, arg.ID, arg.Relationships)
+	var i JsonFile
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FileName,
+		&i.Url,
+		&i.IdStrategy,
+		&i.IdField,
+		&i.ResourceIdFields,
+		&i.ForeignKeySuffix,
+		&i.Relationships,
+	)
+	return i, err
+}
+
+const updateJsonFileUrl = `-- name: UpdateJsonFileUrl :exec
+UPDATE json_files
+SET url=$2
+WHERE id=$1
+`
+
+type UpdateJsonFileUrlParams struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) UpdateJsonFileUrl(ctx context.Context, arg UpdateJsonFileUrlParams) error {
+	_, err := q.db.ExecContext(ctx, updateJsonFileUrl, arg.ID, arg.Url)
+	return err
+}
@@ -5,6 +5,7 @@
 package database
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -20,13 +21,56 @@ type ApiKey struct {
 	Name       string
 }
 
-type JsonFile struct {
-	ID        uuid.UUID
+type JsonContent struct {
+	Key       string
 	CreatedAt time.Time
 	UpdatedAt time.Time
-	UserID    uuid.UUID
-	FileName  string
-	Url       string
+	Contents  json.RawMessage
+}
+
+type JsonFile struct {
+	ID               uuid.UUID
+	CreatedAt        time.Time
+	UpdatedAt        time.Time
+	UserID           uuid.UUID
+	FileName         string
+	Url              string
+	IdStrategy       string
+	IdField          string
+	ResourceIdFields json.RawMessage
+	ForeignKeySuffix string
+	Relationships    json.RawMessage
+}
+
+type JsonRevision struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	JsonFileID uuid.UUID
+	Revision   int32
+	UserID     uuid.UUID
+	Source     string
+	ApiKeyID   uuid.NullUUID
+	ApiKeyName string
+	Size       int64
+	Checksum   string
+}
+
+type JsonSchema struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	JsonFileID uuid.UUID
+	Resource   string
+	Schema     json.RawMessage
+}
+
+type JsonSnapshot struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	JsonFileID uuid.UUID
+	Name       string
+	Revision   int32
 }
 
 type User struct {
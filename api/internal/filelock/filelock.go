@@ -0,0 +1,75 @@
+package filelock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrNotAcquired is returned when the lock could not be acquired before the context was done
+var ErrNotAcquired = errors.New("lock not acquired")
+
+// releaseScript only deletes the lock if it is still held by the same owner,
+// so a lock that expired and was taken by another request is not released by mistake
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Lock is a distributed lock stored in Redis
+type Lock struct {
+	rdb   redis.Cmdable
+	key   string
+	token string
+}
+
+// Acquire blocks until the lock with the given key is acquired, or ctx is done.
+// The lock expires after ttl in case the holder never releases it.
+func Acquire(ctx context.Context, rdb redis.Cmdable, key string, ttl time.Duration) (*Lock, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return nil, fmt.Errorf("Acquire: error generating lock token: %w", err)
+	}
+	lock := &Lock{
+		rdb:   rdb,
+		key:   key,
+		token: hex.EncodeToString(random),
+	}
+
+	for {
+		acquired, err := rdb.SetNX(ctx, key, lock.token, ttl).Result()
+		if err != nil && ctx.Err() == nil {
+			return nil, fmt.Errorf("Acquire: error setting lock: %w", err)
+		}
+		if acquired {
+			return lock, nil
+		}
+
+		// wait a little before trying again, with jitter so waiting requests don't retry in lockstep
+		jitter, err := rand.Int(rand.Reader, big.NewInt(20))
+		if err != nil {
+			jitter = big.NewInt(0)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("Acquire: %w: %w", ErrNotAcquired, ctx.Err())
+		case <-time.After(time.Duration(10+jitter.Int64()) * time.Millisecond):
+		}
+	}
+}
+
+// Release unlocks the lock if it is still held
+func (l *Lock) Release(ctx context.Context) error {
+	if err := releaseScript.Run(ctx, l.rdb, []string{l.key}, l.token).Err(); err != nil {
+		return fmt.Errorf("Release: error releasing lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package filelock
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func newTestRedis(t *testing.T) (*miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return mr, rdb
+}
+
+func TestAcquireRelease(t *testing.T) {
+	mr, rdb := newTestRedis(t)
+	ctx := context.Background()
+
+	lock, err := Acquire(ctx, rdb, "lock:test", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	if !mr.Exists("lock:test") {
+		t.Fatal("lock key was not set")
+	}
+	if ttl := mr.TTL("lock:test"); ttl != time.Minute {
+		t.Errorf("got ttl %v, want %v", ttl, time.Minute)
+	}
+
+	if err := lock.Release(ctx); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if mr.Exists("lock:test") {
+		t.Fatal("lock key was not deleted on release")
+	}
+
+	lock, err = Acquire(ctx, rdb, "lock:test", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire after release: %v", err)
+	}
+	lock.Release(ctx)
+}
+
+func TestAcquireTimeout(t *testing.T) {
+	_, rdb := newTestRedis(t)
+
+	lock, err := Acquire(context.Background(), rdb, "lock:test", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	defer lock.Release(context.Background())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err = Acquire(ctx, rdb, "lock:test", time.Minute)
+	if !errors.Is(err, ErrNotAcquired) {
+		t.Fatalf("got error %v, want ErrNotAcquired", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v, want it to wrap context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed < 100*time.Millisecond {
+		t.Errorf("gave up after %v, before the context was done", elapsed)
+	}
+
+	// other keys are not affected
+	other, err := Acquire(context.Background(), rdb, "lock:other", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire of another key: %v", err)
+	}
+	other.Release(context.Background())
+}
+
+func TestAcquireWaitsForRelease(t *testing.T) {
+	_, rdb := newTestRedis(t)
+	ctx := context.Background()
+
+	lock, err := Acquire(ctx, rdb, "lock:test", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		lock.Release(ctx)
+	}()
+
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	second, err := Acquire(waitCtx, rdb, "lock:test", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire while the lock is released: %v", err)
+	}
+	second.Release(ctx)
+}
+
+func TestReleaseAfterExpiry(t *testing.T) {
+	mr, rdb := newTestRedis(t)
+	ctx := context.Background()
+
+	expired, err := Acquire(ctx, rdb, "lock:test", time.Second)
+	if err != nil {
+		t.Fatalf("Acquire: %v", err)
+	}
+	mr.FastForward(2 * time.Second)
+
+	current, err := Acquire(ctx, rdb, "lock:test", time.Minute)
+	if err != nil {
+		t.Fatalf("Acquire after expiry: %v", err)
+	}
+	// the expired holder must not release the lock taken by someone else
+	if err := expired.Release(ctx); err != nil {
+		t.Fatalf("Release of expired lock: %v", err)
+	}
+	if !mr.Exists("lock:test") {
+		t.Fatal("expired holder released the current lock")
+	}
+
+	if err := current.Release(ctx); err != nil {
+		t.Fatalf("Release: %v", err)
+	}
+	if mr.Exists("lock:test") {
+		t.Fatal("lock key was not deleted on release")
+	}
+}
+
+func TestAcquireMutualExclusion(t *testing.T) {
+	_, rdb := newTestRedis(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	const workers = 50
+	counter := 0
+	holders := 0
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	errs := make(chan error, 2*workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lock, err := Acquire(ctx, rdb, "lock:test", time.Minute)
+			if err != nil {
+				errs <- err
+				return
+			}
+			mu.Lock()
+			holders++
+			if holders > 1 {
+				errs <- errors.New("lock held by more than one worker")
+			}
+			value := counter
+			mu.Unlock()
+
+			// a read-modify-write that loses updates unless the lock is exclusive
+			time.Sleep(time.Millisecond)
+
+			mu.Lock()
+			counter = value + 1
+			holders--
+			mu.Unlock()
+			if err := lock.Release(context.Background()); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+	if counter != workers {
+		t.Errorf("got counter %d, want %d", counter, workers)
+	}
+}
@@ -0,0 +1,171 @@
+// Package graphql parses and executes GraphQL requests against a schema built at runtime.
+// It supports queries, mutations, variables, fragments, the @skip and @include directives and introspection,
+// which is enough for clients like GraphiQL. Interfaces, unions and subscriptions are not supported.
+package graphql
+
+import "fmt"
+
+// Location is a position in the source of a request, lines and columns start at 1
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is a GraphQL error as returned in the errors of a response
+type Error struct {
+	Message    string         `json:"message"`
+	Locations  []Location     `json:"locations,omitempty"`
+	Path       []any          `json:"path,omitempty"`
+	Extensions map[string]any `json:"extensions,omitempty"`
+}
+
+// ExtendedError is an error returned by a resolver with more information for clients,
+// which is added to the extensions of the error in the response
+type ExtendedError interface {
+	error
+	Extensions() map[string]any
+}
+
+func (e *Error) Error() string {
+	if len(e.Locations) > 0 {
+		return fmt.Sprintf("%s (line %d, column %d)", e.Message, e.Locations[0].Line, e.Locations[0].Column)
+	}
+	return e.Message
+}
+
+// Document is a parsed request, containing operations and the fragments they use
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*FragmentDefinition
+}
+
+type Operation struct {
+	// Type is query, mutation or subscription
+	Type                string
+	Name                string
+	VariableDefinitions []*VariableDefinition
+	Directives          []*DirectiveNode
+	SelectionSet        []Selection
+	Loc                 Location
+}
+
+type VariableDefinition struct {
+	Name         string
+	Type         *TypeNode
+	DefaultValue Value
+	Loc          Location
+}
+
+// TypeNode is a type as written in a variable definition, a list when Elem is set, otherwise a named type
+type TypeNode struct {
+	Name    string
+	Elem    *TypeNode
+	NonNull bool
+}
+
+func (t *TypeNode) String() string {
+	s := t.Name
+	if t.Elem != nil {
+		s = "[" + t.Elem.String() + "]"
+	}
+	if t.NonNull {
+		s += "!"
+	}
+	return s
+}
+
+// Selection is a *FieldNode, *FragmentSpread or *InlineFragment
+type Selection interface {
+	directives() []*DirectiveNode
+}
+
+type FieldNode struct {
+	Alias        string
+	Name         string
+	Arguments    []*Argument
+	Directives   []*DirectiveNode
+	SelectionSet []Selection
+	Loc          Location
+}
+
+// ResponseKey is the key of the field in the response, its alias if it has one
+func (f *FieldNode) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*DirectiveNode
+	Loc        Location
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*DirectiveNode
+	SelectionSet  []Selection
+	Loc           Location
+}
+
+func (f *FieldNode) directives() []*DirectiveNode      { return f.Directives }
+func (f *FragmentSpread) directives() []*DirectiveNode { return f.Directives }
+func (f *InlineFragment) directives() []*DirectiveNode { return f.Directives }
+
+type FragmentDefinition struct {
+	Name          string
+	TypeCondition string
+	Directives    []*DirectiveNode
+	SelectionSet  []Selection
+	Loc           Location
+}
+
+type DirectiveNode struct {
+	Name      string
+	Arguments []*Argument
+	Loc       Location
+}
+
+type Argument struct {
+	Name  string
+	Value Value
+	Loc   Location
+}
+
+// Value is a literal or variable in a request, one of the *...Value types or *Variable
+type Value interface {
+	isValue()
+}
+
+type Variable struct {
+	Name string
+	Loc  Location
+}
+
+type IntValue struct{ Raw string }
+type FloatValue struct{ Raw string }
+type StringValue struct{ Value string }
+type BooleanValue struct{ Value bool }
+type NullValue struct{}
+type EnumValue struct{ Name string }
+type ListValue struct{ Values []Value }
+
+type ObjectValue struct {
+	Fields []*ObjectField
+}
+
+type ObjectField struct {
+	Name  string
+	Value Value
+}
+
+func (*Variable) isValue()     {}
+func (*IntValue) isValue()     {}
+func (*FloatValue) isValue()   {}
+func (*StringValue) isValue()  {}
+func (*BooleanValue) isValue() {}
+func (*NullValue) isValue()    {}
+func (*EnumValue) isValue()    {}
+func (*ListValue) isValue()    {}
+func (*ObjectValue) isValue()  {}
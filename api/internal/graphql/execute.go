@@ -0,0 +1,425 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Request is the body of a GraphQL request
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// Response has no data when the request could not be executed at all, for example because of a syntax error
+type Response struct {
+	Data   json.RawMessage `json:"data,omitempty"`
+	Errors []*Error        `json:"errors,omitempty"`
+}
+
+func fieldDefinition(schema *Schema, parent *Object, name string) *Field {
+	switch {
+	case name == typenameField.Name:
+		return typenameField
+	case name == schemaField.Name && parent == schema.Query:
+		return schemaField
+	case name == typeField.Name && parent == schema.Query:
+		return typeField
+	}
+	return parent.Field(name)
+}
+
+// errNull is returned while completing a value whose error has already been recorded, the value becomes null,
+// or the null propagates to the parent when the value is non-nullable
+var errNull = errors.New("null value")
+
+type executor struct {
+	ctx       context.Context
+	schema    *Schema
+	fragments map[string]*FragmentDefinition
+	variables map[string]any
+	errors    []*Error
+}
+
+// Execute parses, validates and executes a request. Errors of fields are returned along with the data of the other fields.
+// The fields of mutations are executed one after the other, in the order of the request.
+func (s *Schema) Execute(ctx context.Context, request Request) *Response {
+	doc, err := Parse(request.Query)
+	if err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+	if errs := validate(s, doc); len(errs) > 0 {
+		return &Response{Errors: errs}
+	}
+	op, err := selectOperation(doc, request.OperationName)
+	if err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+
+	e := &executor{
+		ctx:       ctx,
+		schema:    s,
+		fragments: doc.Fragments,
+	}
+	e.variables, err = e.coerceVariables(op.VariableDefinitions, request.Variables)
+	if err != nil {
+		return &Response{Errors: []*Error{asError(err)}}
+	}
+
+	root := s.Query
+	if op.Type == "mutation" {
+		root = s.Mutation
+	}
+	data, err := e.executeSelectionSet(op.SelectionSet, root, nil, []any{})
+	var result any = data
+	if err != nil {
+		result = nil
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return &Response{Errors: append(e.errors, &Error{Message: fmt.Sprintf("error encoding result: %v", err)})}
+	}
+	return &Response{Data: raw, Errors: e.errors}
+}
+
+func asError(err error) *Error {
+	var graphqlErr *Error
+	if errors.As(err, &graphqlErr) {
+		return graphqlErr
+	}
+	return &Error{Message: err.Error()}
+}
+
+func selectOperation(doc *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(doc.Operations) != 1 {
+			return nil, &Error{Message: "Must provide operation name if query contains multiple operations."}
+		}
+		return doc.Operations[0], nil
+	}
+	for _, op := range doc.Operations {
+		if op.Name == name {
+			return op, nil
+		}
+	}
+	return nil, &Error{Message: fmt.Sprintf("Unknown operation named %q.", name)}
+}
+
+func (e *executor) coerceVariables(defs []*VariableDefinition, inputs map[string]any) (map[string]any, error) {
+	coerced := map[string]any{}
+	for _, def := range defs {
+		t := typeFromNode(e.schema, def.Type)
+		value, ok := inputs[def.Name]
+		if !ok {
+			if def.DefaultValue != nil {
+				defaultValue, err := valueFromAST(def.DefaultValue, t, nil)
+				if err != nil {
+					return nil, &Error{
+						Message:   fmt.Sprintf("Variable \"$%s\" has invalid default value: %v.", def.Name, err),
+						Locations: []Location{def.Loc},
+					}
+				}
+				coerced[def.Name] = defaultValue
+			} else if isNonNull(t) {
+				return nil, &Error{
+					Message:   fmt.Sprintf("Variable \"$%s\" of required type %q was not provided.", def.Name, t),
+					Locations: []Location{def.Loc},
+				}
+			}
+			continue
+		}
+		value, err := coerceValue(value, t)
+		if err != nil {
+			return nil, &Error{
+				Message:   fmt.Sprintf("Variable \"$%s\" got invalid value: %v.", def.Name, err),
+				Locations: []Location{def.Loc},
+			}
+		}
+		coerced[def.Name] = value
+	}
+	return coerced, nil
+}
+
+// orderedFields is the result of a selection set, encoded as a json object in the order of the request
+type orderedFields []orderedField
+
+type orderedField struct {
+	key   string
+	value any
+}
+
+func (fields orderedFields) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range fields {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(field.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(field.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+type fieldGroup struct {
+	key    string
+	fields []*FieldNode
+}
+
+// collectFields groups the fields of a selection set by response key, following fragments and directives
+func (e *executor) collectFields(objectType *Object, selections []Selection, groups []*fieldGroup, visited map[string]bool) []*fieldGroup {
+	for _, selection := range selections {
+		if !e.shouldInclude(selection.directives()) {
+			continue
+		}
+		switch selection := selection.(type) {
+		case *FieldNode:
+			key := selection.ResponseKey()
+			found := false
+			for _, group := range groups {
+				if group.key == key {
+					group.fields = append(group.fields, selection)
+					found = true
+					break
+				}
+			}
+			if !found {
+				groups = append(groups, &fieldGroup{key: key, fields: []*FieldNode{selection}})
+			}
+		case *FragmentSpread:
+			fragment, ok := e.fragments[selection.Name]
+			if !ok || visited[selection.Name] || fragment.TypeCondition != objectType.Name {
+				continue
+			}
+			visited[selection.Name] = true
+			groups = e.collectFields(objectType, fragment.SelectionSet, groups, visited)
+		case *InlineFragment:
+			if selection.TypeCondition != "" && selection.TypeCondition != objectType.Name {
+				continue
+			}
+			groups = e.collectFields(objectType, selection.SelectionSet, groups, visited)
+		}
+	}
+	return groups
+}
+
+// shouldInclude applies @skip and @include
+func (e *executor) shouldInclude(directives []*DirectiveNode) bool {
+	for _, directive := range directives {
+		if directive.Name != SkipDirective.Name && directive.Name != IncludeDirective.Name {
+			continue
+		}
+		for _, argument := range directive.Arguments {
+			if argument.Name != "if" {
+				continue
+			}
+			value, err := valueFromAST(argument.Value, NewNonNull(Boolean), e.variables)
+			if err != nil {
+				continue
+			}
+			if value == (directive.Name == SkipDirective.Name) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (e *executor) executeSelectionSet(selections []Selection, objectType *Object, source any, path []any) (orderedFields, error) {
+	groups := e.collectFields(objectType, selections, nil, map[string]bool{})
+	result := orderedFields{}
+	for _, group := range groups {
+		value, err := e.executeField(objectType, source, group, appendPath(path, group.key))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, orderedField{key: group.key, value: value})
+	}
+	return result, nil
+}
+
+func (e *executor) executeField(objectType *Object, source any, group *fieldGroup, path []any) (any, error) {
+	field := group.fields[0]
+	def := fieldDefinition(e.schema, objectType, field.Name)
+	if def == nil {
+		return nil, nil
+	}
+
+	args, err := e.coerceArguments(def.Args, field.Arguments)
+	if err != nil {
+		e.recordError(err, field, path)
+		return nullable(def.Type, nil, errNull)
+	}
+
+	var value any
+	switch def {
+	case typenameField:
+		value = objectType.Name
+	case schemaField:
+		value = e.schema
+	case typeField:
+		if t := e.schema.Type(args["name"].(string)); t != nil {
+			value = t
+		}
+	default:
+		resolve := def.Resolve
+		if resolve == nil {
+			resolve = defaultResolve(def.Name)
+		}
+		value, err = resolve(ResolveParams{Context: e.ctx, Source: source, Args: args})
+		if err != nil {
+			e.recordError(err, field, path)
+			return nullable(def.Type, nil, errNull)
+		}
+	}
+
+	value, err = e.completeValue(def.Type, group.fields, value, path)
+	return nullable(def.Type, value, err)
+}
+
+// nullable turns a null propagated from inside a value into a null value, unless the value itself is non-nullable
+func nullable(t Type, value any, err error) (any, error) {
+	if err != nil && !isNonNull(t) {
+		return nil, nil
+	}
+	return value, err
+}
+
+func defaultResolve(name string) ResolveFunc {
+	return func(p ResolveParams) (any, error) {
+		if source, ok := p.Source.(map[string]any); ok {
+			return source[name], nil
+		}
+		return nil, nil
+	}
+}
+
+func (e *executor) recordError(err error, field *FieldNode, path []any) {
+	graphqlErr := &Error{
+		Message:   err.Error(),
+		Locations: []Location{field.Loc},
+		Path:      append([]any{}, path...),
+	}
+	var extended ExtendedError
+	if errors.As(err, &extended) {
+		graphqlErr.Extensions = extended.Extensions()
+	}
+	e.errors = append(e.errors, graphqlErr)
+}
+
+func (e *executor) coerceArguments(defs []*InputValue, arguments []*Argument) (map[string]any, error) {
+	coerced := map[string]any{}
+	for _, def := range defs {
+		var argument *Argument
+		for _, candidate := range arguments {
+			if candidate.Name == def.Name {
+				argument = candidate
+			}
+		}
+		present := argument != nil
+		if variable, ok := argument.valueIfPresent().(*Variable); ok {
+			_, present = e.variables[variable.Name]
+		}
+		if !present {
+			if def.DefaultValue != nil {
+				coerced[def.Name] = def.DefaultValue
+			} else if isNonNull(def.Type) {
+				return nil, fmt.Errorf("Argument %q of required type %q was not provided.", def.Name, def.Type)
+			}
+			continue
+		}
+		value, err := valueFromAST(argument.Value, def.Type, e.variables)
+		if err != nil {
+			return nil, fmt.Errorf("Argument %q has invalid value %s: %v.", def.Name, printLiteral(argument.Value), err)
+		}
+		coerced[def.Name] = value
+	}
+	return coerced, nil
+}
+
+func (a *Argument) valueIfPresent() Value {
+	if a == nil {
+		return nil
+	}
+	return a.Value
+}
+
+// completeValue converts a resolved value into a value of the response according to its type.
+// It returns errNull when the value must be null because of an error that has been recorded.
+func (e *executor) completeValue(t Type, fields []*FieldNode, value any, path []any) (any, error) {
+	if nonNull, ok := t.(*NonNull); ok {
+		completed, err := e.completeValue(nonNull.OfType, fields, value, path)
+		if err != nil {
+			return nil, err
+		}
+		if completed == nil {
+			e.recordError(fmt.Errorf("Cannot return null for non-nullable field %s.", fields[0].Name), fields[0], path)
+			return nil, errNull
+		}
+		return completed, nil
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items, ok := value.([]any)
+		if !ok {
+			e.recordError(fmt.Errorf("Expected a list for field %s.", fields[0].Name), fields[0], path)
+			return nil, errNull
+		}
+		result := make([]any, 0, len(items))
+		for i, item := range items {
+			completed, err := e.completeValue(t.OfType, fields, item, appendPath(path, i))
+			completed, err = nullable(t.OfType, completed, err)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, completed)
+		}
+		return result, nil
+	case *Scalar:
+		serialized, err := t.Serialize(value)
+		if err != nil {
+			e.recordError(err, fields[0], path)
+			return nil, errNull
+		}
+		return serialized, nil
+	case *Enum:
+		name, ok := value.(string)
+		if !ok || !t.hasValue(name) {
+			e.recordError(fmt.Errorf("Enum %q cannot represent value: %s", t.Name, inspect(value)), fields[0], path)
+			return nil, errNull
+		}
+		return name, nil
+	case *Object:
+		selections := []Selection{}
+		for _, field := range fields {
+			selections = append(selections, field.SelectionSet...)
+		}
+		return e.executeSelectionSet(selections, t, value, path)
+	}
+	return nil, fmt.Errorf("completeValue: unexpected type %s", t)
+}
+
+// appendPath copies path, so that sibling fields do not share the backing array
+func appendPath(path []any, key any) []any {
+	result := make([]any, len(path), len(path)+1)
+	copy(result, path)
+	return append(result, key)
+}
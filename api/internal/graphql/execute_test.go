@@ -0,0 +1,342 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+)
+
+// newLibrarySchema serves books and their authors, addBook appends to books so that later fields see it
+func newLibrarySchema(t *testing.T) *Schema {
+	t.Helper()
+	authors := map[string]any{
+		"1": map[string]any{"id": float64(1), "name": "ursula"},
+	}
+	books := []any{
+		map[string]any{"id": float64(1), "title": "earthsea", "genre": "FANTASY", "authorId": "1", "tags": []any{"wizards"}},
+		map[string]any{"id": float64(2), "title": "dispossessed", "genre": "SCIENCE_FICTION", "authorId": "1", "tags": []any{}},
+	}
+
+	genre := &Enum{Name: "Genre", Values: []*EnumValueDefinition{{Name: "FANTASY"}, {Name: "SCIENCE_FICTION"}}}
+	author := &Object{Name: "Author", Fields: []*Field{
+		{Name: "id", Type: NewNonNull(ID)},
+		{Name: "name", Type: String},
+	}}
+	book := &Object{Name: "Book", Fields: []*Field{
+		{Name: "id", Type: NewNonNull(ID)},
+		{Name: "title", Type: String},
+		{Name: "genre", Type: genre},
+		{Name: "tags", Type: NewList(String)},
+		{Name: "author", Type: author, Resolve: func(p ResolveParams) (any, error) {
+			return authors[p.Source.(map[string]any)["authorId"].(string)], nil
+		}},
+		{Name: "rating", Type: NewNonNull(Int), Resolve: func(p ResolveParams) (any, error) {
+			return nil, errors.New("ratings are unavailable")
+		}},
+	}}
+	bookInput := &InputObject{Name: "BookInput", Fields: []*InputValue{
+		{Name: "title", Type: NewNonNull(String)},
+		{Name: "genre", Type: genre, DefaultValue: "FANTASY"},
+	}}
+
+	query := &Object{Name: "Query", Fields: []*Field{
+		{
+			Name: "books",
+			Type: NewNonNull(NewList(NewNonNull(book))),
+			Args: []*InputValue{{Name: "genre", Type: genre}, {Name: "limit", Type: Int}},
+			Resolve: func(p ResolveParams) (any, error) {
+				result := []any{}
+				for _, item := range books {
+					if genre, ok := p.Args["genre"]; ok && genre != nil && item.(map[string]any)["genre"] != genre {
+						continue
+					}
+					result = append(result, item)
+				}
+				if limit, ok := p.Args["limit"].(int); ok && limit < len(result) {
+					result = result[:limit]
+				}
+				return result, nil
+			},
+		},
+		{
+			Name: "book",
+			Type: book,
+			Args: []*InputValue{{Name: "id", Type: NewNonNull(ID)}},
+			Resolve: func(p ResolveParams) (any, error) {
+				for _, item := range books {
+					if id, _ := idString(item.(map[string]any)["id"]); id == p.Args["id"] {
+						return item, nil
+					}
+				}
+				return nil, nil
+			},
+		},
+		{
+			Name: "greet",
+			Type: NewNonNull(String),
+			Args: []*InputValue{{Name: "name", Type: String, DefaultValue: "world"}},
+			Resolve: func(p ResolveParams) (any, error) {
+				if p.Args["name"] == nil {
+					return "hello nobody", nil
+				}
+				return "hello " + p.Args["name"].(string), nil
+			},
+		},
+		{
+			Name: "echo",
+			Type: JSON,
+			Args: []*InputValue{{Name: "value", Type: JSON}},
+			Resolve: func(p ResolveParams) (any, error) {
+				return p.Args["value"], nil
+			},
+		},
+	}}
+	mutation := &Object{Name: "Mutation", Fields: []*Field{
+		{
+			Name: "addBook",
+			Type: book,
+			Args: []*InputValue{{Name: "input", Type: NewNonNull(bookInput)}},
+			Resolve: func(p ResolveParams) (any, error) {
+				input := p.Args["input"].(map[string]any)
+				if input["title"] == "" {
+					return nil, errors.New("title is empty")
+				}
+				item := map[string]any{"id": float64(len(books) + 1), "title": input["title"], "genre": input["genre"], "authorId": "1"}
+				books = append(books, item)
+				return item, nil
+			},
+		},
+	}}
+
+	schema, err := NewSchema(query, mutation)
+	if err != nil {
+		t.Fatalf("NewSchema: %v", err)
+	}
+	return schema
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		name          string
+		query         string
+		operationName string
+		variables     map[string]any
+		// data is compared as compacted json, so that the order of fields is checked too
+		data   string
+		errors []string
+	}{
+		{
+			name:  "fields in the order of the request",
+			query: `{ books { title id author { name } } }`,
+			data:  `{"books":[{"title":"earthsea","id":"1","author":{"name":"ursula"}},{"title":"dispossessed","id":"2","author":{"name":"ursula"}}]}`,
+		},
+		{
+			name:  "aliases and arguments",
+			query: `{ first: book(id: 1) { title } missing: book(id: "9") { title } }`,
+			data:  `{"first":{"title":"earthsea"},"missing":null}`,
+		},
+		{
+			name:  "enum arguments and values",
+			query: `{ books(genre: SCIENCE_FICTION) { title genre } }`,
+			data:  `{"books":[{"title":"dispossessed","genre":"SCIENCE_FICTION"}]}`,
+		},
+		{
+			name:  "default argument",
+			query: `{ greet greeted: greet(name: "ada") nobody: greet(name: null) }`,
+			data:  `{"greet":"hello world","greeted":"hello ada","nobody":"hello nobody"}`,
+		},
+		{
+			name:      "variables",
+			query:     `query ($genre: Genre, $limit: Int = 1) { books(genre: $genre, limit: $limit) { title } }`,
+			variables: map[string]any{"genre": "FANTASY"},
+			data:      `{"books":[{"title":"earthsea"}]}`,
+		},
+		{
+			name:      "variables inside lists and objects",
+			query:     `query ($name: String) { echo(value: {names: [$name, "b"], n: 1.5}) }`,
+			variables: map[string]any{"name": "a"},
+			data:      `{"echo":{"n":1.5,"names":["a","b"]}}`,
+		},
+		{
+			name:  "fragments",
+			query: `{ book(id: 1) { ...titles ... on Book { genre } ... { id } } } fragment titles on Book { title tags }`,
+			data:  `{"book":{"title":"earthsea","tags":["wizards"],"genre":"FANTASY","id":"1"}}`,
+		},
+		{
+			name:  "fields merged from fragments",
+			query: `{ book(id: 1) { author { id } ...author } } fragment author on Book { author { name } }`,
+			data:  `{"book":{"author":{"id":"1","name":"ursula"}}}`,
+		},
+		{
+			name:      "skip and include",
+			query:     `query ($yes: Boolean!) { book(id: 1) { title @skip(if: $yes) genre @include(if: $yes) id @include(if: false) } }`,
+			variables: map[string]any{"yes": true},
+			data:      `{"book":{"genre":"FANTASY"}}`,
+		},
+		{
+			name:          "operation name",
+			query:         `query a { greet } query b { greet(name: "b") }`,
+			operationName: "b",
+			data:          `{"greet":"hello b"}`,
+		},
+		{
+			name:  "typename",
+			query: `{ __typename book(id: 1) { __typename } }`,
+			data:  `{"__typename":"Query","book":{"__typename":"Book"}}`,
+		},
+		{
+			name:   "resolver errors propagate to the nearest nullable field",
+			query:  `{ book(id: 1) { title rating } greet }`,
+			data:   `{"book":null,"greet":"hello world"}`,
+			errors: []string{"ratings are unavailable"},
+		},
+		{
+			name:   "errors inside non-null lists null the whole list",
+			query:  `{ books { rating } }`,
+			data:   `null`,
+			errors: []string{"ratings are unavailable"},
+		},
+		{
+			name:   "invalid argument",
+			query:  `{ books(genre: HORROR) { title } greet }`,
+			data:   `null`,
+			errors: []string{`Argument "genre" has invalid value HORROR: value HORROR does not exist in "Genre" enum.`},
+		},
+		{
+			name:      "missing variable",
+			query:     `query ($id: ID!) { book(id: $id) { title } }`,
+			variables: map[string]any{},
+			errors:    []string{`Variable "$id" of required type "ID!" was not provided.`},
+		},
+		{
+			name:      "invalid variable",
+			query:     `query ($limit: Int) { books(limit: $limit) { title } }`,
+			variables: map[string]any{"limit": "one"},
+			errors:    []string{`Variable "$limit" got invalid value: Int cannot represent non-integer value: "one".`},
+		},
+		{
+			name:   "unknown field",
+			query:  `{ books { isbn } }`,
+			errors: []string{`Cannot query field "isbn" on type "Book".`},
+		},
+		{
+			name:   "fragment on another type",
+			query:  `{ book(id: 1) { ...name } } fragment name on Author { name }`,
+			errors: []string{`Fragment cannot be spread here as objects of type "Book" can never be of type "Author".`},
+		},
+		{
+			name:   "several anonymous operations",
+			query:  `{ greet } { greet }`,
+			errors: []string{"This anonymous operation must be the only defined operation.", "This anonymous operation must be the only defined operation."},
+		},
+		{
+			name:   "syntax error",
+			query:  `{ greet`,
+			errors: []string{`Syntax Error: Expected Name, found <EOF>.`},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema := newLibrarySchema(t)
+			response := schema.Execute(context.Background(), Request{Query: tt.query, OperationName: tt.operationName, Variables: tt.variables})
+
+			messages := []string{}
+			for _, err := range response.Errors {
+				messages = append(messages, err.Message)
+			}
+			if want := append([]string{}, tt.errors...); !slices.Equal(messages, want) {
+				t.Errorf("got errors %q, want %q", messages, want)
+			}
+			if tt.data == "" {
+				if response.Data != nil {
+					t.Errorf("got data %s, want none", response.Data)
+				}
+				return
+			}
+			if string(response.Data) != tt.data {
+				t.Errorf("got data %s, want %s", response.Data, tt.data)
+			}
+		})
+	}
+}
+
+func TestExecuteErrorLocations(t *testing.T) {
+	schema := newLibrarySchema(t)
+	response := schema.Execute(context.Background(), Request{Query: "{\n  book(id: 1) {\n    rating\n  }\n}"})
+	if len(response.Errors) != 1 {
+		t.Fatalf("got errors %v, want one", response.Errors)
+	}
+	data, err := json.Marshal(response.Errors[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"message":"ratings are unavailable","locations":[{"line":3,"column":5}],"path":["book","rating"]}`
+	if string(data) != want {
+		t.Errorf("got error %s, want %s", data, want)
+	}
+}
+
+func TestExecuteMutations(t *testing.T) {
+	schema := newLibrarySchema(t)
+	response := schema.Execute(context.Background(), Request{
+		Query: `mutation ($title: String!) {
+			first: addBook(input: {title: $title}) { id title genre }
+			failed: addBook(input: {title: ""}) { id }
+			second: addBook(input: {title: "tehanu", genre: SCIENCE_FICTION}) { id genre }
+		}`,
+		Variables: map[string]any{"title": "tombs"},
+	})
+	want := `{"first":{"id":"3","title":"tombs","genre":"FANTASY"},"failed":null,"second":{"id":"4","genre":"SCIENCE_FICTION"}}`
+	if string(response.Data) != want {
+		t.Errorf("got data %s, want %s", response.Data, want)
+	}
+	if len(response.Errors) != 1 || response.Errors[0].Message != "title is empty" {
+		t.Errorf("got errors %v, want the error of the failed mutation", response.Errors)
+	}
+
+	// mutations are executed in order, so the query sees both books
+	response = schema.Execute(context.Background(), Request{Query: `{ books { title } }`})
+	if !bytes.Contains(response.Data, []byte(`{"title":"tombs"},{"title":"tehanu"}`)) {
+		t.Errorf("got data %s, want the added books", response.Data)
+	}
+
+	response = schema.Execute(context.Background(), Request{Query: `mutation { addBook(input: {}) { id } }`})
+	if len(response.Errors) != 1 || response.Data == nil {
+		t.Errorf("got response %+v, want an error for the missing title", response)
+	}
+}
+
+func TestIntrospection(t *testing.T) {
+	schema := newLibrarySchema(t)
+	response := schema.Execute(context.Background(), Request{Query: `{
+		__schema { queryType { name } mutationType { name } subscriptionType { name } }
+		book: __type(name: "Book") {
+			kind
+			fields { name type { kind name ofType { kind name } } }
+		}
+		genre: __type(name: "Genre") { kind enumValues { name } }
+		input: __type(name: "BookInput") { kind inputFields { name defaultValue } }
+		missing: __type(name: "Missing") { name }
+	}`})
+	if len(response.Errors) > 0 {
+		t.Fatalf("got errors %v", response.Errors)
+	}
+	want := `{` +
+		`"__schema":{"queryType":{"name":"Query"},"mutationType":{"name":"Mutation"},"subscriptionType":null},` +
+		`"book":{"kind":"OBJECT","fields":[` +
+		`{"name":"id","type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"SCALAR","name":"ID"}}},` +
+		`{"name":"title","type":{"kind":"SCALAR","name":"String","ofType":null}},` +
+		`{"name":"genre","type":{"kind":"ENUM","name":"Genre","ofType":null}},` +
+		`{"name":"tags","type":{"kind":"LIST","name":null,"ofType":{"kind":"SCALAR","name":"String"}}},` +
+		`{"name":"author","type":{"kind":"OBJECT","name":"Author","ofType":null}},` +
+		`{"name":"rating","type":{"kind":"NON_NULL","name":null,"ofType":{"kind":"SCALAR","name":"Int"}}}]},` +
+		`"genre":{"kind":"ENUM","enumValues":[{"name":"FANTASY"},{"name":"SCIENCE_FICTION"}]},` +
+		`"input":{"kind":"INPUT_OBJECT","inputFields":[{"name":"title","defaultValue":null},{"name":"genre","defaultValue":"FANTASY"}]},` +
+		`"missing":null}`
+	if string(response.Data) != want {
+		t.Errorf("got data %s\nwant %s", response.Data, want)
+	}
+}
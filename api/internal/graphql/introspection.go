@@ -0,0 +1,414 @@
+package graphql
+
+import "strings"
+
+// the introspection types describe the schema itself, their resolvers read *Schema, Type, *Field,
+// *InputValue, *EnumValueDefinition and *Directive sources
+var (
+	schemaType        *Object
+	typeType          *Object
+	fieldType         *Object
+	inputValueType    *Object
+	enumValueType     *Object
+	directiveType     *Object
+	typeKindEnum      *Enum
+	directiveLocation *Enum
+
+	// the meta fields are available on every type (__typename) or on the query type (__schema and __type)
+	typenameField *Field
+	schemaField   *Field
+	typeField     *Field
+)
+
+func init() {
+	typeKindEnum = &Enum{
+		Name:        "__TypeKind",
+		Description: "An enum describing what kind of type a given `__Type` is.",
+		Values:      enumValues("SCALAR", "OBJECT", "INTERFACE", "UNION", "ENUM", "INPUT_OBJECT", "LIST", "NON_NULL"),
+	}
+	directiveLocation = &Enum{
+		Name:        "__DirectiveLocation",
+		Description: "A Directive can be adjacent to many parts of the GraphQL language, a __DirectiveLocation describes one such possible adjacencies.",
+		Values: enumValues(
+			"QUERY", "MUTATION", "SUBSCRIPTION", "FIELD", "FRAGMENT_DEFINITION", "FRAGMENT_SPREAD", "INLINE_FRAGMENT",
+			"VARIABLE_DEFINITION", "SCHEMA", "SCALAR", "OBJECT", "FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INTERFACE",
+			"UNION", "ENUM", "ENUM_VALUE", "INPUT_OBJECT", "INPUT_FIELD_DEFINITION",
+		),
+	}
+
+	schemaType = &Object{
+		Name:        "__Schema",
+		Description: "A GraphQL Schema defines the capabilities of a GraphQL server. It exposes all available types and directives on the server, as well as the entry points for query, mutation, and subscription operations.",
+	}
+	typeType = &Object{
+		Name:        "__Type",
+		Description: "The fundamental unit of any GraphQL Schema is the type. There are many kinds of types in GraphQL as represented by the `__TypeKind` enum.",
+	}
+	fieldType = &Object{
+		Name:        "__Field",
+		Description: "Object and Interface types are described by a list of Fields, each of which has a name, potentially a list of arguments, and a return type.",
+	}
+	inputValueType = &Object{
+		Name:        "__InputValue",
+		Description: "Arguments provided to Fields or Directives and the input fields of an InputObject are represented as Input Values which describe their type and optionally a default value.",
+	}
+	enumValueType = &Object{
+		Name:        "__EnumValue",
+		Description: "One possible value for a given Enum. Enum values are unique values, not a placeholder for a string or numeric value.",
+	}
+	directiveType = &Object{
+		Name:        "__Directive",
+		Description: "A Directive provides a way to describe alternate runtime execution and type validation behavior in a GraphQL document.",
+	}
+
+	typenameField = &Field{
+		Name:        "__typename",
+		Description: "The name of the current Object type at runtime.",
+		Type:        NewNonNull(String),
+	}
+	schemaField = &Field{
+		Name:        "__schema",
+		Description: "Access the current type schema of this server.",
+		Type:        NewNonNull(schemaType),
+	}
+	typeField = &Field{
+		Name:        "__type",
+		Description: "Request the type information of a single type.",
+		Args:        []*InputValue{{Name: "name", Type: NewNonNull(String)}},
+		Type:        typeType,
+	}
+
+	includeDeprecated := []*InputValue{{Name: "includeDeprecated", Type: Boolean, DefaultValue: false}}
+	notDeprecated := []*Field{
+		{Name: "isDeprecated", Type: NewNonNull(Boolean), Resolve: constant(false)},
+		{Name: "deprecationReason", Type: String, Resolve: constant(nil)},
+	}
+
+	schemaType.Fields = []*Field{
+		{Name: "description", Type: String, Resolve: constant(nil)},
+		{
+			Name:        "types",
+			Description: "A list of all types supported by this server.",
+			Type:        NewNonNull(NewList(NewNonNull(typeType))),
+			Resolve: func(p ResolveParams) (any, error) {
+				types := []any{}
+				for _, t := range p.Source.(*Schema).Types() {
+					types = append(types, t)
+				}
+				return types, nil
+			},
+		},
+		{
+			Name:        "queryType",
+			Description: "The type that query operations will be rooted at.",
+			Type:        NewNonNull(typeType),
+			Resolve: func(p ResolveParams) (any, error) {
+				return p.Source.(*Schema).Query, nil
+			},
+		},
+		{
+			Name:        "mutationType",
+			Description: "If this server supports mutation, the type that mutation operations will be rooted at.",
+			Type:        typeType,
+			Resolve: func(p ResolveParams) (any, error) {
+				if mutation := p.Source.(*Schema).Mutation; mutation != nil {
+					return mutation, nil
+				}
+				return nil, nil
+			},
+		},
+		{
+			Name:        "subscriptionType",
+			Description: "If this server support subscription, the type that subscription operations will be rooted at.",
+			Type:        typeType,
+			Resolve:     constant(nil),
+		},
+		{
+			Name:        "directives",
+			Description: "A list of all directives supported by this server.",
+			Type:        NewNonNull(NewList(NewNonNull(directiveType))),
+			Resolve: func(p ResolveParams) (any, error) {
+				directives := []any{}
+				for _, directive := range p.Source.(*Schema).Directives {
+					directives = append(directives, directive)
+				}
+				return directives, nil
+			},
+		},
+	}
+
+	typeType.Fields = []*Field{
+		{
+			Name: "kind",
+			Type: NewNonNull(typeKindEnum),
+			Resolve: func(p ResolveParams) (any, error) {
+				switch p.Source.(type) {
+				case *Scalar:
+					return "SCALAR", nil
+				case *Object:
+					return "OBJECT", nil
+				case *InputObject:
+					return "INPUT_OBJECT", nil
+				case *Enum:
+					return "ENUM", nil
+				case *List:
+					return "LIST", nil
+				}
+				return "NON_NULL", nil
+			},
+		},
+		{
+			Name: "name",
+			Type: String,
+			Resolve: func(p ResolveParams) (any, error) {
+				if named, ok := p.Source.(NamedType); ok {
+					return named.TypeName(), nil
+				}
+				return nil, nil
+			},
+		},
+		{
+			Name: "description",
+			Type: String,
+			Resolve: func(p ResolveParams) (any, error) {
+				if named, ok := p.Source.(NamedType); ok {
+					return description(named.TypeDescription()), nil
+				}
+				return nil, nil
+			},
+		},
+		{Name: "specifiedByURL", Type: String, Resolve: constant(nil)},
+		{
+			Name: "fields",
+			Args: includeDeprecated,
+			Type: NewList(NewNonNull(fieldType)),
+			Resolve: func(p ResolveParams) (any, error) {
+				object, ok := p.Source.(*Object)
+				if !ok {
+					return nil, nil
+				}
+				fields := []any{}
+				for _, field := range object.Fields {
+					if !strings.HasPrefix(field.Name, "__") {
+						fields = append(fields, field)
+					}
+				}
+				return fields, nil
+			},
+		},
+		{
+			Name: "interfaces",
+			Type: NewList(NewNonNull(typeType)),
+			Resolve: func(p ResolveParams) (any, error) {
+				if _, ok := p.Source.(*Object); ok {
+					return []any{}, nil
+				}
+				return nil, nil
+			},
+		},
+		{Name: "possibleTypes", Type: NewList(NewNonNull(typeType)), Resolve: constant(nil)},
+		{
+			Name: "enumValues",
+			Args: includeDeprecated,
+			Type: NewList(NewNonNull(enumValueType)),
+			Resolve: func(p ResolveParams) (any, error) {
+				enum, ok := p.Source.(*Enum)
+				if !ok {
+					return nil, nil
+				}
+				values := []any{}
+				for _, value := range enum.Values {
+					values = append(values, value)
+				}
+				return values, nil
+			},
+		},
+		{
+			Name: "inputFields",
+			Args: includeDeprecated,
+			Type: NewList(NewNonNull(inputValueType)),
+			Resolve: func(p ResolveParams) (any, error) {
+				inputObject, ok := p.Source.(*InputObject)
+				if !ok {
+					return nil, nil
+				}
+				return inputValues(inputObject.Fields), nil
+			},
+		},
+		{
+			Name: "ofType",
+			Type: typeType,
+			Resolve: func(p ResolveParams) (any, error) {
+				switch t := p.Source.(type) {
+				case *List:
+					return t.OfType, nil
+				case *NonNull:
+					return t.OfType, nil
+				}
+				return nil, nil
+			},
+		},
+		{
+			Name: "isOneOf",
+			Type: Boolean,
+			Resolve: func(p ResolveParams) (any, error) {
+				if _, ok := p.Source.(*InputObject); ok {
+					return false, nil
+				}
+				return nil, nil
+			},
+		},
+	}
+
+	fieldType.Fields = append([]*Field{
+		{
+			Name: "name",
+			Type: NewNonNull(String),
+			Resolve: func(p ResolveParams) (any, error) {
+				return p.Source.(*Field).Name, nil
+			},
+		},
+		{
+			Name: "description",
+			Type: String,
+			Resolve: func(p ResolveParams) (any, error) {
+				return description(p.Source.(*Field).Description), nil
+			},
+		},
+		{
+			Name: "args",
+			Args: includeDeprecated,
+			Type: NewNonNull(NewList(NewNonNull(inputValueType))),
+			Resolve: func(p ResolveParams) (any, error) {
+				return inputValues(p.Source.(*Field).Args), nil
+			},
+		},
+		{
+			Name: "type",
+			Type: NewNonNull(typeType),
+			Resolve: func(p ResolveParams) (any, error) {
+				return p.Source.(*Field).Type, nil
+			},
+		},
+	}, notDeprecated...)
+
+	inputValueType.Fields = append([]*Field{
+		{
+			Name: "name",
+			Type: NewNonNull(String),
+			Resolve: func(p ResolveParams) (any, error) {
+				return p.Source.(*InputValue).Name, nil
+			},
+		},
+		{
+			Name: "description",
+			Type: String,
+			Resolve: func(p ResolveParams) (any, error) {
+				return description(p.Source.(*InputValue).Description), nil
+			},
+		},
+		{
+			Name: "type",
+			Type: NewNonNull(typeType),
+			Resolve: func(p ResolveParams) (any, error) {
+				return p.Source.(*InputValue).Type, nil
+			},
+		},
+		{
+			Name:        "defaultValue",
+			Description: "A GraphQL-formatted string representing the default value for this input value.",
+			Type:        String,
+			Resolve: func(p ResolveParams) (any, error) {
+				inputValue := p.Source.(*InputValue)
+				if inputValue.DefaultValue == nil {
+					return nil, nil
+				}
+				return printValue(inputValue.DefaultValue, inputValue.Type), nil
+			},
+		},
+	}, notDeprecated...)
+
+	enumValueType.Fields = append([]*Field{
+		{
+			Name: "name",
+			Type: NewNonNull(String),
+			Resolve: func(p ResolveParams) (any, error) {
+				return p.Source.(*EnumValueDefinition).Name, nil
+			},
+		},
+		{
+			Name: "description",
+			Type: String,
+			Resolve: func(p ResolveParams) (any, error) {
+				return description(p.Source.(*EnumValueDefinition).Description), nil
+			},
+		},
+	}, notDeprecated...)
+
+	directiveType.Fields = []*Field{
+		{
+			Name: "name",
+			Type: NewNonNull(String),
+			Resolve: func(p ResolveParams) (any, error) {
+				return p.Source.(*Directive).Name, nil
+			},
+		},
+		{
+			Name: "description",
+			Type: String,
+			Resolve: func(p ResolveParams) (any, error) {
+				return description(p.Source.(*Directive).Description), nil
+			},
+		},
+		{Name: "isRepeatable", Type: NewNonNull(Boolean), Resolve: constant(false)},
+		{
+			Name: "locations",
+			Type: NewNonNull(NewList(NewNonNull(directiveLocation))),
+			Resolve: func(p ResolveParams) (any, error) {
+				locations := []any{}
+				for _, location := range p.Source.(*Directive).Locations {
+					locations = append(locations, location)
+				}
+				return locations, nil
+			},
+		},
+		{
+			Name: "args",
+			Args: includeDeprecated,
+			Type: NewNonNull(NewList(NewNonNull(inputValueType))),
+			Resolve: func(p ResolveParams) (any, error) {
+				return inputValues(p.Source.(*Directive).Args), nil
+			},
+		},
+	}
+}
+
+func enumValues(names ...string) []*EnumValueDefinition {
+	values := []*EnumValueDefinition{}
+	for _, name := range names {
+		values = append(values, &EnumValueDefinition{Name: name})
+	}
+	return values
+}
+
+func inputValues(values []*InputValue) []any {
+	result := []any{}
+	for _, value := range values {
+		result = append(result, value)
+	}
+	return result
+}
+
+func constant(value any) ResolveFunc {
+	return func(ResolveParams) (any, error) {
+		return value, nil
+	}
+}
+
+// description returns nil for an empty description, so that it is null rather than an empty string
+func description(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
@@ -0,0 +1,330 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	loc   Location
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "<EOF>"
+	case tokenString:
+		return strconv.Quote(t.value)
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+type lexer struct {
+	source    string
+	pos       int
+	line      int
+	lineStart int
+}
+
+func newLexer(source string) *lexer {
+	return &lexer{source: source, line: 1}
+}
+
+func (l *lexer) location() Location {
+	return Location{Line: l.line, Column: utf8.RuneCountInString(l.source[l.lineStart:l.pos]) + 1}
+}
+
+func (l *lexer) syntaxError(loc Location, format string, args ...any) *Error {
+	return &Error{
+		Message:   "Syntax Error: " + fmt.Sprintf(format, args...),
+		Locations: []Location{loc},
+	}
+}
+
+// newline records a line break ending at pos
+func (l *lexer) newline() {
+	l.line++
+	l.lineStart = l.pos
+}
+
+// skipIgnored skips whitespace, commas, comments and the byte order mark
+func (l *lexer) skipIgnored() {
+	for l.pos < len(l.source) {
+		switch c := l.source[l.pos]; c {
+		case ' ', '\t', ',':
+			l.pos++
+		case '\n':
+			l.pos++
+			l.newline()
+		case '\r':
+			l.pos++
+			if l.pos < len(l.source) && l.source[l.pos] == '\n' {
+				l.pos++
+			}
+			l.newline()
+		case '#':
+			for l.pos < len(l.source) && l.source[l.pos] != '\n' && l.source[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			if strings.HasPrefix(l.source[l.pos:], "\uFEFF") {
+				l.pos += len("\uFEFF")
+				continue
+			}
+			return
+		}
+	}
+}
+
+func (l *lexer) next() (token, error) {
+	l.skipIgnored()
+	loc := l.location()
+	if l.pos >= len(l.source) {
+		return token{kind: tokenEOF, loc: loc}, nil
+	}
+
+	c := l.source[l.pos]
+	switch {
+	case strings.HasPrefix(l.source[l.pos:], "..."):
+		l.pos += 3
+		return token{kind: tokenPunctuator, value: "...", loc: loc}, nil
+	case strings.IndexByte("!$&():=@[]{}|", c) >= 0:
+		l.pos++
+		return token{kind: tokenPunctuator, value: string(c), loc: loc}, nil
+	case isNameStart(c):
+		start := l.pos
+		for l.pos < len(l.source) && isNameContinue(l.source[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokenName, value: l.source[start:l.pos], loc: loc}, nil
+	case c == '-' || isDigit(c):
+		return l.readNumber(loc)
+	case strings.HasPrefix(l.source[l.pos:], `"""`):
+		return l.readBlockString(loc)
+	case c == '"':
+		return l.readString(loc)
+	}
+	r, _ := utf8.DecodeRuneInString(l.source[l.pos:])
+	return token{}, l.syntaxError(loc, "Unexpected character %q.", r)
+}
+
+func (l *lexer) readNumber(loc Location) (token, error) {
+	start := l.pos
+	kind := tokenInt
+	if l.source[l.pos] == '-' {
+		l.pos++
+	}
+	if l.pos < len(l.source) && l.source[l.pos] == '0' {
+		l.pos++
+		if l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+			return token{}, l.syntaxError(l.location(), "Invalid number, unexpected digit after 0.")
+		}
+	} else if !l.readDigits() {
+		return token{}, l.syntaxError(l.location(), "Invalid number, expected digit.")
+	}
+	if l.pos < len(l.source) && l.source[l.pos] == '.' {
+		kind = tokenFloat
+		l.pos++
+		if !l.readDigits() {
+			return token{}, l.syntaxError(l.location(), "Invalid number, expected digit after \".\".")
+		}
+	}
+	if l.pos < len(l.source) && (l.source[l.pos] == 'e' || l.source[l.pos] == 'E') {
+		kind = tokenFloat
+		l.pos++
+		if l.pos < len(l.source) && (l.source[l.pos] == '+' || l.source[l.pos] == '-') {
+			l.pos++
+		}
+		if !l.readDigits() {
+			return token{}, l.syntaxError(l.location(), "Invalid number, expected digit in exponent.")
+		}
+	}
+	if l.pos < len(l.source) && (l.source[l.pos] == '.' || isNameStart(l.source[l.pos])) {
+		return token{}, l.syntaxError(l.location(), "Invalid number, unexpected %q.", l.source[l.pos])
+	}
+	return token{kind: kind, value: l.source[start:l.pos], loc: loc}, nil
+}
+
+func (l *lexer) readDigits() bool {
+	start := l.pos
+	for l.pos < len(l.source) && isDigit(l.source[l.pos]) {
+		l.pos++
+	}
+	return l.pos > start
+}
+
+func (l *lexer) readString(loc Location) (token, error) {
+	l.pos++
+	var builder strings.Builder
+	for l.pos < len(l.source) {
+		c := l.source[l.pos]
+		switch c {
+		case '"':
+			l.pos++
+			return token{kind: tokenString, value: builder.String(), loc: loc}, nil
+		case '\n', '\r':
+			return token{}, l.syntaxError(l.location(), "Unterminated string.")
+		case '\\':
+			if l.pos+1 >= len(l.source) {
+				return token{}, l.syntaxError(l.location(), "Unterminated string.")
+			}
+			escape := l.source[l.pos+1]
+			switch escape {
+			case '"', '\\', '/':
+				builder.WriteByte(escape)
+			case 'b':
+				builder.WriteByte('\b')
+			case 'f':
+				builder.WriteByte('\f')
+			case 'n':
+				builder.WriteByte('\n')
+			case 'r':
+				builder.WriteByte('\r')
+			case 't':
+				builder.WriteByte('\t')
+			case 'u':
+				r, size, ok := l.readUnicodeEscape()
+				if !ok {
+					return token{}, l.syntaxError(l.location(), "Invalid Unicode escape sequence.")
+				}
+				builder.WriteRune(r)
+				l.pos += size
+				continue
+			default:
+				return token{}, l.syntaxError(l.location(), "Invalid character escape sequence: \\%c.", escape)
+			}
+			l.pos += 2
+		default:
+			builder.WriteByte(c)
+			l.pos++
+		}
+	}
+	return token{}, l.syntaxError(l.location(), "Unterminated string.")
+}
+
+// readUnicodeEscape reads \uXXXX, or a surrogate pair of them, at pos and returns the rune and the length of the escape
+func (l *lexer) readUnicodeEscape() (rune, int, bool) {
+	hex := func(at int) (rune, bool) {
+		if at+6 > len(l.source) || l.source[at:at+2] != `\u` {
+			return 0, false
+		}
+		value, err := strconv.ParseUint(l.source[at+2:at+6], 16, 32)
+		return rune(value), err == nil
+	}
+	r, ok := hex(l.pos)
+	if !ok {
+		return 0, 0, false
+	}
+	if r >= 0xD800 && r <= 0xDBFF {
+		low, ok := hex(l.pos + 6)
+		if !ok || low < 0xDC00 || low > 0xDFFF {
+			return 0, 0, false
+		}
+		return (r-0xD800)<<10 + (low - 0xDC00) + 0x10000, 12, true
+	}
+	if r >= 0xDC00 && r <= 0xDFFF {
+		return 0, 0, false
+	}
+	return r, 6, true
+}
+
+func (l *lexer) readBlockString(loc Location) (token, error) {
+	l.pos += 3
+	var raw strings.Builder
+	for l.pos < len(l.source) {
+		switch {
+		case strings.HasPrefix(l.source[l.pos:], `"""`):
+			l.pos += 3
+			return token{kind: tokenString, value: blockStringValue(raw.String()), loc: loc}, nil
+		case strings.HasPrefix(l.source[l.pos:], `\"""`):
+			raw.WriteString(`"""`)
+			l.pos += 4
+		case l.source[l.pos] == '\n':
+			raw.WriteByte('\n')
+			l.pos++
+			l.newline()
+		case l.source[l.pos] == '\r':
+			raw.WriteByte('\n')
+			l.pos++
+			if l.pos < len(l.source) && l.source[l.pos] == '\n' {
+				l.pos++
+			}
+			l.newline()
+		default:
+			raw.WriteByte(l.source[l.pos])
+			l.pos++
+		}
+	}
+	return token{}, l.syntaxError(l.location(), "Unterminated string.")
+}
+
+// blockStringValue removes the common indentation of a block string and its leading and trailing blank lines
+func blockStringValue(raw string) string {
+	lines := strings.Split(raw, "\n")
+	commonIndent := -1
+	for _, line := range lines[1:] {
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		if indent == len(line) {
+			continue
+		}
+		if commonIndent == -1 || indent < commonIndent {
+			commonIndent = indent
+		}
+	}
+	if commonIndent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= commonIndent {
+				lines[i] = lines[i][commonIndent:]
+			} else {
+				lines[i] = ""
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isNameContinue(c byte) bool {
+	return isNameStart(c) || isDigit(c)
+}
+
+// IsValidName reports whether name can be used as the name of a field, argument or type
+func IsValidName(name string) bool {
+	if name == "" || !isNameStart(name[0]) || strings.HasPrefix(name, "__") {
+		return false
+	}
+	for i := 1; i < len(name); i++ {
+		if !isNameContinue(name[i]) {
+			return false
+		}
+	}
+	return true
+}
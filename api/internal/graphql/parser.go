@@ -0,0 +1,289 @@
+package graphql
+
+import "fmt"
+
+// parser is a recursive descent parser for executable documents.
+// Errors are raised with panic and recovered by Parse, which keeps the grammar functions short.
+type parser struct {
+	lexer *lexer
+	tok   token
+}
+
+// Parse parses a request into a document of operations and fragments
+func Parse(source string) (doc *Document, err error) {
+	p := &parser{lexer: newLexer(source)}
+	defer func() {
+		if r := recover(); r != nil {
+			parseErr, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, parseErr
+		}
+	}()
+	p.advance()
+	return p.parseDocument(), nil
+}
+
+func (p *parser) advance() {
+	tok, err := p.lexer.next()
+	if err != nil {
+		panic(err)
+	}
+	p.tok = tok
+}
+
+func (p *parser) fail(format string, args ...any) {
+	panic(p.lexer.syntaxError(p.tok.loc, format, args...))
+}
+
+// peek reports whether the current token is the punctuator value
+func (p *parser) peek(value string) bool {
+	return p.tok.kind == tokenPunctuator && p.tok.value == value
+}
+
+func (p *parser) peekKeyword(name string) bool {
+	return p.tok.kind == tokenName && p.tok.value == name
+}
+
+func (p *parser) skip(value string) bool {
+	if p.peek(value) {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(value string) {
+	if !p.skip(value) {
+		p.fail("Expected %q, found %s.", value, p.tok)
+	}
+}
+
+func (p *parser) expectKeyword(name string) {
+	if !p.peekKeyword(name) {
+		p.fail("Expected %q, found %s.", name, p.tok)
+	}
+	p.advance()
+}
+
+func (p *parser) parseName() string {
+	if p.tok.kind != tokenName {
+		p.fail("Expected Name, found %s.", p.tok)
+	}
+	name := p.tok.value
+	p.advance()
+	return name
+}
+
+func (p *parser) parseDocument() *Document {
+	doc := &Document{Fragments: map[string]*FragmentDefinition{}}
+	if p.tok.kind == tokenEOF {
+		p.fail("Unexpected %s.", p.tok)
+	}
+	for p.tok.kind != tokenEOF {
+		switch {
+		case p.peek("{"):
+			doc.Operations = append(doc.Operations, &Operation{
+				Type:         "query",
+				Loc:          p.tok.loc,
+				SelectionSet: p.parseSelectionSet(),
+			})
+		case p.peekKeyword("query"), p.peekKeyword("mutation"), p.peekKeyword("subscription"):
+			doc.Operations = append(doc.Operations, p.parseOperation())
+		case p.peekKeyword("fragment"):
+			fragment := p.parseFragmentDefinition()
+			if _, ok := doc.Fragments[fragment.Name]; ok {
+				panic(&Error{
+					Message:   fmt.Sprintf("There can be only one fragment named %q.", fragment.Name),
+					Locations: []Location{fragment.Loc},
+				})
+			}
+			doc.Fragments[fragment.Name] = fragment
+		default:
+			p.fail("Unexpected %s.", p.tok)
+		}
+	}
+	return doc
+}
+
+func (p *parser) parseOperation() *Operation {
+	op := &Operation{Type: p.tok.value, Loc: p.tok.loc}
+	p.advance()
+	if p.tok.kind == tokenName {
+		op.Name = p.parseName()
+	}
+	if p.skip("(") {
+		for !p.skip(")") {
+			op.VariableDefinitions = append(op.VariableDefinitions, p.parseVariableDefinition())
+		}
+	}
+	op.Directives = p.parseDirectives(false)
+	op.SelectionSet = p.parseSelectionSet()
+	return op
+}
+
+func (p *parser) parseVariableDefinition() *VariableDefinition {
+	def := &VariableDefinition{Loc: p.tok.loc}
+	p.expect("$")
+	def.Name = p.parseName()
+	p.expect(":")
+	def.Type = p.parseType()
+	if p.skip("=") {
+		def.DefaultValue = p.parseValue(true)
+	}
+	// directives on variable definitions have no meaning here
+	p.parseDirectives(true)
+	return def
+}
+
+func (p *parser) parseType() *TypeNode {
+	t := &TypeNode{}
+	if p.skip("[") {
+		t.Elem = p.parseType()
+		p.expect("]")
+	} else {
+		t.Name = p.parseName()
+	}
+	t.NonNull = p.skip("!")
+	return t
+}
+
+func (p *parser) parseSelectionSet() []Selection {
+	p.expect("{")
+	selections := []Selection{}
+	for !p.skip("}") {
+		selections = append(selections, p.parseSelection())
+	}
+	if len(selections) == 0 {
+		p.fail("Expected Name, found \"}\".")
+	}
+	return selections
+}
+
+func (p *parser) parseSelection() Selection {
+	if !p.peek("...") {
+		return p.parseField()
+	}
+	loc := p.tok.loc
+	p.advance()
+	if p.tok.kind == tokenName && p.tok.value != "on" {
+		return &FragmentSpread{
+			Name:       p.parseName(),
+			Directives: p.parseDirectives(false),
+			Loc:        loc,
+		}
+	}
+	fragment := &InlineFragment{Loc: loc}
+	if p.peekKeyword("on") {
+		p.advance()
+		fragment.TypeCondition = p.parseName()
+	}
+	fragment.Directives = p.parseDirectives(false)
+	fragment.SelectionSet = p.parseSelectionSet()
+	return fragment
+}
+
+func (p *parser) parseField() *FieldNode {
+	field := &FieldNode{Loc: p.tok.loc}
+	field.Name = p.parseName()
+	if p.skip(":") {
+		field.Alias = field.Name
+		field.Name = p.parseName()
+	}
+	field.Arguments = p.parseArguments(false)
+	field.Directives = p.parseDirectives(false)
+	if p.peek("{") {
+		field.SelectionSet = p.parseSelectionSet()
+	}
+	return field
+}
+
+func (p *parser) parseArguments(isConst bool) []*Argument {
+	if !p.skip("(") {
+		return nil
+	}
+	arguments := []*Argument{}
+	for !p.skip(")") {
+		argument := &Argument{Loc: p.tok.loc}
+		argument.Name = p.parseName()
+		p.expect(":")
+		argument.Value = p.parseValue(isConst)
+		arguments = append(arguments, argument)
+	}
+	return arguments
+}
+
+func (p *parser) parseDirectives(isConst bool) []*DirectiveNode {
+	var directives []*DirectiveNode
+	for p.peek("@") {
+		directive := &DirectiveNode{Loc: p.tok.loc}
+		p.advance()
+		directive.Name = p.parseName()
+		directive.Arguments = p.parseArguments(isConst)
+		directives = append(directives, directive)
+	}
+	return directives
+}
+
+func (p *parser) parseFragmentDefinition() *FragmentDefinition {
+	fragment := &FragmentDefinition{Loc: p.tok.loc}
+	p.expectKeyword("fragment")
+	if p.peekKeyword("on") {
+		p.fail("Unexpected %s.", p.tok)
+	}
+	fragment.Name = p.parseName()
+	p.expectKeyword("on")
+	fragment.TypeCondition = p.parseName()
+	fragment.Directives = p.parseDirectives(false)
+	fragment.SelectionSet = p.parseSelectionSet()
+	return fragment
+}
+
+// parseValue parses a literal, isConst disallows variables as in default values
+func (p *parser) parseValue(isConst bool) Value {
+	tok := p.tok
+	switch tok.kind {
+	case tokenInt:
+		p.advance()
+		return &IntValue{Raw: tok.value}
+	case tokenFloat:
+		p.advance()
+		return &FloatValue{Raw: tok.value}
+	case tokenString:
+		p.advance()
+		return &StringValue{Value: tok.value}
+	case tokenName:
+		p.advance()
+		switch tok.value {
+		case "true", "false":
+			return &BooleanValue{Value: tok.value == "true"}
+		case "null":
+			return &NullValue{}
+		}
+		return &EnumValue{Name: tok.value}
+	}
+
+	switch {
+	case p.peek("$") && !isConst:
+		p.advance()
+		return &Variable{Name: p.parseName(), Loc: tok.loc}
+	case p.skip("["):
+		list := &ListValue{Values: []Value{}}
+		for !p.skip("]") {
+			list.Values = append(list.Values, p.parseValue(isConst))
+		}
+		return list
+	case p.skip("{"):
+		object := &ObjectValue{Fields: []*ObjectField{}}
+		for !p.skip("}") {
+			field := &ObjectField{Name: p.parseName()}
+			p.expect(":")
+			field.Value = p.parseValue(isConst)
+			object.Fields = append(object.Fields, field)
+		}
+		return object
+	}
+	p.fail("Unexpected %s.", tok)
+	return nil
+}
@@ -0,0 +1,130 @@
+package graphql
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	doc, err := Parse(`
+		# comments are ignored
+		query Books($genre: Genre = FANTASY, $first: Int!, $tags: [String!]) @include(if: true) {
+			books(genre: $genre, limit: $first) {
+				...bookFields
+				... on Book @skip(if: false) { author { name } }
+				alias: title
+			}
+		}
+
+		fragment bookFields on Book {
+			id
+			title
+			meta(value: {list: [1, 2.5, "s", """block""", null, true, ENUM], nested: {a: $first}})
+		}
+	`)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if len(doc.Operations) != 1 {
+		t.Fatalf("got %d operations, want 1", len(doc.Operations))
+	}
+	op := doc.Operations[0]
+	if op.Type != "query" || op.Name != "Books" || op.Loc != (Location{Line: 3, Column: 3}) {
+		t.Errorf("got operation %s %s at %v", op.Type, op.Name, op.Loc)
+	}
+	variableTypes := []string{}
+	for _, def := range op.VariableDefinitions {
+		variableTypes = append(variableTypes, def.Name+": "+def.Type.String())
+	}
+	if want := []string{"genre: Genre", "first: Int!", "tags: [String!]"}; !reflect.DeepEqual(variableTypes, want) {
+		t.Errorf("got variables %v, want %v", variableTypes, want)
+	}
+	if defaultValue, ok := op.VariableDefinitions[0].DefaultValue.(*EnumValue); !ok || defaultValue.Name != "FANTASY" {
+		t.Errorf("got default value %#v, want the enum value FANTASY", op.VariableDefinitions[0].DefaultValue)
+	}
+
+	books := op.SelectionSet[0].(*FieldNode)
+	if len(books.Arguments) != 2 || books.Arguments[1].Value.(*Variable).Name != "first" {
+		t.Errorf("got arguments %#v", books.Arguments)
+	}
+	if spread, ok := books.SelectionSet[0].(*FragmentSpread); !ok || spread.Name != "bookFields" {
+		t.Errorf("got %#v, want a spread of bookFields", books.SelectionSet[0])
+	}
+	if inline, ok := books.SelectionSet[1].(*InlineFragment); !ok || inline.TypeCondition != "Book" || len(inline.Directives) != 1 {
+		t.Errorf("got %#v, want an inline fragment on Book", books.SelectionSet[1])
+	}
+	if alias := books.SelectionSet[2].(*FieldNode); alias.ResponseKey() != "alias" || alias.Name != "title" {
+		t.Errorf("got field %s with key %s", alias.Name, alias.ResponseKey())
+	}
+
+	fragment, ok := doc.Fragments["bookFields"]
+	if !ok || fragment.TypeCondition != "Book" || len(fragment.SelectionSet) != 3 {
+		t.Fatalf("got fragment %#v", fragment)
+	}
+	meta := fragment.SelectionSet[2].(*FieldNode)
+	value, err := literalToJSON(meta.Arguments[0].Value, map[string]any{"first": 10})
+	if err != nil {
+		t.Fatalf("literalToJSON: %v", err)
+	}
+	want := map[string]any{
+		"list":   []any{float64(1), 2.5, "s", "block", nil, true, "ENUM"},
+		"nested": map[string]any{"a": 10},
+	}
+	if !reflect.DeepEqual(value, want) {
+		t.Errorf("got value %#v, want %#v", value, want)
+	}
+}
+
+func TestParseStrings(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`"plain"`, "plain"},
+		{`"escapes \" \\ \/ \b \f \n \r \t"`, "escapes \" \\ / \b \f \n \r \t"},
+		{`"unicode \u00e9 é \uD83D\uDE00 😀"`, "unicode é é 😀 😀"},
+		{"\"\"\"\n    block\n      indented\n    \\\"\"\"\n\"\"\"", "block\n  indented\n\"\"\""},
+	}
+	for _, tt := range tests {
+		doc, err := Parse(`{ field(value: ` + tt.source + `) }`)
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.source, err)
+			continue
+		}
+		value := doc.Operations[0].SelectionSet[0].(*FieldNode).Arguments[0].Value.(*StringValue)
+		if value.Value != tt.want {
+			t.Errorf("Parse(%s) = %q, want %q", tt.source, value.Value, tt.want)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		source   string
+		location Location
+	}{
+		{``, Location{Line: 1, Column: 1}},
+		{`{`, Location{Line: 1, Column: 2}},
+		{`{ field(arg: ) }`, Location{Line: 1, Column: 14}},
+		{"{\n  field(arg: $var)\n}\nfragment on on Book { id }", Location{Line: 4, Column: 10}},
+		{`query ($var: ) { field }`, Location{Line: 1, Column: 14}},
+		{`{ field(arg: "unterminated) }`, Location{Line: 1, Column: 30}},
+		{`{ field(arg: 01) }`, Location{Line: 1, Column: 15}},
+		{`{ field(arg: "\x") }`, Location{Line: 1, Column: 15}},
+		{`{ field } ?`, Location{Line: 1, Column: 11}},
+		{`fragment a on T { id } fragment a on T { id }`, Location{Line: 1, Column: 24}},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.source)
+		var graphqlErr *Error
+		if !errors.As(err, &graphqlErr) {
+			t.Errorf("Parse(%q) got error %v, want an *Error", tt.source, err)
+			continue
+		}
+		if len(graphqlErr.Locations) != 1 || graphqlErr.Locations[0] != tt.location {
+			t.Errorf("Parse(%q) got error %v at %v, want %v", tt.source, err, graphqlErr.Locations, tt.location)
+		}
+	}
+}
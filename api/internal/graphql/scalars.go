@@ -0,0 +1,260 @@
+package graphql
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// maxSafeInteger is the largest integer a float64 holds exactly. Int accepts integers up to it
+// rather than the 32 bits of the specification, because ids and timestamps in json documents are often larger.
+const maxSafeInteger = 1<<53 - 1
+
+var Int = &Scalar{
+	Name:        "Int",
+	Description: "The `Int` scalar type represents non-fractional signed whole numeric values.",
+	Serialize: func(value any) (any, error) {
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case int64:
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) <= maxSafeInteger {
+				return int64(v), nil
+			}
+		case bool:
+			if v {
+				return 1, nil
+			}
+			return 0, nil
+		}
+		return nil, fmt.Errorf("Int cannot represent non-integer value: %v", inspect(value))
+	},
+	ParseValue: func(value any) (any, error) {
+		switch v := value.(type) {
+		case int:
+			return v, nil
+		case float64:
+			if v == math.Trunc(v) && math.Abs(v) <= maxSafeInteger {
+				return int(v), nil
+			}
+		}
+		return nil, fmt.Errorf("Int cannot represent non-integer value: %v", inspect(value))
+	},
+	ParseLiteral: func(value Value, _ map[string]any) (any, error) {
+		if v, ok := value.(*IntValue); ok {
+			n, err := strconv.ParseInt(v.Raw, 10, 64)
+			if err == nil && n >= -maxSafeInteger && n <= maxSafeInteger {
+				return int(n), nil
+			}
+		}
+		return nil, fmt.Errorf("Int cannot represent non-integer value: %s", printLiteral(value))
+	},
+}
+
+var Float = &Scalar{
+	Name:        "Float",
+	Description: "The `Float` scalar type represents signed double-precision fractional values as specified by IEEE 754.",
+	Serialize: func(value any) (any, error) {
+		switch v := value.(type) {
+		case int:
+			return float64(v), nil
+		case int64:
+			return float64(v), nil
+		case float64:
+			if !math.IsInf(v, 0) && !math.IsNaN(v) {
+				return v, nil
+			}
+		case bool:
+			if v {
+				return 1.0, nil
+			}
+			return 0.0, nil
+		}
+		return nil, fmt.Errorf("Float cannot represent non numeric value: %v", inspect(value))
+	},
+	ParseValue: func(value any) (any, error) {
+		switch v := value.(type) {
+		case int:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		}
+		return nil, fmt.Errorf("Float cannot represent non numeric value: %v", inspect(value))
+	},
+	ParseLiteral: func(value Value, _ map[string]any) (any, error) {
+		switch v := value.(type) {
+		case *IntValue:
+			return strconv.ParseFloat(v.Raw, 64)
+		case *FloatValue:
+			return strconv.ParseFloat(v.Raw, 64)
+		}
+		return nil, fmt.Errorf("Float cannot represent non numeric value: %s", printLiteral(value))
+	},
+}
+
+var String = &Scalar{
+	Name:        "String",
+	Description: "The `String` scalar type represents textual data, represented as UTF-8 character sequences.",
+	Serialize: func(value any) (any, error) {
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case bool:
+			return strconv.FormatBool(v), nil
+		case int:
+			return strconv.Itoa(v), nil
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), nil
+		}
+		return nil, fmt.Errorf("String cannot represent value: %v", inspect(value))
+	},
+	ParseValue: func(value any) (any, error) {
+		if v, ok := value.(string); ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("String cannot represent a non string value: %v", inspect(value))
+	},
+	ParseLiteral: func(value Value, _ map[string]any) (any, error) {
+		if v, ok := value.(*StringValue); ok {
+			return v.Value, nil
+		}
+		return nil, fmt.Errorf("String cannot represent a non string value: %s", printLiteral(value))
+	},
+}
+
+var Boolean = &Scalar{
+	Name:        "Boolean",
+	Description: "The `Boolean` scalar type represents `true` or `false`.",
+	Serialize: func(value any) (any, error) {
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("Boolean cannot represent a non boolean value: %v", inspect(value))
+	},
+	ParseValue: func(value any) (any, error) {
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+		return nil, fmt.Errorf("Boolean cannot represent a non boolean value: %v", inspect(value))
+	},
+	ParseLiteral: func(value Value, _ map[string]any) (any, error) {
+		if v, ok := value.(*BooleanValue); ok {
+			return v.Value, nil
+		}
+		return nil, fmt.Errorf("Boolean cannot represent a non boolean value: %s", printLiteral(value))
+	},
+}
+
+// ID values are strings, integer ids are converted to strings
+var ID = &Scalar{
+	Name:        "ID",
+	Description: "The `ID` scalar type represents a unique identifier, serialized as a string.",
+	Serialize: func(value any) (any, error) {
+		if id, ok := idString(value); ok {
+			return id, nil
+		}
+		return nil, fmt.Errorf("ID cannot represent value: %v", inspect(value))
+	},
+	ParseValue: func(value any) (any, error) {
+		if id, ok := idString(value); ok {
+			return id, nil
+		}
+		return nil, fmt.Errorf("ID cannot represent value: %v", inspect(value))
+	},
+	ParseLiteral: func(value Value, _ map[string]any) (any, error) {
+		switch v := value.(type) {
+		case *StringValue:
+			return v.Value, nil
+		case *IntValue:
+			return v.Raw, nil
+		}
+		return nil, fmt.Errorf("ID cannot represent a non-string and non-integer value: %s", printLiteral(value))
+	},
+}
+
+// JSON is any json value, for values that have no fixed shape
+var JSON = &Scalar{
+	Name:        "JSON",
+	Description: "The `JSON` scalar type represents any json value.",
+	Serialize: func(value any) (any, error) {
+		return value, nil
+	},
+	ParseValue: func(value any) (any, error) {
+		return value, nil
+	},
+	ParseLiteral: literalToJSON,
+}
+
+func idString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case int:
+		return strconv.Itoa(v), true
+	case int64:
+		return strconv.FormatInt(v, 10), true
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) <= maxSafeInteger {
+			return strconv.FormatInt(int64(v), 10), true
+		}
+	}
+	return "", false
+}
+
+// literalToJSON converts a literal into the value encoding/json would decode, numbers become float64
+func literalToJSON(value Value, variables map[string]any) (any, error) {
+	switch v := value.(type) {
+	case *Variable:
+		return variables[v.Name], nil
+	case *IntValue:
+		return strconv.ParseFloat(v.Raw, 64)
+	case *FloatValue:
+		return strconv.ParseFloat(v.Raw, 64)
+	case *StringValue:
+		return v.Value, nil
+	case *BooleanValue:
+		return v.Value, nil
+	case *NullValue:
+		return nil, nil
+	case *EnumValue:
+		return v.Name, nil
+	case *ListValue:
+		list := []any{}
+		for _, item := range v.Values {
+			itemValue, err := literalToJSON(item, variables)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, itemValue)
+		}
+		return list, nil
+	case *ObjectValue:
+		object := map[string]any{}
+		for _, field := range v.Fields {
+			fieldValue, err := literalToJSON(field.Value, variables)
+			if err != nil {
+				return nil, err
+			}
+			object[field.Name] = fieldValue
+		}
+		return object, nil
+	}
+	return nil, fmt.Errorf("unknown literal %T", value)
+}
+
+// inspect formats a value for error messages
+func inspect(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case string:
+		return strconv.Quote(v)
+	case map[string]any:
+		return "{...}"
+	case []any:
+		return "[...]"
+	}
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,307 @@
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// Type is a *Scalar, *Object, *InputObject, *Enum, or a *List or *NonNull wrapping one of them
+type Type interface {
+	String() string
+}
+
+// NamedType is a type that is not a wrapper
+type NamedType interface {
+	Type
+	TypeName() string
+	TypeDescription() string
+}
+
+type ResolveParams struct {
+	Context context.Context
+	// Source is the value of the object the field belongs to, nil for the fields of the root types
+	Source any
+	Args   map[string]any
+}
+
+// ResolveFunc returns the value of a field. Lists are returned as []any and objects as any value
+// the resolvers of the object type understand. Without a resolver, fields are read from a map[string]any source.
+type ResolveFunc func(p ResolveParams) (any, error)
+
+type Object struct {
+	Name        string
+	Description string
+	Fields      []*Field
+}
+
+type Field struct {
+	Name        string
+	Description string
+	Args        []*InputValue
+	Type        Type
+	Resolve     ResolveFunc
+}
+
+// InputValue is an argument of a field or directive, or a field of an input object.
+// A nil DefaultValue means the value has no default.
+type InputValue struct {
+	Name         string
+	Description  string
+	Type         Type
+	DefaultValue any
+}
+
+type InputObject struct {
+	Name        string
+	Description string
+	Fields      []*InputValue
+}
+
+// Enum values are represented by their names when resolving and coercing
+type Enum struct {
+	Name        string
+	Description string
+	Values      []*EnumValueDefinition
+}
+
+type EnumValueDefinition struct {
+	Name        string
+	Description string
+}
+
+// Scalar converts between the values returned by resolvers and the values of responses and requests
+type Scalar struct {
+	Name        string
+	Description string
+	// Serialize converts a resolved value into a value of the response
+	Serialize func(value any) (any, error)
+	// ParseValue converts a value of a variable, as decoded by encoding/json
+	ParseValue func(value any) (any, error)
+	// ParseLiteral converts a literal of the request
+	ParseLiteral func(value Value, variables map[string]any) (any, error)
+}
+
+type List struct {
+	OfType Type
+}
+
+type NonNull struct {
+	OfType Type
+}
+
+// NewList returns the type [t]
+func NewList(t Type) *List {
+	return &List{OfType: t}
+}
+
+// NewNonNull returns the type t!
+func NewNonNull(t Type) *NonNull {
+	return &NonNull{OfType: t}
+}
+
+func (t *Scalar) String() string      { return t.Name }
+func (t *Object) String() string      { return t.Name }
+func (t *InputObject) String() string { return t.Name }
+func (t *Enum) String() string        { return t.Name }
+func (t *List) String() string        { return "[" + t.OfType.String() + "]" }
+func (t *NonNull) String() string     { return t.OfType.String() + "!" }
+
+func (t *Scalar) TypeName() string      { return t.Name }
+func (t *Object) TypeName() string      { return t.Name }
+func (t *InputObject) TypeName() string { return t.Name }
+func (t *Enum) TypeName() string        { return t.Name }
+
+func (t *Scalar) TypeDescription() string      { return t.Description }
+func (t *Object) TypeDescription() string      { return t.Description }
+func (t *InputObject) TypeDescription() string { return t.Description }
+func (t *Enum) TypeDescription() string        { return t.Description }
+
+// Field returns the field with the given name, or nil if the object has none
+func (t *Object) Field(name string) *Field {
+	for _, field := range t.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+func (t *InputObject) Field(name string) *InputValue {
+	for _, field := range t.Fields {
+		if field.Name == name {
+			return field
+		}
+	}
+	return nil
+}
+
+func (t *Enum) hasValue(name string) bool {
+	return slices.ContainsFunc(t.Values, func(value *EnumValueDefinition) bool { return value.Name == name })
+}
+
+// namedType removes the list and non null wrappers of t
+func namedType(t Type) NamedType {
+	for {
+		switch wrapper := t.(type) {
+		case *List:
+			t = wrapper.OfType
+		case *NonNull:
+			t = wrapper.OfType
+		default:
+			return t.(NamedType)
+		}
+	}
+}
+
+func isNonNull(t Type) bool {
+	_, ok := t.(*NonNull)
+	return ok
+}
+
+func isInputType(t Type) bool {
+	switch namedType(t).(type) {
+	case *Scalar, *Enum, *InputObject:
+		return true
+	}
+	return false
+}
+
+func isLeafType(t Type) bool {
+	switch namedType(t).(type) {
+	case *Scalar, *Enum:
+		return true
+	}
+	return false
+}
+
+type Directive struct {
+	Name        string
+	Description string
+	Locations   []string
+	Args        []*InputValue
+}
+
+var (
+	SkipDirective = &Directive{
+		Name:        "skip",
+		Description: "Directs the executor to skip this field or fragment when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args: []*InputValue{
+			{Name: "if", Description: "Skipped when true.", Type: NewNonNull(Boolean)},
+		},
+	}
+	IncludeDirective = &Directive{
+		Name:        "include",
+		Description: "Directs the executor to include this field or fragment only when the `if` argument is true.",
+		Locations:   []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args: []*InputValue{
+			{Name: "if", Description: "Included when true.", Type: NewNonNull(Boolean)},
+		},
+	}
+	DeprecatedDirective = &Directive{
+		Name:        "deprecated",
+		Description: "Marks an element of a GraphQL schema as no longer supported.",
+		Locations:   []string{"FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INPUT_FIELD_DEFINITION", "ENUM_VALUE"},
+		Args: []*InputValue{
+			{Name: "reason", Description: "Explains why this element was deprecated.", Type: String, DefaultValue: "No longer supported"},
+		},
+	}
+	SpecifiedByDirective = &Directive{
+		Name:        "specifiedBy",
+		Description: "Exposes a URL that specifies the behavior of this scalar.",
+		Locations:   []string{"SCALAR"},
+		Args: []*InputValue{
+			{Name: "url", Description: "The URL that specifies the behavior of this scalar.", Type: NewNonNull(String)},
+		},
+	}
+)
+
+// Schema is the set of types reachable from the root types
+type Schema struct {
+	Query      *Object
+	Mutation   *Object
+	Directives []*Directive
+	types      map[string]NamedType
+}
+
+// NewSchema collects the types reachable from the query and mutation types, mutation can be nil.
+// Two different types with the same name are an error.
+func NewSchema(query *Object, mutation *Object) (*Schema, error) {
+	s := &Schema{
+		Query:      query,
+		Mutation:   mutation,
+		Directives: []*Directive{IncludeDirective, SkipDirective, DeprecatedDirective, SpecifiedByDirective},
+		types:      map[string]NamedType{},
+	}
+	roots := []Type{query, schemaType, String, Boolean}
+	if mutation != nil {
+		roots = append(roots, mutation)
+	}
+	for _, root := range roots {
+		if err := s.addType(root); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *Schema) addType(t Type) error {
+	named := namedType(t)
+	if existing, ok := s.types[named.TypeName()]; ok {
+		if existing != named {
+			return fmt.Errorf("NewSchema: schema must contain unique named types but contains multiple types named %q", named.TypeName())
+		}
+		return nil
+	}
+	s.types[named.TypeName()] = named
+
+	switch named := named.(type) {
+	case *Object:
+		for _, field := range named.Fields {
+			if err := s.addType(field.Type); err != nil {
+				return err
+			}
+			for _, arg := range field.Args {
+				if err := s.addType(arg.Type); err != nil {
+					return err
+				}
+			}
+		}
+	case *InputObject:
+		for _, field := range named.Fields {
+			if err := s.addType(field.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Type returns the named type called name, or nil if the schema has none
+func (s *Schema) Type(name string) NamedType {
+	return s.types[name]
+}
+
+// Types returns every named type of the schema, sorted by name
+func (s *Schema) Types() []NamedType {
+	names := make([]string, 0, len(s.types))
+	for name := range s.types {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	types := make([]NamedType, 0, len(names))
+	for _, name := range names {
+		types = append(types, s.types[name])
+	}
+	return types
+}
+
+func (s *Schema) directive(name string) *Directive {
+	for _, directive := range s.Directives {
+		if directive.Name == name {
+			return directive
+		}
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package graphql
+
+import "fmt"
+
+// validator checks the parts of a document that would otherwise only fail for some values,
+// such as unknown fields inside lists that happen to be empty. Values of arguments are checked when they are coerced.
+type validator struct {
+	schema    *Schema
+	doc       *Document
+	variables map[string]bool
+	visited   map[string]bool
+	errors    []*Error
+}
+
+func validate(schema *Schema, doc *Document) []*Error {
+	v := &validator{schema: schema, doc: doc}
+
+	operationNames := map[string]bool{}
+	for _, op := range doc.Operations {
+		if op.Name == "" && len(doc.Operations) > 1 {
+			v.errorf(op.Loc, "This anonymous operation must be the only defined operation.")
+		}
+		if op.Name != "" && operationNames[op.Name] {
+			v.errorf(op.Loc, "There can be only one operation named %q.", op.Name)
+		}
+		operationNames[op.Name] = true
+
+		root := v.rootType(op)
+		if root == nil {
+			continue
+		}
+		v.variables = map[string]bool{}
+		for _, def := range op.VariableDefinitions {
+			if v.variables[def.Name] {
+				v.errorf(def.Loc, "There can be only one variable named \"$%s\".", def.Name)
+			}
+			v.variables[def.Name] = true
+			t := typeFromNode(schema, def.Type)
+			if t == nil {
+				v.errorf(def.Loc, "Unknown type %q.", typeNodeName(def.Type))
+			} else if !isInputType(t) {
+				v.errorf(def.Loc, "Variable \"$%s\" cannot be non-input type %q.", def.Name, def.Type)
+			}
+		}
+		v.visited = map[string]bool{}
+		v.checkDirectives(op.Directives)
+		v.checkSelectionSet(op.SelectionSet, root)
+	}
+
+	for _, fragment := range doc.Fragments {
+		if schema.Type(fragment.TypeCondition) == nil {
+			v.errorf(fragment.Loc, "Unknown type %q.", fragment.TypeCondition)
+		}
+	}
+	return v.errors
+}
+
+func (v *validator) errorf(loc Location, format string, args ...any) {
+	v.errors = append(v.errors, &Error{
+		Message:   fmt.Sprintf(format, args...),
+		Locations: []Location{loc},
+	})
+}
+
+func (v *validator) rootType(op *Operation) *Object {
+	switch op.Type {
+	case "query":
+		return v.schema.Query
+	case "mutation":
+		if v.schema.Mutation == nil {
+			v.errorf(op.Loc, "Schema is not configured for mutations.")
+		}
+		return v.schema.Mutation
+	}
+	v.errorf(op.Loc, "Subscriptions are not supported.")
+	return nil
+}
+
+func (v *validator) checkSelectionSet(selections []Selection, parent *Object) {
+	for _, selection := range selections {
+		v.checkDirectives(selection.directives())
+		switch selection := selection.(type) {
+		case *FieldNode:
+			v.checkField(selection, parent)
+		case *FragmentSpread:
+			fragment, ok := v.doc.Fragments[selection.Name]
+			if !ok {
+				v.errorf(selection.Loc, "Unknown fragment %q.", selection.Name)
+				continue
+			}
+			if !v.checkTypeCondition(fragment.TypeCondition, parent, selection.Loc) || v.visited[selection.Name] {
+				continue
+			}
+			v.visited[selection.Name] = true
+			v.checkDirectives(fragment.Directives)
+			v.checkSelectionSet(fragment.SelectionSet, parent)
+			delete(v.visited, selection.Name)
+		case *InlineFragment:
+			if selection.TypeCondition != "" && !v.checkTypeCondition(selection.TypeCondition, parent, selection.Loc) {
+				continue
+			}
+			v.checkSelectionSet(selection.SelectionSet, parent)
+		}
+	}
+}
+
+// checkTypeCondition checks that a fragment on typeCondition can be spread in parent.
+// Every type with fields is an object, so the condition must be the parent itself.
+func (v *validator) checkTypeCondition(typeCondition string, parent *Object, loc Location) bool {
+	t := v.schema.Type(typeCondition)
+	if t == nil {
+		v.errorf(loc, "Unknown type %q.", typeCondition)
+		return false
+	}
+	if t != parent {
+		v.errorf(loc, "Fragment cannot be spread here as objects of type %q can never be of type %q.", parent.Name, typeCondition)
+		return false
+	}
+	return true
+}
+
+func (v *validator) checkField(field *FieldNode, parent *Object) {
+	def := fieldDefinition(v.schema, parent, field.Name)
+	if def == nil {
+		v.errorf(field.Loc, "Cannot query field %q on type %q.", field.Name, parent.Name)
+		return
+	}
+	v.checkArguments(field.Arguments, def.Args, field.Loc, fmt.Sprintf("field %q", field.Name))
+
+	switch named := namedType(def.Type).(type) {
+	case *Object:
+		if field.SelectionSet == nil {
+			v.errorf(field.Loc, "Field %q of type %q must have a selection of subfields. Did you mean \"%s { ... }\"?", field.Name, def.Type, field.Name)
+			return
+		}
+		v.checkSelectionSet(field.SelectionSet, named)
+	default:
+		if field.SelectionSet != nil {
+			v.errorf(field.Loc, "Field %q must not have a selection since type %q has no subfields.", field.Name, def.Type)
+		}
+	}
+}
+
+func (v *validator) checkDirectives(directives []*DirectiveNode) {
+	for _, directive := range directives {
+		def := v.schema.directive(directive.Name)
+		if def == nil {
+			v.errorf(directive.Loc, "Unknown directive \"@%s\".", directive.Name)
+			continue
+		}
+		v.checkArguments(directive.Arguments, def.Args, directive.Loc, "directive \"@"+directive.Name+"\"")
+	}
+}
+
+func (v *validator) checkArguments(arguments []*Argument, defs []*InputValue, loc Location, owner string) {
+	given := map[string]bool{}
+	for _, argument := range arguments {
+		if given[argument.Name] {
+			v.errorf(argument.Loc, "There can be only one argument named %q.", argument.Name)
+		}
+		given[argument.Name] = true
+		found := false
+		for _, def := range defs {
+			found = found || def.Name == argument.Name
+		}
+		if !found {
+			v.errorf(argument.Loc, "Unknown argument %q on %s.", argument.Name, owner)
+		}
+		v.checkVariables(argument.Value, argument.Loc)
+	}
+	for _, def := range defs {
+		if isNonNull(def.Type) && def.DefaultValue == nil && !given[def.Name] {
+			v.errorf(loc, "Argument %q of type %q is required on %s, but it was not provided.", def.Name, def.Type, owner)
+		}
+	}
+}
+
+// checkVariables checks that the variables used in value are defined by the operation
+func (v *validator) checkVariables(value Value, loc Location) {
+	switch value := value.(type) {
+	case *Variable:
+		if !v.variables[value.Name] {
+			v.errorf(value.Loc, "Variable \"$%s\" is not defined.", value.Name)
+		}
+	case *ListValue:
+		for _, item := range value.Values {
+			v.checkVariables(item, loc)
+		}
+	case *ObjectValue:
+		for _, field := range value.Fields {
+			v.checkVariables(field.Value, loc)
+		}
+	}
+}
+
+// typeFromNode returns the schema type of a variable definition, or nil if it names an unknown type
+func typeFromNode(schema *Schema, node *TypeNode) Type {
+	var t Type
+	if node.Elem != nil {
+		elem := typeFromNode(schema, node.Elem)
+		if elem == nil {
+			return nil
+		}
+		t = NewList(elem)
+	} else {
+		named := schema.Type(node.Name)
+		if named == nil {
+			return nil
+		}
+		t = named
+	}
+	if node.NonNull {
+		t = NewNonNull(t)
+	}
+	return t
+}
+
+func typeNodeName(node *TypeNode) string {
+	for node.Elem != nil {
+		node = node.Elem
+	}
+	return node.Name
+}
@@ -0,0 +1,235 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// coerceValue converts the value of a variable, as decoded by encoding/json, into a value of the input type t
+func coerceValue(value any, t Type) (any, error) {
+	if nonNull, ok := t.(*NonNull); ok {
+		if value == nil {
+			return nil, fmt.Errorf("expected non-nullable type %q not to be null", t)
+		}
+		return coerceValue(value, nonNull.OfType)
+	}
+	if value == nil {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		items, ok := value.([]any)
+		if !ok {
+			// a single value is coerced into a list of one
+			item, err := coerceValue(value, t.OfType)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		}
+		result := make([]any, 0, len(items))
+		for i, item := range items {
+			itemValue, err := coerceValue(item, t.OfType)
+			if err != nil {
+				return nil, fmt.Errorf("at index %d: %w", i, err)
+			}
+			result = append(result, itemValue)
+		}
+		return result, nil
+	case *InputObject:
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("expected type %q to be an object", t.Name)
+		}
+		for key := range object {
+			if t.Field(key) == nil {
+				return nil, fmt.Errorf("field %q is not defined by type %q", key, t.Name)
+			}
+		}
+		result := map[string]any{}
+		for _, field := range t.Fields {
+			fieldValue, ok := object[field.Name]
+			if !ok {
+				if field.DefaultValue != nil {
+					result[field.Name] = field.DefaultValue
+				} else if isNonNull(field.Type) {
+					return nil, fmt.Errorf("field %q of required type %q was not provided", field.Name, field.Type)
+				}
+				continue
+			}
+			coerced, err := coerceValue(fieldValue, field.Type)
+			if err != nil {
+				return nil, fmt.Errorf("at field %q: %w", field.Name, err)
+			}
+			result[field.Name] = coerced
+		}
+		return result, nil
+	case *Enum:
+		name, ok := value.(string)
+		if !ok || !t.hasValue(name) {
+			return nil, fmt.Errorf("value %s does not exist in %q enum", inspect(value), t.Name)
+		}
+		return name, nil
+	case *Scalar:
+		return t.ParseValue(value)
+	}
+	return nil, fmt.Errorf("type %q is not an input type", t)
+}
+
+// valueFromAST converts a literal into a value of the input type t, replacing variables with their coerced values
+func valueFromAST(node Value, t Type, variables map[string]any) (any, error) {
+	if variable, ok := node.(*Variable); ok {
+		value := variables[variable.Name]
+		if value == nil && isNonNull(t) {
+			return nil, fmt.Errorf("variable \"$%s\" of non-null type %q must not be null", variable.Name, t)
+		}
+		return value, nil
+	}
+
+	if nonNull, ok := t.(*NonNull); ok {
+		if _, isNull := node.(*NullValue); isNull {
+			return nil, fmt.Errorf("expected value of type %q, found null", t)
+		}
+		return valueFromAST(node, nonNull.OfType, variables)
+	}
+	if _, isNull := node.(*NullValue); isNull {
+		return nil, nil
+	}
+
+	switch t := t.(type) {
+	case *List:
+		list, ok := node.(*ListValue)
+		if !ok {
+			item, err := valueFromAST(node, t.OfType, variables)
+			if err != nil {
+				return nil, err
+			}
+			return []any{item}, nil
+		}
+		result := make([]any, 0, len(list.Values))
+		for _, item := range list.Values {
+			itemValue, err := valueFromAST(item, t.OfType, variables)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, itemValue)
+		}
+		return result, nil
+	case *InputObject:
+		object, ok := node.(*ObjectValue)
+		if !ok {
+			return nil, fmt.Errorf("expected value of type %q, found %s", t.Name, printLiteral(node))
+		}
+		fields := map[string]Value{}
+		for _, field := range object.Fields {
+			if t.Field(field.Name) == nil {
+				return nil, fmt.Errorf("field %q is not defined by type %q", field.Name, t.Name)
+			}
+			fields[field.Name] = field.Value
+		}
+		result := map[string]any{}
+		for _, field := range t.Fields {
+			fieldNode, ok := fields[field.Name]
+			if variable, isVariable := fieldNode.(*Variable); isVariable {
+				_, ok = variables[variable.Name]
+			}
+			if !ok {
+				if field.DefaultValue != nil {
+					result[field.Name] = field.DefaultValue
+				} else if isNonNull(field.Type) {
+					return nil, fmt.Errorf("field %q of required type %q was not provided", field.Name, field.Type)
+				}
+				continue
+			}
+			fieldValue, err := valueFromAST(fieldNode, field.Type, variables)
+			if err != nil {
+				return nil, err
+			}
+			result[field.Name] = fieldValue
+		}
+		return result, nil
+	case *Enum:
+		enum, ok := node.(*EnumValue)
+		if !ok || !t.hasValue(enum.Name) {
+			return nil, fmt.Errorf("value %s does not exist in %q enum", printLiteral(node), t.Name)
+		}
+		return enum.Name, nil
+	case *Scalar:
+		return t.ParseLiteral(node, variables)
+	}
+	return nil, fmt.Errorf("type %q is not an input type", t)
+}
+
+// printLiteral formats a literal as it would be written in a request
+func printLiteral(node Value) string {
+	switch v := node.(type) {
+	case *Variable:
+		return "$" + v.Name
+	case *IntValue:
+		return v.Raw
+	case *FloatValue:
+		return v.Raw
+	case *StringValue:
+		return strconv.Quote(v.Value)
+	case *BooleanValue:
+		return strconv.FormatBool(v.Value)
+	case *NullValue:
+		return "null"
+	case *EnumValue:
+		return v.Name
+	case *ListValue:
+		items := []string{}
+		for _, item := range v.Values {
+			items = append(items, printLiteral(item))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case *ObjectValue:
+		fields := []string{}
+		for _, field := range v.Fields {
+			fields = append(fields, field.Name+": "+printLiteral(field.Value))
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	}
+	return ""
+}
+
+// printValue formats an input value of type t as a literal, as the default values of introspection are
+func printValue(value any, t Type) string {
+	if value == nil {
+		return "null"
+	}
+	switch t := t.(type) {
+	case *NonNull:
+		return printValue(value, t.OfType)
+	case *List:
+		items, ok := value.([]any)
+		if !ok {
+			return printValue(value, t.OfType)
+		}
+		printed := []string{}
+		for _, item := range items {
+			printed = append(printed, printValue(item, t.OfType))
+		}
+		return "[" + strings.Join(printed, ", ") + "]"
+	case *InputObject:
+		object, _ := value.(map[string]any)
+		fields := []string{}
+		for _, field := range t.Fields {
+			if fieldValue, ok := object[field.Name]; ok {
+				fields = append(fields, field.Name+": "+printValue(fieldValue, field.Type))
+			}
+		}
+		return "{" + strings.Join(fields, ", ") + "}"
+	case *Enum:
+		return fmt.Sprint(value)
+	}
+	switch v := value.(type) {
+	case string:
+		return strconv.Quote(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
@@ -1,19 +1,24 @@
 package jsonfile
 
 import (
-	"github.com/aws/aws-sdk-go-v2/service/s3"
 	"github.com/pl3lee/restjson/internal/database"
+	"github.com/pl3lee/restjson/internal/storage"
 	"github.com/redis/go-redis/v9"
 )
 
 type JsonConfig struct {
-	Db            *database.Queries
-	BaseURL       string
-	ClientURL     string
-	S3Bucket      string
-	S3Region      string
-	S3Client      *s3.Client
-	Rdb           *redis.Client
+	Db        *database.Queries
+	BaseURL   string
+	ClientURL string
+	Store     storage.Store
+	// Rdb caches contents and holds the write locks of json files, any redis client works including a cluster
+	Rdb           redis.Cmdable
 	FreeFileLimit int
 	ProFileLimit  int
+	// maximum size in bytes of each json file
+	FreeMaxFileSize int64
+	ProMaxFileSize  int64
+	// number of revisions kept for each json file, older ones are deleted unless a snapshot points at them.
+	// 0 keeps every revision.
+	MaxRevisions int
 }
@@ -0,0 +1,83 @@
+package jsonfile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/pl3lee/restjson/internal/utils"
+)
+
+// computeETag returns a strong etag for the json encoding of value.
+// Objects are encoded with sorted keys, so equal values always have the same etag.
+func computeETag(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("computeETag: error marshalling value: %w", err)
+	}
+	hash := sha256.Sum256(data)
+	return fmt.Sprintf(`"%s"`, hex.EncodeToString(hash[:16])), nil
+}
+
+// etagMatches reports whether the If-Match or If-None-Match header value contains etag.
+// Only strong comparison is used, so weak etags never match.
+func etagMatches(header string, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// respondWithETag responds with payload and its etag, or with 304 Not Modified
+// when the etag matches the If-None-Match header sent by the client
+func respondWithETag(w http.ResponseWriter, r *http.Request, code int, payload any) {
+	etag, err := computeETag(payload)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error computing etag", err)
+		return
+	}
+	w.Header().Set("ETag", etag)
+
+	if ifNoneMatch := r.Header.Get("If-None-Match"); ifNoneMatch != "" && etagMatches(ifNoneMatch, etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	utils.RespondWithJSON(w, code, payload)
+}
+
+// checkIfMatch compares the If-Match header with the etag of the current value of the resource being written.
+// It responds with 412 Precondition Failed and returns false when they do not match.
+func checkIfMatch(w http.ResponseWriter, r *http.Request, current any) bool {
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return true
+	}
+	etag, err := computeETag(current)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error computing etag", err)
+		return false
+	}
+	if !etagMatches(ifMatch, etag) {
+		w.Header().Set("ETag", etag)
+		utils.RespondWithErrorDetails(w, http.StatusPreconditionFailed, "resource has been modified", map[string]string{
+			"etag": etag,
+		}, nil)
+		return false
+	}
+	return true
+}
+
+// setETag sets the etag of the value after it has been written
+func setETag(w http.ResponseWriter, value any) {
+	etag, err := computeETag(value)
+	if err != nil {
+		return
+	}
+	w.Header().Set("ETag", etag)
+}
@@ -0,0 +1,350 @@
+package jsonfile
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pl3lee/restjson/internal/database"
+)
+
+// fakeDb is an in memory database for handler tests. It answers the queries of the database package
+// by the name sqlc gives them, so only the queries used by the tested handlers are supported.
+type fakeDb struct {
+	mu        sync.Mutex
+	users     map[uuid.UUID]database.User
+	files     map[uuid.UUID]database.JsonFile
+	revisions []database.JsonRevision
+	schemas   []database.JsonSchema
+}
+
+func newFakeDb() *fakeDb {
+	return &fakeDb{
+		users: map[uuid.UUID]database.User{},
+		files: map[uuid.UUID]database.JsonFile{},
+	}
+}
+
+func (db *fakeDb) queries() *database.Queries {
+	return database.New(sql.OpenDB(fakeConnector{db: db}))
+}
+
+func (db *fakeDb) addUser(user database.User) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.users[user.ID] = user
+}
+
+func (db *fakeDb) addFile(file database.JsonFile) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.files[file.ID] = file
+}
+
+func (db *fakeDb) file(id uuid.UUID) database.JsonFile {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	return db.files[id]
+}
+
+func (db *fakeDb) fileRevisions(fileId uuid.UUID) []database.JsonRevision {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	revisions := []database.JsonRevision{}
+	for _, revision := range db.revisions {
+		if revision.JsonFileID == fileId {
+			revisions = append(revisions, revision)
+		}
+	}
+	return revisions
+}
+
+// query runs the query called name, and returns the rows it selects as database models
+func (db *fakeDb) query(name string, args []driver.Value) ([]any, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	now := time.Now()
+	switch name {
+	case "GetUserById":
+		if user, ok := db.users[argUUID(args[0])]; ok {
+			return []any{user}, nil
+		}
+		return nil, nil
+	case "GetJsonFile":
+		if file, ok := db.files[argUUID(args[0])]; ok {
+			return []any{file}, nil
+		}
+		return nil, nil
+	case "GetJsonFiles":
+		files := []any{}
+		for _, file := range db.files {
+			if file.UserID == argUUID(args[0]) {
+				files = append(files, file)
+			}
+		}
+		slices.SortFunc(files, func(a, b any) int {
+			return strings.Compare(a.(database.JsonFile).FileName, b.(database.JsonFile).FileName)
+		})
+		return files, nil
+	case "RenameJsonFile", "UpdateJsonFileSettings", "UpdateJsonFileRelationships":
+		file, ok := db.files[argUUID(args[0])]
+		if !ok {
+			return nil, nil
+		}
+		switch name {
+		case "RenameJsonFile":
+			file.FileName = args[1].(string)
+		case "UpdateJsonFileSettings":
+			file.IdStrategy = args[1].(string)
+			file.IdField = args[2].(string)
+			file.ResourceIdFields = slices.Clone(args[3].([]byte))
+			file.ForeignKeySuffix = args[4].(string)
+		case "UpdateJsonFileRelationships":
+			file.Relationships = slices.Clone(args[1].([]byte))
+		}
+		file.UpdatedAt = now
+		db.files[file.ID] = file
+		return []any{file}, nil
+	case "CreateJsonRevision":
+		revision := database.JsonRevision{
+			ID:         uuid.New(),
+			CreatedAt:  now,
+			JsonFileID: argUUID(args[0]),
+			Revision:   1,
+			UserID:     argUUID(args[1]),
+			Source:     args[2].(string),
+			ApiKeyName: args[4].(string),
+			Size:       args[5].(int64),
+			Checksum:   args[6].(string),
+		}
+		if args[3] != nil {
+			revision.ApiKeyID = uuid.NullUUID{UUID: argUUID(args[3]), Valid: true}
+		}
+		for _, existing := range db.revisions {
+			if existing.JsonFileID == revision.JsonFileID {
+				revision.Revision = max(revision.Revision, existing.Revision+1)
+			}
+		}
+		db.revisions = append(db.revisions, revision)
+		return []any{revision}, nil
+	case "DeleteJsonRevision":
+		db.revisions = slices.DeleteFunc(db.revisions, func(revision database.JsonRevision) bool {
+			return revision.JsonFileID == argUUID(args[0]) && int64(revision.Revision) == args[1].(int64)
+		})
+		return nil, nil
+	case "DeleteOldJsonRevisions":
+		// snapshots are not kept, so no revision is protected by one
+		latest := int32(0)
+		for _, revision := range db.revisions {
+			if revision.JsonFileID == argUUID(args[0]) {
+				latest = max(latest, revision.Revision)
+			}
+		}
+		deleted := []any{}
+		db.revisions = slices.DeleteFunc(db.revisions, func(revision database.JsonRevision) bool {
+			if revision.JsonFileID != argUUID(args[0]) || int64(revision.Revision) > int64(latest)-args[1].(int64) {
+				return false
+			}
+			deleted = append(deleted, struct{ Revision int32 }{revision.Revision})
+			return true
+		})
+		return deleted, nil
+	case "GetJsonRevision":
+		for _, revision := range db.revisions {
+			if revision.JsonFileID == argUUID(args[0]) && int64(revision.Revision) == args[1].(int64) {
+				return []any{revision}, nil
+			}
+		}
+		return nil, nil
+	case "GetJsonRevisions", "GetLatestJsonRevision":
+		revisions := []any{}
+		for i := len(db.revisions) - 1; i >= 0; i-- {
+			if db.revisions[i].JsonFileID == argUUID(args[0]) {
+				revisions = append(revisions, db.revisions[i])
+			}
+		}
+		if name == "GetLatestJsonRevision" && len(revisions) > 1 {
+			revisions = revisions[:1]
+		}
+		return revisions, nil
+	case "GetLatestJsonRevisionSizes":
+		latest := map[uuid.UUID]database.JsonRevision{}
+		for _, revision := range db.revisions {
+			if db.files[revision.JsonFileID].UserID == argUUID(args[0]) && revision.Revision > latest[revision.JsonFileID].Revision {
+				latest[revision.JsonFileID] = revision
+			}
+		}
+		sizes := []any{}
+		for fileId, revision := range latest {
+			sizes = append(sizes, database.GetLatestJsonRevisionSizesRow{JsonFileID: fileId, Size: revision.Size})
+		}
+		return sizes, nil
+	case "GetJsonSchemas":
+		schemas := []any{}
+		for _, schema := range db.schemas {
+			if schema.JsonFileID == argUUID(args[0]) {
+				schemas = append(schemas, schema)
+			}
+		}
+		return schemas, nil
+	case "UpsertJsonSchema":
+		schema := database.JsonSchema{
+			ID:         uuid.New(),
+			CreatedAt:  now,
+			UpdatedAt:  now,
+			JsonFileID: argUUID(args[0]),
+			Resource:   args[1].(string),
+			Schema:     slices.Clone(args[2].([]byte)),
+		}
+		db.schemas = slices.DeleteFunc(db.schemas, func(existing database.JsonSchema) bool {
+			return existing.JsonFileID == schema.JsonFileID && existing.Resource == schema.Resource
+		})
+		db.schemas = append(db.schemas, schema)
+		return []any{schema}, nil
+	}
+	return nil, fmt.Errorf("fakeDb: query %s is not supported", name)
+}
+
+func argUUID(value driver.Value) uuid.UUID {
+	switch v := value.(type) {
+	case string:
+		return uuid.MustParse(v)
+	case []byte:
+		return uuid.MustParse(string(v))
+	}
+	panic(fmt.Sprintf("fakeDb: %v is not a uuid", value))
+}
+
+// queryName reads the name of a query generated by sqlc, from its "-- name: GetJsonFile :one" header
+func queryName(query string) string {
+	header, _, _ := strings.Cut(query, "\n")
+	fields := strings.Fields(header)
+	if len(fields) < 3 || fields[0] != "--" || fields[1] != "name:" {
+		return query
+	}
+	return fields[2]
+}
+
+// rowValues turns a database model into the values of its columns, which are in the order of its fields
+func rowValues(model any) []driver.Value {
+	v := reflect.ValueOf(model)
+	values := make([]driver.Value, v.NumField())
+	for i := range values {
+		switch field := v.Field(i).Interface().(type) {
+		case uuid.UUID:
+			values[i] = field.String()
+		case uuid.NullUUID:
+			if field.Valid {
+				values[i] = field.UUID.String()
+			}
+		case json.RawMessage:
+			values[i] = []byte(slices.Clone(field))
+		case int32:
+			values[i] = int64(field)
+		default:
+			values[i] = field
+		}
+	}
+	return values
+}
+
+type fakeConnector struct {
+	db *fakeDb
+}
+
+func (c fakeConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	return fakeConn{db: c.db}, nil
+}
+
+func (c fakeConnector) Driver() driver.Driver {
+	return fakeDriver{}
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("fakeDb: open the database with sql.OpenDB")
+}
+
+type fakeConn struct {
+	db *fakeDb
+}
+
+func (c fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("fakeDb: prepared statements are not supported")
+}
+
+func (c fakeConn) Close() error {
+	return nil
+}
+
+func (c fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("fakeDb: transactions are not supported")
+}
+
+func (c fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	models, err := c.db.query(queryName(query), namedValues(args))
+	if err != nil {
+		return nil, err
+	}
+	rows := &fakeRows{}
+	for _, model := range models {
+		rows.rows = append(rows.rows, rowValues(model))
+	}
+	return rows, nil
+}
+
+func (c fakeConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	if _, err := c.db.query(queryName(query), namedValues(args)); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func namedValues(args []driver.NamedValue) []driver.Value {
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	return values
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+	next int
+}
+
+func (r *fakeRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	columns := make([]string, len(r.rows[0]))
+	for i := range columns {
+		columns[i] = fmt.Sprintf("column%d", i)
+	}
+	return columns
+}
+
+func (r *fakeRows) Close() error {
+	return nil
+}
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.rows) {
+		return io.EOF
+	}
+	copy(dest, r.rows[r.next])
+	r.next++
+	return nil
+}
@@ -0,0 +1,688 @@
+package jsonfile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pl3lee/restjson/internal/auth"
+	"github.com/pl3lee/restjson/internal/database"
+	"github.com/pl3lee/restjson/internal/graphql"
+	"github.com/pl3lee/restjson/internal/jsonpatch"
+	"github.com/pl3lee/restjson/internal/utils"
+)
+
+// HandlerGraphQL executes a GraphQL request against a schema derived from the resources of the file.
+// Arrays of objects get list and item queries and create, update and delete mutations, which are saved
+// like the writes of the REST api, so they are validated against the schemas and relationships of the file.
+func (cfg *JsonConfig) HandlerGraphQL(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+	userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
+	fileContents, ok := r.Context().Value(FileContentContextKey).(map[string]any)
+	if !ok {
+		utils.RespondWithError(w, http.StatusBadRequest, "json file is not a map", nil)
+		return
+	}
+
+	var request graphql.Request
+	defer r.Body.Close()
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if request.Query == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "query is required", nil)
+		return
+	}
+
+	file := &graphqlFile{
+		cfg:          cfg,
+		userId:       userId,
+		fileMetadata: fileMetadata,
+		contents:     fileContents,
+	}
+	schema, err := file.schema()
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error building graphql schema", err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, schema.Execute(r.Context(), request))
+}
+
+// graphqlFile is the json file a GraphQL request runs against.
+// Mutations replace contents once they are saved, so the fields after them see their changes.
+type graphqlFile struct {
+	cfg          *JsonConfig
+	userId       uuid.UUID
+	fileMetadata database.JsonFile
+	contents     map[string]any
+}
+
+// graphqlCollection is an array of objects exposed as an object type
+type graphqlCollection struct {
+	resource string
+	idField  string
+	object   *graphql.Object
+	input    *graphql.InputObject
+	filter   *graphql.InputObject
+	// filterParams maps the fields of the filter to the query parameters of the REST api
+	filterParams map[string]graphqlFilterParam
+	// numericIds are the ID fields whose values are numbers in the file
+	numericIds map[string]bool
+}
+
+type graphqlFilterParam struct {
+	param string
+	// multiple fields take a list of values, any of which can match
+	multiple bool
+}
+
+// graphqlError is a resolver error with details like the ones of the REST api
+type graphqlError struct {
+	message string
+	details any
+}
+
+func (e *graphqlError) Error() string {
+	return e.message
+}
+
+func (e *graphqlError) Extensions() map[string]any {
+	return map[string]any{"details": e.details}
+}
+
+type graphqlBuilder struct {
+	file        *graphqlFile
+	names       map[string]bool
+	collections map[string]*graphqlCollection
+}
+
+// schema derives the types of the file from its contents, like the generated types, and relationship fields
+// from foreign keys, like the _embed and _expand query parameters
+func (f *graphqlFile) schema() (*graphql.Schema, error) {
+	b := &graphqlBuilder{
+		file:        f,
+		names:       map[string]bool{},
+		collections: map[string]*graphqlCollection{},
+	}
+	for _, name := range []string{"Query", "Mutation", "String", "Int", "Float", "Boolean", "ID", "JSON"} {
+		b.names[name] = true
+	}
+
+	keys := []string{}
+	for _, key := range routeKeys(f.contents) {
+		if graphql.IsValidName(key) {
+			keys = append(keys, key)
+		}
+	}
+
+	// every collection gets its type before any fields, so that relationship fields can refer to any of them
+	for _, key := range keys {
+		items, ok := f.contents[key].([]any)
+		if !ok || !hasIds(items) {
+			continue
+		}
+		b.collections[key] = &graphqlCollection{
+			resource:     key,
+			idField:      resourceIdField(f.fileMetadata, key),
+			object:       &graphql.Object{Name: b.typeName(itemTypeName(key))},
+			filterParams: map[string]graphqlFilterParam{},
+			numericIds:   map[string]bool{},
+		}
+	}
+	relations := detectRelations(f.fileMetadata, f.contents)
+	for _, key := range keys {
+		if c, ok := b.collections[key]; ok {
+			b.defineCollection(c, relations)
+		}
+	}
+	for _, relation := range relations {
+		b.defineRelation(relation)
+	}
+
+	query := &graphql.Object{Name: "Query"}
+	mutation := &graphql.Object{Name: "Mutation"}
+	for _, key := range keys {
+		var t graphql.Type
+		if c, ok := b.collections[key]; ok {
+			t = graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(c.object)))
+		} else {
+			t = b.outputType(inferSchema(f.contents[key]), pascalCase(key))
+		}
+		query.Fields = append(query.Fields, &graphql.Field{
+			Name: key,
+			Type: t,
+			Args: b.listArgs(b.collections[key]),
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				if c, ok := b.collections[key]; ok {
+					items, _ := f.contents[key].([]any)
+					return c.selectItems(items, p.Args)
+				}
+				return f.contents[key], nil
+			},
+		})
+	}
+	for _, key := range keys {
+		if c, ok := b.collections[key]; ok {
+			query.Fields = append(query.Fields, b.collectionQueries(c, query)...)
+			mutation.Fields = append(mutation.Fields, b.collectionMutations(c)...)
+		}
+	}
+
+	if len(mutation.Fields) == 0 {
+		return graphql.NewSchema(query, nil)
+	}
+	return graphql.NewSchema(query, mutation)
+}
+
+// itemTypeName names the items of a resource, posts becomes Post, like the generated types
+func itemTypeName(resource string) string {
+	name := pascalCase(singularize(resource))
+	if singularize(resource) == resource {
+		name += "Item"
+	}
+	return name
+}
+
+// typeName makes name a unique GraphQL name, which is ascii only
+func (b *graphqlBuilder) typeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 128 && (r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')) {
+			return r
+		}
+		return -1
+	}, name)
+	if !graphql.IsValidName(name) {
+		name = "T" + strings.TrimLeft(name, "_")
+	}
+	return uniqueSchemaName(b.names, name)
+}
+
+// nestedTypeName names the object type of a property, after the singular of the property when it is an array
+func nestedTypeName(parent string, property string, schema map[string]any) string {
+	if schemaTypes(schema)["array"] {
+		return parent + pascalCase(singularize(property))
+	}
+	return parent + pascalCase(property)
+}
+
+// sortedProperties returns the properties of an object schema with a valid GraphQL name, sorted with idField first
+func sortedProperties(schema map[string]any, idField string) ([]string, map[string]any) {
+	properties, _ := schema["properties"].(map[string]any)
+	names := []string{}
+	for _, name := range slices.Sorted(maps.Keys(properties)) {
+		if !graphql.IsValidName(name) {
+			continue
+		}
+		if name == idField {
+			names = append([]string{name}, names...)
+			continue
+		}
+		names = append(names, name)
+	}
+	return names, properties
+}
+
+// outputType converts an inferred schema into a GraphQL type, values of mixed types are JSON
+func (b *graphqlBuilder) outputType(schema map[string]any, name string) graphql.Type {
+	types := schemaTypes(schema)
+	delete(types, "null")
+	if len(types) != 1 {
+		return graphql.JSON
+	}
+
+	switch {
+	case types["string"]:
+		return graphql.String
+	case types["integer"]:
+		return graphql.Int
+	case types["number"]:
+		return graphql.Float
+	case types["boolean"]:
+		return graphql.Boolean
+	case types["array"]:
+		items, ok := schema["items"].(map[string]any)
+		if !ok {
+			return graphql.NewList(graphql.JSON)
+		}
+		return graphql.NewList(b.outputType(items, name))
+	}
+
+	propertyNames, properties := sortedProperties(schema, "")
+	if len(propertyNames) == 0 {
+		return graphql.JSON
+	}
+	object := &graphql.Object{Name: b.typeName(name)}
+	for _, property := range propertyNames {
+		propertySchema, _ := properties[property].(map[string]any)
+		object.Fields = append(object.Fields, &graphql.Field{
+			Name: property,
+			Type: b.outputType(propertySchema, nestedTypeName(object.Name, property, propertySchema)),
+		})
+	}
+	return object
+}
+
+// scalarType returns the name of the single scalar type of schema, or an empty string
+func scalarType(schema map[string]any) string {
+	types := schemaTypes(schema)
+	delete(types, "null")
+	if len(types) != 1 {
+		return ""
+	}
+	for _, t := range []string{"string", "integer", "number", "boolean"} {
+		if types[t] {
+			return t
+		}
+	}
+	return ""
+}
+
+func graphqlScalar(scalar string) graphql.Type {
+	switch scalar {
+	case "string":
+		return graphql.String
+	case "integer":
+		return graphql.Int
+	case "number":
+		return graphql.Float
+	case "boolean":
+		return graphql.Boolean
+	}
+	return graphql.JSON
+}
+
+// defineCollection adds the fields of the items of a collection to its object, input and filter types.
+// Ids and foreign keys are IDs, even when they are numbers in the file.
+func (b *graphqlBuilder) defineCollection(c *graphqlCollection, relations []relation) {
+	items, _ := b.file.contents[c.resource].([]any)
+	schema := itemsSchema(items)
+	required := map[string]bool{}
+	if requiredList, ok := schema["required"].([]any); ok {
+		for _, property := range requiredList {
+			if property, ok := property.(string); ok {
+				required[property] = true
+			}
+		}
+	}
+	foreignKeys := map[string]bool{}
+	for _, relation := range relations {
+		if relation.child == c.resource {
+			foreignKeys[relation.foreignKey] = true
+		}
+	}
+
+	c.input = &graphql.InputObject{
+		Name:        b.typeName(c.object.Name + "Input"),
+		Description: fmt.Sprintf("The fields of an item of %s.", c.resource),
+	}
+	c.filter = &graphql.InputObject{
+		Name:        b.typeName(c.object.Name + "Filter"),
+		Description: fmt.Sprintf("Filters items of %s like the query parameters of the REST api, every filter must match.", c.resource),
+	}
+	propertyNames, properties := sortedProperties(schema, c.idField)
+	for _, property := range propertyNames {
+		propertySchema, _ := properties[property].(map[string]any)
+		scalar := scalarType(propertySchema)
+
+		var outputType, inputType graphql.Type
+		if property == c.idField || foreignKeys[property] {
+			outputType, inputType = graphql.ID, graphql.ID
+			if scalar == "integer" || scalar == "number" {
+				c.numericIds[property] = true
+			}
+			scalar = "id"
+		} else {
+			outputType = b.outputType(propertySchema, nestedTypeName(c.object.Name, property, propertySchema))
+			inputType = graphqlScalar(scalar)
+		}
+		if property == c.idField && required[property] {
+			outputType = graphql.NewNonNull(outputType)
+		}
+
+		c.object.Fields = append(c.object.Fields, &graphql.Field{Name: property, Type: outputType})
+		c.input.Fields = append(c.input.Fields, &graphql.InputValue{Name: property, Type: inputType})
+		c.addFilters(property, propertySchema, scalar, inputType)
+	}
+}
+
+// addFilters adds the filters of a property, which are the operators of the REST api for its type
+// and an _in filter matching any of several values, like a repeated query parameter
+func (c *graphqlCollection) addFilters(property string, schema map[string]any, scalar string, t graphql.Type) {
+	add := func(name string, t graphql.Type, param string, multiple bool) {
+		if c.filter.Field(name) != nil {
+			return
+		}
+		c.filter.Fields = append(c.filter.Fields, &graphql.InputValue{Name: name, Type: t})
+		c.filterParams[name] = graphqlFilterParam{param: param, multiple: multiple}
+	}
+
+	if scalar == "" {
+		if items, ok := schema["items"].(map[string]any); ok && schemaTypes(schema)["array"] && scalarType(items) != "" {
+			add(property+"_contains", graphql.String, property+"_contains", false)
+		}
+		return
+	}
+	add(property, t, property, false)
+	add(property+"_in", graphql.NewList(graphql.NewNonNull(t)), property, true)
+	add(property+"_ne", t, property+"_ne", false)
+	if scalar != "boolean" {
+		add(property+"_gte", t, property+"_gte", false)
+		add(property+"_lte", t, property+"_lte", false)
+	}
+	if scalar == "string" {
+		add(property+"_like", graphql.String, property+"_like", false)
+	}
+}
+
+// listArgs are the arguments of lists of a collection, named like the query parameters of the REST api
+func (b *graphqlBuilder) listArgs(c *graphqlCollection) []*graphql.InputValue {
+	if c == nil {
+		return nil
+	}
+	args := []*graphql.InputValue{}
+	if len(c.filter.Fields) > 0 {
+		args = append(args, &graphql.InputValue{Name: "filter", Type: c.filter})
+	}
+	return append(args,
+		&graphql.InputValue{Name: "sort", Description: "Fields to sort by, separated by commas.", Type: graphql.String},
+		&graphql.InputValue{Name: "order", Description: "asc or desc for each sort field, separated by commas.", Type: graphql.String},
+		&graphql.InputValue{Name: "page", Type: graphql.Int},
+		&graphql.InputValue{Name: "limit", Type: graphql.Int},
+		&graphql.InputValue{Name: "start", Type: graphql.Int},
+		&graphql.InputValue{Name: "end", Type: graphql.Int},
+	)
+}
+
+// selectItems applies the arguments of a list like the query parameters of the REST api
+func (c *graphqlCollection) selectItems(items []any, args map[string]any) (any, error) {
+	query := url.Values{}
+	if filter, ok := args["filter"].(map[string]any); ok {
+		for name, value := range filter {
+			param := c.filterParams[name]
+			if value == nil {
+				continue
+			}
+			if param.multiple {
+				for _, item := range value.([]any) {
+					query.Add(param.param, stringifyValue(item))
+				}
+				continue
+			}
+			query.Add(param.param, stringifyValue(value))
+		}
+	}
+	for _, arg := range []string{"sort", "order"} {
+		if value, ok := args[arg].(string); ok {
+			query.Set("_"+arg, value)
+		}
+	}
+	for _, arg := range []string{"page", "limit", "start", "end"} {
+		if value, ok := args[arg].(int); ok {
+			query.Set("_"+arg, strconv.Itoa(value))
+		}
+	}
+
+	result, _, _, err := selectItems(query, items)
+	if err != nil {
+		return nil, &graphqlError{message: "invalid query: " + err.Error(), details: err}
+	}
+	return result, nil
+}
+
+// defineRelation adds a field to the child type for its parent, comment.post,
+// and a field to the parent type for its children, post.comments
+func (b *graphqlBuilder) defineRelation(relation relation) {
+	parent, child := b.collections[relation.parent], b.collections[relation.child]
+	if parent == nil || child == nil {
+		return
+	}
+	f := b.file
+
+	if name := singularize(relation.parent); graphql.IsValidName(name) && child.object.Field(name) == nil {
+		child.object.Fields = append(child.object.Fields, &graphql.Field{
+			Name:        name,
+			Description: fmt.Sprintf("The item of %s referenced by %s.", relation.parent, relation.foreignKey),
+			Type:        parent.object,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				item, _ := p.Source.(map[string]any)
+				reference, ok := item[relation.foreignKey]
+				if !ok || reference == nil {
+					return nil, nil
+				}
+				items, _ := f.contents[relation.parent].([]any)
+				if index := findItemIndex(items, parent.idField, stringifyValue(reference)); index != -1 {
+					return items[index], nil
+				}
+				return nil, nil
+			},
+		})
+	}
+
+	if parent.object.Field(relation.child) == nil {
+		parent.object.Fields = append(parent.object.Fields, &graphql.Field{
+			Name:        relation.child,
+			Description: fmt.Sprintf("The items of %s whose %s references this item.", relation.child, relation.foreignKey),
+			Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(child.object))),
+			Args:        b.listArgs(child),
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				item, _ := p.Source.(map[string]any)
+				route := childRoute{relation: relation, parentId: item[parent.idField]}
+				children := []any{}
+				items, _ := f.contents[relation.child].([]any)
+				for _, child := range items {
+					if route.parentId != nil && route.belongsTo(child) {
+						children = append(children, child)
+					}
+				}
+				return child.selectItems(children, p.Args)
+			},
+		})
+	}
+}
+
+// collectionQueries are the queries of a single item and of the number of items of a collection
+func (b *graphqlBuilder) collectionQueries(c *graphqlCollection, query *graphql.Object) []*graphql.Field {
+	f := b.file
+	fields := []*graphql.Field{}
+
+	itemName := singularize(c.resource)
+	if itemName == c.resource {
+		itemName += "Item"
+	}
+	if graphql.IsValidName(itemName) && query.Field(itemName) == nil {
+		fields = append(fields, &graphql.Field{
+			Name:        itemName,
+			Description: fmt.Sprintf("The item of %s with the given %s.", c.resource, c.idField),
+			Type:        c.object,
+			Args:        []*graphql.InputValue{{Name: "id", Type: graphql.NewNonNull(graphql.ID)}},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				items, _ := f.contents[c.resource].([]any)
+				if index := findItemIndex(items, c.idField, p.Args["id"].(string)); index != -1 {
+					return items[index], nil
+				}
+				return nil, nil
+			},
+		})
+	}
+
+	if countName := c.resource + "Count"; query.Field(countName) == nil {
+		args := []*graphql.InputValue{}
+		if len(c.filter.Fields) > 0 {
+			args = append(args, &graphql.InputValue{Name: "filter", Type: c.filter})
+		}
+		fields = append(fields, &graphql.Field{
+			Name:        countName,
+			Description: fmt.Sprintf("The number of items of %s matching the filter.", c.resource),
+			Type:        graphql.NewNonNull(graphql.Int),
+			Args:        args,
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				items, _ := f.contents[c.resource].([]any)
+				result, err := c.selectItems(items, p.Args)
+				if err != nil {
+					return nil, err
+				}
+				return len(result.([]any)), nil
+			},
+		})
+	}
+	return fields
+}
+
+// collectionMutations create, update and delete items of a collection.
+// Their results are nullable, so that a failed mutation does not stop the ones after it.
+func (b *graphqlBuilder) collectionMutations(c *graphqlCollection) []*graphql.Field {
+	if len(c.input.Fields) == 0 {
+		return nil
+	}
+	f := b.file
+	idArg := &graphql.InputValue{Name: "id", Type: graphql.NewNonNull(graphql.ID)}
+	inputArg := &graphql.InputValue{Name: "input", Type: graphql.NewNonNull(c.input)}
+
+	return []*graphql.Field{
+		{
+			Name:        "create" + c.object.Name,
+			Description: fmt.Sprintf("Adds an item to %s, generating its %s if it is not given.", c.resource, c.idField),
+			Type:        c.object,
+			Args:        []*graphql.InputValue{inputArg},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return f.createItem(p.Context, c, p.Args["input"].(map[string]any))
+			},
+		},
+		{
+			Name:        "update" + c.object.Name,
+			Description: "Merges the input into the item, fields set to null are removed.",
+			Type:        c.object,
+			Args:        []*graphql.InputValue{idArg, inputArg},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return f.updateItem(p.Context, c, p.Args["id"].(string), p.Args["input"].(map[string]any))
+			},
+		},
+		{
+			Name:        "delete" + c.object.Name,
+			Description: "Removes the item and returns it, applying the delete rules of relationships.",
+			Type:        c.object,
+			Args:        []*graphql.InputValue{idArg},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return f.deleteItem(p.Context, c, p.Args["id"].(string))
+			},
+		},
+	}
+}
+
+// inputItem converts the input of a mutation into the values encoding/json would decode,
+// numeric ids given as strings become numbers again
+func (c *graphqlCollection) inputItem(input map[string]any) (map[string]any, error) {
+	item := map[string]any{}
+	for key, value := range input {
+		if id, ok := value.(string); ok && c.numericIds[key] {
+			if number, err := strconv.ParseFloat(id, 64); err == nil {
+				value = number
+			}
+		}
+		item[key] = value
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, fmt.Errorf("inputItem: error encoding input: %w", err)
+	}
+	result := map[string]any{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("inputItem: error decoding input: %w", err)
+	}
+	return result, nil
+}
+
+func (f *graphqlFile) createItem(ctx context.Context, c *graphqlCollection, input map[string]any) (any, error) {
+	items, _ := f.contents[c.resource].([]any)
+	newItem, err := c.inputItem(input)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := assignId(items, newItem, c.idField, f.fileMetadata.IdStrategy); !ok {
+		return nil, &graphqlError{
+			message: "resource item with given id already exists",
+			details: map[string]any{c.idField: newItem[c.idField]},
+		}
+	}
+
+	updatedContents := maps.Clone(f.contents)
+	updatedContents[c.resource] = append(slices.Clone(items), newItem)
+	if err := f.save(ctx, updatedContents); err != nil {
+		return nil, err
+	}
+	return newItem, nil
+}
+
+func (f *graphqlFile) updateItem(ctx context.Context, c *graphqlCollection, id string, input map[string]any) (any, error) {
+	items, _ := f.contents[c.resource].([]any)
+	index := findItemIndex(items, c.idField, id)
+	if index == -1 {
+		return nil, errors.New("cannot find resource item with given id")
+	}
+	patch, err := c.inputItem(input)
+	if err != nil {
+		return nil, err
+	}
+	updatedItem := jsonpatch.MergePatch(items[index], patch).(map[string]any)
+	if updatedItem[c.idField] == nil {
+		// removing the id would make the item unreachable
+		updatedItem[c.idField] = items[index].(map[string]any)[c.idField]
+	}
+
+	updatedItems := slices.Clone(items)
+	updatedItems[index] = updatedItem
+	updatedContents := maps.Clone(f.contents)
+	updatedContents[c.resource] = updatedItems
+	if err := f.save(ctx, updatedContents); err != nil {
+		return nil, err
+	}
+	return updatedItem, nil
+}
+
+func (f *graphqlFile) deleteItem(ctx context.Context, c *graphqlCollection, id string) (any, error) {
+	items, _ := f.contents[c.resource].([]any)
+	index := findItemIndex(items, c.idField, id)
+	if index == -1 {
+		return nil, errors.New("cannot find resource item with given id")
+	}
+	removed := items[index]
+	updatedContents := maps.Clone(f.contents)
+	updatedContents[c.resource] = slices.Delete(slices.Clone(items), index, index+1)
+	if err := f.save(ctx, updatedContents); err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+// save writes the contents of a mutation, which are seen by the rest of the request once they are saved
+func (f *graphqlFile) save(ctx context.Context, contents map[string]any) error {
+	validationErrors, err := f.cfg.writeFileContents(ctx, f.userId, f.fileMetadata, contents)
+	var sizeErr *fileSizeError
+	if errors.As(err, &sizeErr) {
+		return &graphqlError{message: sizeErr.Error(), details: sizeErr}
+	}
+	var referenceErr *ReferenceError
+	if errors.As(err, &referenceErr) {
+		return &graphqlError{message: referenceErr.summary(), details: referenceErr}
+	}
+	if err != nil {
+		log.Println(err)
+		return errors.New("failed to save updated file contents")
+	}
+	if len(validationErrors) > 0 {
+		return &graphqlError{message: "json does not match schema", details: validationErrors}
+	}
+	f.contents = contents
+	return nil
+}
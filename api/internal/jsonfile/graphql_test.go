@@ -0,0 +1,279 @@
+package jsonfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const graphqlFixture = `{
+	"posts": [{"id": 1, "title": "first", "tags": ["go"]}, {"id": 2, "title": "second", "tags": []}],
+	"comments": [{"id": 1, "postId": 1, "body": "nice"}],
+	"settings": {"theme": "dark"}
+}`
+
+// introspectionQuery is the query GraphiQL sends to load the schema
+const introspectionQuery = `
+query IntrospectionQuery {
+  __schema {
+    description
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types { ...FullType }
+    directives {
+      name
+      description
+      isRepeatable
+      locations
+      args(includeDeprecated: true) { ...InputValue }
+    }
+  }
+}
+
+fragment FullType on __Type {
+  kind
+  name
+  description
+  specifiedByURL
+  fields(includeDeprecated: true) {
+    name
+    description
+    args(includeDeprecated: true) { ...InputValue }
+    type { ...TypeRef }
+    isDeprecated
+    deprecationReason
+  }
+  inputFields(includeDeprecated: true) { ...InputValue }
+  interfaces { ...TypeRef }
+  enumValues(includeDeprecated: true) {
+    name
+    description
+    isDeprecated
+    deprecationReason
+  }
+  possibleTypes { ...TypeRef }
+}
+
+fragment InputValue on __InputValue {
+  name
+  description
+  type { ...TypeRef }
+  defaultValue
+  isDeprecated
+  deprecationReason
+}
+
+fragment TypeRef on __Type {
+  kind
+  name
+  ofType {
+    kind
+    name
+    ofType {
+      kind
+      name
+      ofType {
+        kind
+        name
+        ofType {
+          kind
+          name
+          ofType {
+            kind
+            name
+            ofType {
+              kind
+              name
+              ofType {
+                kind
+                name
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}
+`
+
+type graphqlResponse struct {
+	Data   map[string]any   `json:"data"`
+	Errors []map[string]any `json:"errors"`
+}
+
+func (s *testServer) graphql(t *testing.T, query string, variables map[string]any) graphqlResponse {
+	t.Helper()
+	rec := s.do(t, http.MethodPost, fmt.Sprintf("/public/%s/graphql", s.fileId), map[string]any{"query": query, "variables": variables})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", rec.Code, rec.Body.String())
+	}
+	return decodeBody[graphqlResponse](t, rec)
+}
+
+func TestGraphQLIntrospection(t *testing.T) {
+	s := newTestServer(t, graphqlFixture)
+	response := s.graphql(t, introspectionQuery, nil)
+	if len(response.Errors) > 0 {
+		t.Fatalf("got errors %v", response.Errors)
+	}
+
+	schema := response.Data["__schema"].(map[string]any)
+	if name := schema["mutationType"].(map[string]any)["name"]; name != "Mutation" {
+		t.Errorf("got mutation type %v, want Mutation", name)
+	}
+	types := map[string]map[string]any{}
+	for _, t := range schema["types"].([]any) {
+		t := t.(map[string]any)
+		types[t["name"].(string)] = t
+	}
+	fieldNames := func(typeName string, key string) []string {
+		names := []string{}
+		fields, _ := types[typeName][key].([]any)
+		for _, field := range fields {
+			names = append(names, field.(map[string]any)["name"].(string))
+		}
+		return names
+	}
+
+	wantFields := map[string][]string{
+		"Query":       {"comments", "posts", "settings", "comment", "commentsCount", "post", "postsCount"},
+		"Mutation":    {"createComment", "updateComment", "deleteComment", "createPost", "updatePost", "deletePost"},
+		"Post":        {"id", "tags", "title", "comments"},
+		"Comment":     {"id", "body", "postId", "post"},
+		"Settings":    {"theme"},
+		"__Directive": {"name", "description", "isRepeatable", "locations", "args"},
+	}
+	for typeName, want := range wantFields {
+		if got := fieldNames(typeName, "fields"); !reflect.DeepEqual(got, want) {
+			t.Errorf("got fields %v of %s, want %v", got, typeName, want)
+		}
+	}
+	if got, want := fieldNames("PostFilter", "inputFields"), []string{
+		"id", "id_in", "id_ne", "id_gte", "id_lte",
+		"tags_contains",
+		"title", "title_in", "title_ne", "title_gte", "title_lte", "title_like",
+	}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got filters %v, want %v", got, want)
+	}
+	for _, typeName := range []string{"String", "Int", "Boolean", "ID", "JSON", "__Schema", "__Type", "__TypeKind"} {
+		if types[typeName] == nil {
+			t.Errorf("type %s is missing", typeName)
+		}
+	}
+
+	directives := []string{}
+	for _, directive := range schema["directives"].([]any) {
+		directives = append(directives, directive.(map[string]any)["name"].(string))
+	}
+	if want := []string{"include", "skip", "deprecated", "specifiedBy"}; !reflect.DeepEqual(directives, want) {
+		t.Errorf("got directives %v, want %v", directives, want)
+	}
+}
+
+func TestGraphQLQueries(t *testing.T) {
+	s := newTestServer(t, graphqlFixture)
+	response := s.graphql(t, `query ($title: String) {
+		posts(filter: {title_like: $title}, sort: "id", order: "desc") { id title comments { body } }
+		post(id: "1") { title }
+		comment(id: "1") { post { title } }
+		postsCount(filter: {id_in: ["1", "2"]})
+		settings { theme }
+	}`, map[string]any{"title": "s"})
+	if len(response.Errors) > 0 {
+		t.Fatalf("got errors %v", response.Errors)
+	}
+	want := decodeJson(t, `{
+		"posts": [{"id": "2", "title": "second", "comments": []}, {"id": "1", "title": "first", "comments": [{"body": "nice"}]}],
+		"post": {"title": "first"},
+		"comment": {"post": {"title": "first"}},
+		"postsCount": 2,
+		"settings": {"theme": "dark"}
+	}`)
+	if !reflect.DeepEqual(response.Data, want) {
+		t.Errorf("got data %v, want %v", response.Data, want)
+	}
+}
+
+func TestGraphQLMutations(t *testing.T) {
+	s := newTestServer(t, graphqlFixture)
+	revisions := len(s.db.fileRevisions(s.fileId))
+
+	response := s.graphql(t, `mutation {
+		created: createComment(input: {postId: "2", body: "hello"}) { id postId body post { title } }
+		updated: updateComment(id: "2", input: {body: "edited"}) { id body }
+		deleted: deleteComment(id: "1") { id }
+	}`, nil)
+	if len(response.Errors) > 0 {
+		t.Fatalf("got errors %v", response.Errors)
+	}
+	want := decodeJson(t, `{
+		"created": {"id": "2", "postId": "2", "body": "hello", "post": {"title": "second"}},
+		"updated": {"id": "2", "body": "edited"},
+		"deleted": {"id": "1"}
+	}`)
+	if !reflect.DeepEqual(response.Data, want) {
+		t.Errorf("got data %v, want %v", response.Data, want)
+	}
+	response = s.graphql(t, `{ comments { id body } }`, nil)
+	if want := decodeJson(t, `{"comments": [{"id": "2", "body": "edited"}]}`); !reflect.DeepEqual(response.Data, want) {
+		t.Errorf("got data %v, want %v", response.Data, want)
+	}
+
+	// numeric ids stay numbers in the file, and every mutation is saved as a revision
+	if comments := s.contents(t)["comments"]; !reflect.DeepEqual(comments, []any{
+		map[string]any{"id": float64(2), "postId": float64(2), "body": "edited"},
+	}) {
+		t.Errorf("got comments %v", comments)
+	}
+	if got := len(s.db.fileRevisions(s.fileId)); got != revisions+3 {
+		t.Errorf("got %d revisions, want %d", got, revisions+3)
+	}
+}
+
+func TestGraphQLMutationErrors(t *testing.T) {
+	s := newReferencesServer(t, OnDeleteRestrict)
+	rec := s.do(t, http.MethodPut, fmt.Sprintf("/jsonfiles/%s/schemas/users", s.fileId), `{"items": {"properties": {"id": {"maximum": 5}}}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("error setting schema: %d %s", rec.Code, rec.Body.String())
+	}
+
+	response := s.graphql(t, `mutation {
+		missingPost: createComment(input: {postId: "9"}) { id }
+		restricted: deleteUser(id: "1") { id }
+		invalid: createUser(input: {id: "7"}) { id }
+		existingId: createUser(input: {id: "2"}) { id }
+		missingItem: deleteUser(id: "9") { id }
+		created: createUser(input: {}) { id }
+	}`, nil)
+
+	if !reflect.DeepEqual(response.Data, map[string]any{
+		"missingPost": nil,
+		"restricted":  nil,
+		"invalid":     nil,
+		"existingId":  nil,
+		"missingItem": nil,
+		"created":     map[string]any{"id": "3"},
+	}) {
+		t.Errorf("got data %v", response.Data)
+	}
+	got := map[string]string{}
+	for _, err := range response.Errors {
+		path := err["path"].([]any)
+		got[path[0].(string)] = err["message"].(string)
+	}
+	want := map[string]string{
+		"missingPost": "invalid reference",
+		"restricted":  "resource item is still referenced",
+		"invalid":     "json does not match schema",
+		"existingId":  "resource item with given id already exists",
+		"missingItem": "cannot find resource item with given id",
+	}
+	if !reflect.DeepEqual(got, want) {
+		data, _ := json.MarshalIndent(response.Errors, "", "  ")
+		t.Errorf("got errors %s, want %v", data, want)
+	}
+}
@@ -1,21 +1,25 @@
 package jsonfile
 
 import (
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"maps"
 	"net/http"
+	"net/url"
 	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
-	"github.com/google/uuid"
-	"github.com/pl3lee/restjson/internal/auth"
 	"github.com/pl3lee/restjson/internal/database"
-	"github.com/pl3lee/restjson/internal/s3util"
+	"github.com/pl3lee/restjson/internal/jsonpatch"
+	"github.com/pl3lee/restjson/internal/storage"
 	"github.com/pl3lee/restjson/internal/utils"
 )
 
 func (cfg *JsonConfig) HandlerGetResource(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
 	resource := chi.URLParam(r, "resource")
 	fileContents, ok := r.Context().Value(FileContentContextKey).(map[string]any)
 	if !ok {
@@ -28,10 +32,34 @@ func (cfg *JsonConfig) HandlerGetResource(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	utils.RespondWithJSON(w, http.StatusOK, resourceData)
+	items, ok := resourceData.([]any)
+	if !ok {
+		respondWithETag(w, r, http.StatusOK, resourceData)
+		return
+	}
+
+	result, err := cfg.queryItems(w, r, items)
+	if err != nil {
+		utils.RespondWithErrorDetails(w, http.StatusBadRequest, "invalid query", err, err)
+		return
+	}
+	result, err = embedRelations(fileMetadata, fileContents, resource, result, r.URL.Query())
+	if err != nil {
+		utils.RespondWithErrorDetails(w, http.StatusBadRequest, "invalid query", err, err)
+		return
+	}
+
+	respondWithETag(w, r, http.StatusOK, result)
 }
 
 func (cfg *JsonConfig) HandlerGetResourceItem(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+	resource := chi.URLParam(r, "resource")
+	fileContents, ok := r.Context().Value(FileContentContextKey).(map[string]any)
+	if !ok {
+		utils.RespondWithError(w, http.StatusBadRequest, "json file is not a map", nil)
+		return
+	}
 	items, ok := r.Context().Value(ResourceDataContextKey).([]any)
 	if !ok {
 		utils.RespondWithError(w, http.StatusInternalServerError, "failed to retrieve items from context", nil)
@@ -39,21 +67,20 @@ func (cfg *JsonConfig) HandlerGetResourceItem(w http.ResponseWriter, r *http.Req
 	}
 	resourceId := chi.URLParam(r, "id")
 
-	for _, item := range items {
-		itemMap, ok := item.(map[string]any)
-		if ok {
-			id := fmt.Sprintf("%v", itemMap["id"])
-			if id == resourceId {
-				utils.RespondWithJSON(w, http.StatusOK, item)
-				return
-			}
-		}
+	index := findItemIndex(items, resourceIdField(fileMetadata, resource), resourceId)
+	if index == -1 {
+		utils.RespondWithError(w, http.StatusNotFound, "resource with particular id not found", nil)
+		return
 	}
-	utils.RespondWithError(w, http.StatusNotFound, "resource with particular id not found", nil)
+	result, err := embedRelations(fileMetadata, fileContents, resource, items[index:index+1], r.URL.Query())
+	if err != nil {
+		utils.RespondWithErrorDetails(w, http.StatusBadRequest, "invalid query", err, err)
+		return
+	}
+	respondWithETag(w, r, http.StatusOK, result[0])
 }
 
 func (cfg *JsonConfig) HandlerCreateResourceItem(w http.ResponseWriter, r *http.Request) {
-	userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
 	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
 	resource := chi.URLParam(r, "resource")
 	fileContents, ok := r.Context().Value(FileContentContextKey).(map[string]any)
@@ -66,26 +93,41 @@ func (cfg *JsonConfig) HandlerCreateResourceItem(w http.ResponseWriter, r *http.
 		utils.RespondWithError(w, http.StatusInternalServerError, "resource is not a slice", nil)
 		return
 	}
+	if !checkIfMatch(w, r, items) {
+		return
+	}
 	var newResource map[string]any
 	defer r.Body.Close()
 	if err := json.NewDecoder(r.Body).Decode(&newResource); err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
 		return
 	}
+	if newResource == nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "request body must be an object", nil)
+		return
+	}
+
+	idField := resourceIdField(fileMetadata, resource)
+	newId, ok := assignId(items, newResource, idField, fileMetadata.IdStrategy)
+	if !ok {
+		utils.RespondWithErrorDetails(w, http.StatusConflict, "resource item with given id already exists", map[string]any{
+			idField: newResource[idField],
+		}, nil)
+		return
+	}
+
 	items = append(items, newResource)
 	fileContents[resource] = items
 
-	err := s3util.UploadJsonToS3(r.Context(), cfg.S3Client, cfg.Rdb, cfg.S3Bucket, userId, fileMetadata.ID, fileContents)
-
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "failed to save updated file contents to s3", err)
+	if !cfg.saveFileContents(w, r, fileMetadata, fileContents) {
 		return
 	}
-	utils.RespondWithJSON(w, http.StatusCreated, fileContents)
+	w.Header().Set("Location", fmt.Sprintf("%s%s/%s", cfg.BaseURL, r.URL.Path, url.PathEscape(newId)))
+	setETag(w, newResource)
+	utils.RespondWithJSON(w, http.StatusCreated, newResource)
 }
 
 func (cfg *JsonConfig) HandlerUpdateResourceItem(w http.ResponseWriter, r *http.Request) {
-	userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
 	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
 	resource := chi.URLParam(r, "resource")
 	fileContents, ok := r.Context().Value(FileContentContextKey).(map[string]any)
@@ -98,7 +140,7 @@ func (cfg *JsonConfig) HandlerUpdateResourceItem(w http.ResponseWriter, r *http.
 		utils.RespondWithError(w, http.StatusInternalServerError, "resource is not a slice", nil)
 		return
 	}
-	var updatedResourceItem map[string]any
+	var updatedResourceItem any
 	defer r.Body.Close()
 	if err := json.NewDecoder(r.Body).Decode(&updatedResourceItem); err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
@@ -106,34 +148,29 @@ func (cfg *JsonConfig) HandlerUpdateResourceItem(w http.ResponseWriter, r *http.
 	}
 	resourceId := chi.URLParam(r, "id")
 
-	foundResourceItem := false
-	for index, item := range items {
-		itemMap, ok := item.(map[string]any)
-		if ok {
-			id := fmt.Sprintf("%v", itemMap["id"])
-			if id == resourceId {
-				items[index] = updatedResourceItem
-				foundResourceItem = true
-			}
-		}
-	}
-	if !foundResourceItem {
+	idField := resourceIdField(fileMetadata, resource)
+	index := findItemIndex(items, idField, resourceId)
+	if index == -1 {
 		utils.RespondWithError(w, http.StatusNotFound, "cannot find resource item with given id", nil)
 		return
 	}
+	if !checkIfMatch(w, r, items[index]) {
+		return
+	}
+	if !checkUpdatedItem(w, items, index, updatedResourceItem, idField) {
+		return
+	}
+	items[index] = updatedResourceItem
 	fileContents[resource] = items
 
-	err := s3util.UploadJsonToS3(r.Context(), cfg.S3Client, cfg.Rdb, cfg.S3Bucket, userId, fileMetadata.ID, fileContents)
-
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "failed to save updated file contents to s3", err)
+	if !cfg.saveFileContents(w, r, fileMetadata, fileContents) {
 		return
 	}
-	utils.RespondWithJSON(w, http.StatusOK, fileContents)
+	setETag(w, updatedResourceItem)
+	utils.RespondWithJSON(w, http.StatusOK, updatedResourceItem)
 }
 
 func (cfg *JsonConfig) HandlerPartialUpdateResourceItem(w http.ResponseWriter, r *http.Request) {
-	userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
 	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
 	resource := chi.URLParam(r, "resource")
 	fileContents, ok := r.Context().Value(FileContentContextKey).(map[string]any)
@@ -146,42 +183,35 @@ func (cfg *JsonConfig) HandlerPartialUpdateResourceItem(w http.ResponseWriter, r
 		utils.RespondWithError(w, http.StatusInternalServerError, "resource is not a slice", nil)
 		return
 	}
-	var partialUpdate map[string]any
-	defer r.Body.Close()
-	if err := json.NewDecoder(r.Body).Decode(&partialUpdate); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
-		return
-	}
 	resourceId := chi.URLParam(r, "id")
 
-	foundResourceItem := false
-	for index, item := range items {
-		itemMap, ok := item.(map[string]any)
-		if ok {
-			id := fmt.Sprintf("%v", itemMap["id"])
-			if id == resourceId {
-				maps.Copy(itemMap, partialUpdate)
-				items[index] = itemMap
-				foundResourceItem = true
-			}
-		}
-	}
-	if !foundResourceItem {
+	idField := resourceIdField(fileMetadata, resource)
+	index := findItemIndex(items, idField, resourceId)
+	if index == -1 {
 		utils.RespondWithError(w, http.StatusNotFound, "cannot find resource item with given id", nil)
 		return
 	}
+	if !checkIfMatch(w, r, items[index]) {
+		return
+	}
+	patchedItem, ok := applyPatchRequest(w, r, items[index])
+	if !ok {
+		return
+	}
+	if !checkUpdatedItem(w, items, index, patchedItem, idField) {
+		return
+	}
+	items[index] = patchedItem
 	fileContents[resource] = items
 
-	err := s3util.UploadJsonToS3(r.Context(), cfg.S3Client, cfg.Rdb, cfg.S3Bucket, userId, fileMetadata.ID, fileContents)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "failed to save updated file contents to s3", err)
+	if !cfg.saveFileContents(w, r, fileMetadata, fileContents) {
 		return
 	}
-	utils.RespondWithJSON(w, http.StatusOK, fileContents)
+	setETag(w, patchedItem)
+	utils.RespondWithJSON(w, http.StatusOK, patchedItem)
 }
 
 func (cfg *JsonConfig) HandlerDeleteResourceItem(w http.ResponseWriter, r *http.Request) {
-	userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
 	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
 	resource := chi.URLParam(r, "resource")
 	fileContents, ok := r.Context().Value(FileContentContextKey).(map[string]any)
@@ -196,34 +226,25 @@ func (cfg *JsonConfig) HandlerDeleteResourceItem(w http.ResponseWriter, r *http.
 	}
 	resourceId := chi.URLParam(r, "id")
 
-	foundResourceItem := false
-	for index, item := range items {
-		itemMap, ok := item.(map[string]any)
-		if ok {
-			id := fmt.Sprintf("%v", itemMap["id"])
-			if id == resourceId {
-				items = slices.Delete(items, index, index+1)
-				foundResourceItem = true
-				break
-			}
-		}
-	}
-	if !foundResourceItem {
+	index := findItemIndex(items, resourceIdField(fileMetadata, resource), resourceId)
+	if index == -1 {
 		utils.RespondWithError(w, http.StatusNotFound, "cannot find resource item with given id", nil)
 		return
 	}
+	if !checkIfMatch(w, r, items[index]) {
+		return
+	}
+	items = slices.Delete(items, index, index+1)
 	fileContents[resource] = items
 
-	err := s3util.UploadJsonToS3(r.Context(), cfg.S3Client, cfg.Rdb, cfg.S3Bucket, userId, fileMetadata.ID, fileContents)
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "failed to save updated file contents to s3", err)
+	// the onDelete rules of the relationships referencing the item are applied when the contents are saved
+	if !cfg.saveFileContents(w, r, fileMetadata, fileContents) {
 		return
 	}
 	utils.RespondWithJSON(w, http.StatusOK, fileContents)
 }
 
 func (cfg *JsonConfig) HandlerUpdateResource(w http.ResponseWriter, r *http.Request) {
-	userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
 	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
 	resource := chi.URLParam(r, "resource")
 	fileContents, ok := r.Context().Value(FileContentContextKey).(map[string]any)
@@ -231,6 +252,9 @@ func (cfg *JsonConfig) HandlerUpdateResource(w http.ResponseWriter, r *http.Requ
 		utils.RespondWithError(w, http.StatusBadRequest, "json file is not a map", nil)
 		return
 	}
+	if !checkIfMatch(w, r, fileContents[resource]) {
+		return
+	}
 	var updatedResource any
 	defer r.Body.Close()
 	if err := json.NewDecoder(r.Body).Decode(&updatedResource); err != nil {
@@ -239,17 +263,14 @@ func (cfg *JsonConfig) HandlerUpdateResource(w http.ResponseWriter, r *http.Requ
 	}
 	fileContents[resource] = updatedResource
 
-	err := s3util.UploadJsonToS3(r.Context(), cfg.S3Client, cfg.Rdb, cfg.S3Bucket, userId, fileMetadata.ID, fileContents)
-
-	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "failed to save updated file contents to s3", err)
+	if !cfg.saveFileContents(w, r, fileMetadata, fileContents) {
 		return
 	}
-	utils.RespondWithJSON(w, http.StatusOK, fileContents)
+	setETag(w, updatedResource)
+	utils.RespondWithJSON(w, http.StatusOK, updatedResource)
 }
 
 func (cfg *JsonConfig) HandlerPartialUpdateResource(w http.ResponseWriter, r *http.Request) {
-	userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
 	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
 	resource := chi.URLParam(r, "resource")
 	fileContents, ok := r.Context().Value(FileContentContextKey).(map[string]any)
@@ -257,27 +278,349 @@ func (cfg *JsonConfig) HandlerPartialUpdateResource(w http.ResponseWriter, r *ht
 		utils.RespondWithError(w, http.StatusBadRequest, "json file is not a map", nil)
 		return
 	}
-	existingResource, ok := fileContents[resource].(map[string]any)
+	if !checkIfMatch(w, r, fileContents[resource]) {
+		return
+	}
+	patchedResource, ok := applyPatchRequest(w, r, fileContents[resource])
 	if !ok {
-		utils.RespondWithError(w, http.StatusBadRequest, "resource is not a map", nil)
 		return
 	}
-	var partialUpdate map[string]any
-	defer r.Body.Close()
-	if err := json.NewDecoder(r.Body).Decode(&partialUpdate); err != nil {
-		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
+	fileContents[resource] = patchedResource
+
+	if !cfg.saveFileContents(w, r, fileMetadata, fileContents) {
 		return
 	}
+	setETag(w, patchedResource)
+	utils.RespondWithJSON(w, http.StatusOK, patchedResource)
+}
 
-	maps.Copy(existingResource, partialUpdate)
+func (cfg *JsonConfig) HandlerPartialUpdateJson(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+	fileContents := r.Context().Value(FileContentContextKey)
+	if !checkIfMatch(w, r, fileContents) {
+		return
+	}
+	patchedContents, ok := applyPatchRequest(w, r, fileContents)
+	if !ok {
+		return
+	}
 
-	fileContents[resource] = existingResource
+	if !cfg.saveFileContents(w, r, fileMetadata, patchedContents) {
+		return
+	}
+	setETag(w, patchedContents)
+	utils.RespondWithJSON(w, http.StatusOK, patchedContents)
+}
 
-	err := s3util.UploadJsonToS3(r.Context(), cfg.S3Client, cfg.Rdb, cfg.S3Bucket, userId, fileMetadata.ID, fileContents)
+// HandlerResourcePath handles paths nested deeper than a resource item, such as /users/1/addresses/2,
+// and fields of resources that are objects, such as /settings/theme.
+// Each path segment is resolved through objects by key and through arrays by id.
+func (cfg *JsonConfig) HandlerResourcePath(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+	fileContents, ok := r.Context().Value(FileContentContextKey).(map[string]any)
+	if !ok {
+		utils.RespondWithError(w, http.StatusBadRequest, "json file is not a map", nil)
+		return
+	}
 
+	segments := resourcePathSegments(r)
+	tokens, exists, err := resolveResourcePath(fileMetadata, fileContents, segments)
+	if err != nil || !exists {
+		if route, ok := resolveChildRoute(fileMetadata, fileContents, segments); ok {
+			cfg.handleChildRoute(w, r, route)
+			return
+		}
+	}
+	if err != nil || (!exists && r.Method != http.MethodPut) {
+		utils.RespondWithErrorDetails(w, http.StatusNotFound, "resource path not found", map[string]string{
+			"path": "/" + strings.Join(segments, "/"),
+		}, err)
+		return
+	}
+	target := valueAt(fileContents, tokens)
+
+	if r.Method == http.MethodGet {
+		items, ok := target.([]any)
+		if !ok {
+			respondWithETag(w, r, http.StatusOK, target)
+			return
+		}
+		result, err := cfg.queryItems(w, r, items)
+		if err != nil {
+			utils.RespondWithErrorDetails(w, http.StatusBadRequest, "invalid query", err, err)
+			return
+		}
+		respondWithETag(w, r, http.StatusOK, result)
+		return
+	}
+
+	if exists && !checkIfMatch(w, r, target) {
+		return
+	}
+
+	// a path ending at an item of an array replaces the item, which follows the rules of the item routes
+	checkValue := func(value any) bool { return true }
+	if exists && len(tokens) > 1 {
+		if items, ok := valueAt(fileContents, tokens[:len(tokens)-1]).([]any); ok && hasIds(items) {
+			index, _ := strconv.Atoi(tokens[len(tokens)-1])
+			idField := resourceIdField(fileMetadata, tokens[len(tokens)-2])
+			checkValue = func(value any) bool {
+				return checkUpdatedItem(w, items, index, value, idField)
+			}
+		}
+	}
+
+	var updatedContents any
+	switch r.Method {
+	case http.MethodPut:
+		var updatedValue any
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&updatedValue); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
+			return
+		}
+		if !checkValue(updatedValue) {
+			return
+		}
+		updatedContents, err = jsonpatch.Add(fileContents, tokens, updatedValue)
+		target = updatedValue
+	case http.MethodPatch:
+		patchedValue, ok := applyPatchRequest(w, r, target)
+		if !ok || !checkValue(patchedValue) {
+			return
+		}
+		updatedContents, err = jsonpatch.Replace(fileContents, tokens, patchedValue)
+		target = patchedValue
+	case http.MethodPost:
+		items, ok := target.([]any)
+		if !ok {
+			utils.RespondWithError(w, http.StatusBadRequest, "resource is not an array", nil)
+			return
+		}
+		var newItem map[string]any
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&newItem); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
+			return
+		}
+		if newItem == nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "request body must be an object", nil)
+			return
+		}
+		idField := resourceIdField(fileMetadata, tokens[len(tokens)-1])
+		newId, ok := assignId(items, newItem, idField, fileMetadata.IdStrategy)
+		if !ok {
+			utils.RespondWithErrorDetails(w, http.StatusConflict, "resource item with given id already exists", map[string]any{
+				idField: newItem[idField],
+			}, nil)
+			return
+		}
+		updatedContents, err = jsonpatch.Add(fileContents, append(tokens, "-"), newItem)
+		target = newItem
+		w.Header().Set("Location", fmt.Sprintf("%s%s/%s", cfg.BaseURL, strings.TrimSuffix(r.URL.Path, "/"), url.PathEscape(newId)))
+	case http.MethodDelete:
+		updatedContents, _, err = jsonpatch.Remove(fileContents, tokens)
+	default:
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "failed to save updated file contents to s3", err)
+		utils.RespondWithError(w, http.StatusUnprocessableEntity, "cannot update resource path", err)
 		return
 	}
-	utils.RespondWithJSON(w, http.StatusOK, fileContents)
+
+	if !cfg.saveFileContents(w, r, fileMetadata, updatedContents) {
+		return
+	}
+
+	switch {
+	case r.Method == http.MethodPost:
+		setETag(w, target)
+		utils.RespondWithJSON(w, http.StatusCreated, target)
+	case r.Method == http.MethodPut && !exists:
+		setETag(w, target)
+		utils.RespondWithJSON(w, http.StatusCreated, target)
+	case r.Method == http.MethodDelete:
+		utils.RespondWithJSON(w, http.StatusOK, updatedContents)
+	default:
+		setETag(w, target)
+		utils.RespondWithJSON(w, http.StatusOK, target)
+	}
+}
+
+// handleChildRoute handles /{parent}/{id}/{child} and /{parent}/{id}/{child}/{childId},
+// which work like the routes of the child resource, limited to the children of the parent item
+func (cfg *JsonConfig) handleChildRoute(w http.ResponseWriter, r *http.Request, route childRoute) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+	fileContents := r.Context().Value(FileContentContextKey).(map[string]any)
+	children := fileContents[route.child].([]any)
+	idField := resourceIdField(fileMetadata, route.child)
+
+	if route.childId == "" {
+		switch r.Method {
+		case http.MethodGet:
+			belonging := []any{}
+			for _, child := range children {
+				if route.belongsTo(child) {
+					belonging = append(belonging, child)
+				}
+			}
+			result, err := cfg.queryItems(w, r, belonging)
+			if err != nil {
+				utils.RespondWithErrorDetails(w, http.StatusBadRequest, "invalid query", err, err)
+				return
+			}
+			result, err = embedRelations(fileMetadata, fileContents, route.child, result, r.URL.Query())
+			if err != nil {
+				utils.RespondWithErrorDetails(w, http.StatusBadRequest, "invalid query", err, err)
+				return
+			}
+			respondWithETag(w, r, http.StatusOK, result)
+		case http.MethodPost:
+			var newItem map[string]any
+			defer r.Body.Close()
+			if err := json.NewDecoder(r.Body).Decode(&newItem); err != nil {
+				utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
+				return
+			}
+			if newItem == nil {
+				utils.RespondWithError(w, http.StatusBadRequest, "request body must be an object", nil)
+				return
+			}
+			// the new child always belongs to the parent in the url
+			newItem[route.foreignKey] = route.parentId
+			newId, ok := assignId(children, newItem, idField, fileMetadata.IdStrategy)
+			if !ok {
+				utils.RespondWithErrorDetails(w, http.StatusConflict, "resource item with given id already exists", map[string]any{
+					idField: newItem[idField],
+				}, nil)
+				return
+			}
+			fileContents[route.child] = append(children, newItem)
+
+			if !cfg.saveFileContents(w, r, fileMetadata, fileContents) {
+				return
+			}
+			w.Header().Set("Location", fmt.Sprintf("%s%s/%s", cfg.BaseURL, strings.TrimSuffix(r.URL.Path, "/"), url.PathEscape(newId)))
+			setETag(w, newItem)
+			utils.RespondWithJSON(w, http.StatusCreated, newItem)
+		default:
+			utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed", nil)
+		}
+		return
+	}
+
+	index := findItemIndex(children, idField, route.childId)
+	if index == -1 || !route.belongsTo(children[index]) {
+		utils.RespondWithError(w, http.StatusNotFound, "cannot find resource item with given id", nil)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		result, err := embedRelations(fileMetadata, fileContents, route.child, children[index:index+1], r.URL.Query())
+		if err != nil {
+			utils.RespondWithErrorDetails(w, http.StatusBadRequest, "invalid query", err, err)
+			return
+		}
+		respondWithETag(w, r, http.StatusOK, result[0])
+		return
+	}
+
+	if !checkIfMatch(w, r, children[index]) {
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		var updatedItem any
+		defer r.Body.Close()
+		if err := json.NewDecoder(r.Body).Decode(&updatedItem); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
+			return
+		}
+		if !checkUpdatedItem(w, children, index, updatedItem, idField) {
+			return
+		}
+		updatedItem.(map[string]any)[route.foreignKey] = route.parentId
+		children[index] = updatedItem
+	case http.MethodPatch:
+		patchedItem, ok := applyPatchRequest(w, r, children[index])
+		if !ok || !checkUpdatedItem(w, children, index, patchedItem, idField) {
+			return
+		}
+		children[index] = patchedItem
+	case http.MethodDelete:
+		fileContents[route.child] = slices.Delete(children, index, index+1)
+	default:
+		utils.RespondWithError(w, http.StatusMethodNotAllowed, "method not allowed", nil)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		fileContents[route.child] = children
+	}
+
+	if !cfg.saveFileContents(w, r, fileMetadata, fileContents) {
+		return
+	}
+	if r.Method == http.MethodDelete {
+		utils.RespondWithJSON(w, http.StatusOK, fileContents)
+		return
+	}
+	setETag(w, children[index])
+	utils.RespondWithJSON(w, http.StatusOK, children[index])
+}
+
+// checkUpdatedItem checks that updated can replace items[index]: it must be an object, and keeps the id of the item
+// it replaces unless it has an id of its own that no other item uses.
+// It responds with an error and returns false otherwise.
+func checkUpdatedItem(w http.ResponseWriter, items []any, index int, updated any, idField string) bool {
+	updatedItem, ok := updated.(map[string]any)
+	if !ok {
+		utils.RespondWithError(w, http.StatusBadRequest, "resource item must be an object", nil)
+		return false
+	}
+	if !keepItemId(items, index, updatedItem, idField) {
+		utils.RespondWithErrorDetails(w, http.StatusConflict, "resource item with given id already exists", map[string]any{
+			idField: updatedItem[idField],
+		}, nil)
+		return false
+	}
+	return true
+}
+
+// HandlerResetJson restores the contents of a json file to a named snapshot, so that test suites can start from a known state.
+// It runs under the write lock of the file, so no other write can be interleaved with the reset.
+func (cfg *JsonConfig) HandlerResetJson(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+
+	name := r.URL.Query().Get("snapshot")
+	if name == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "snapshot is required", nil)
+		return
+	}
+	snapshot, err := cfg.Db.GetJsonSnapshot(r.Context(), database.GetJsonSnapshotParams{
+		JsonFileID: fileMetadata.ID,
+		Name:       name,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("snapshot %q not found", name), err)
+		return
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error getting json snapshot", err)
+		return
+	}
+
+	contents, err := storage.GetJsonRevision(r.Context(), cfg.Store, fileMetadata.UserID, fileMetadata.ID, snapshot.Revision)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "cannot get json snapshot from storage", err)
+		return
+	}
+	if !cfg.saveFileContents(w, r, fileMetadata, contents) {
+		return
+	}
+	// the cache was just written with the snapshot, drop it anyway so the next request reads the baseline from storage
+	storage.ClearJsonCache(r.Context(), cfg.Rdb, fileMetadata.UserID, fileMetadata.ID)
+
+	setETag(w, contents)
+	utils.RespondWithJSON(w, http.StatusOK, contents)
 }
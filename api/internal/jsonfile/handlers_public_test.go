@@ -0,0 +1,125 @@
+package jsonfile
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/pl3lee/restjson/internal/jsonpatch"
+)
+
+const itemsFixture = `{
+	"users": [{"id": 1, "name": "ada"}, {"id": 2, "name": "grace"}],
+	"posts": [{"id": 1, "userId": 1, "title": "first"}, {"id": 2, "userId": 1, "title": "second"}],
+	"teams": [{"id": 1, "members": [{"id": 1, "name": "ada"}, {"id": 2, "name": "grace"}]}],
+	"settings": {"theme": "dark"}
+}`
+
+func TestUpdateItemIds(t *testing.T) {
+	// every route replacing or patching an item
+	writes := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		status      int
+		// pointer and value of the updated item, when the write succeeds
+		pointer string
+		want    string
+	}{
+		{name: "put item without id", method: http.MethodPut, path: "/public/%s/users/1", body: `{"name": "ada lovelace"}`, status: http.StatusOK, pointer: "/users/0", want: `{"id": 1, "name": "ada lovelace"}`},
+		{name: "put item with new id", method: http.MethodPut, path: "/public/%s/users/1", body: `{"id": 3, "name": "ada"}`, status: http.StatusOK, pointer: "/users/0", want: `{"id": 3, "name": "ada"}`},
+		{name: "put item with id of another item", method: http.MethodPut, path: "/public/%s/users/1", body: `{"id": 2}`, status: http.StatusConflict},
+		{name: "put item with array", method: http.MethodPut, path: "/public/%s/users/1", body: `[{"id": 1}]`, status: http.StatusBadRequest},
+		{name: "put item with null", method: http.MethodPut, path: "/public/%s/users/1", body: `null`, status: http.StatusBadRequest},
+		{name: "patch item with id of another item", method: http.MethodPatch, path: "/public/%s/users/1", body: `{"id": 2}`, status: http.StatusConflict},
+		{name: "merge patch removing id", method: http.MethodPatch, path: "/public/%s/users/1", contentType: mergePatchMediaType, body: `{"id": null}`, status: http.StatusOK, pointer: "/users/0", want: `{"id": 1, "name": "ada"}`},
+		{name: "json patch replacing item", method: http.MethodPatch, path: "/public/%s/users/1", contentType: jsonPatchMediaType, body: `[{"op": "replace", "path": "", "value": 5}]`, status: http.StatusBadRequest},
+		{name: "put nested item without id", method: http.MethodPut, path: "/public/%s/teams/1/members/1", body: `{"name": "ada lovelace"}`, status: http.StatusOK, pointer: "/teams/0/members/0", want: `{"id": 1, "name": "ada lovelace"}`},
+		{name: "put nested item with id of another item", method: http.MethodPut, path: "/public/%s/teams/1/members/1", body: `{"id": 2}`, status: http.StatusConflict},
+		{name: "put nested item with number", method: http.MethodPut, path: "/public/%s/teams/1/members/1", body: `5`, status: http.StatusBadRequest},
+		{name: "patch nested item with id of another item", method: http.MethodPatch, path: "/public/%s/teams/1/members/1", body: `{"id": 2}`, status: http.StatusConflict},
+		{name: "put child without id", method: http.MethodPut, path: "/public/%s/users/1/posts/1", body: `{"title": "edited"}`, status: http.StatusOK, pointer: "/posts/0", want: `{"id": 1, "userId": 1, "title": "edited"}`},
+		{name: "put child with id of another item", method: http.MethodPut, path: "/public/%s/users/1/posts/1", body: `{"id": 2}`, status: http.StatusConflict},
+		{name: "put child with array", method: http.MethodPut, path: "/public/%s/users/1/posts/1", body: `[]`, status: http.StatusBadRequest},
+		{name: "patch child with id of another item", method: http.MethodPatch, path: "/public/%s/users/1/posts/1", body: `{"id": 2}`, status: http.StatusConflict},
+	}
+	for _, write := range writes {
+		t.Run(write.name, func(t *testing.T) {
+			s := newTestServer(t, itemsFixture)
+			contentType := cmp.Or(write.contentType, "application/json")
+			rec := s.doWithContentType(t, write.method, fmt.Sprintf(write.path, s.fileId), contentType, write.body)
+			if rec.Code != write.status {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, write.status, rec.Body.String())
+			}
+
+			contents := s.contents(t)
+			if write.status != http.StatusOK {
+				if !reflect.DeepEqual(contents, decodeJson(t, itemsFixture)) {
+					t.Errorf("contents changed to %v", contents)
+				}
+				return
+			}
+			tokens, err := jsonpatch.ParsePointer(write.pointer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := jsonpatch.Get(contents, tokens)
+			if err != nil {
+				t.Fatalf("cannot get %s: %v", write.pointer, err)
+			}
+			if want := decodeJson(t, write.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("got item %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestWritesRespondWithWrittenValue(t *testing.T) {
+	// every write of an item or resource, with the pointer of the value it responds with
+	writes := []struct {
+		name    string
+		method  string
+		path    string
+		body    string
+		status  int
+		pointer string
+	}{
+		{name: "put item", method: http.MethodPut, path: "/public/%s/users/1", body: `{"name": "ada lovelace"}`, status: http.StatusOK, pointer: "/users/0"},
+		{name: "patch item", method: http.MethodPatch, path: "/public/%s/users/1", body: `{"name": "ada lovelace"}`, status: http.StatusOK, pointer: "/users/0"},
+		{name: "put resource", method: http.MethodPut, path: "/public/%s/users", body: `[{"id": 1}]`, status: http.StatusOK, pointer: "/users"},
+		{name: "patch resource", method: http.MethodPatch, path: "/public/%s/settings", body: `{"theme": "light"}`, status: http.StatusOK, pointer: "/settings"},
+		{name: "put nested item", method: http.MethodPut, path: "/public/%s/teams/1/members/1", body: `{"name": "ada lovelace"}`, status: http.StatusOK, pointer: "/teams/0/members/0"},
+		{name: "patch nested item", method: http.MethodPatch, path: "/public/%s/teams/1/members/1", body: `{"name": "ada lovelace"}`, status: http.StatusOK, pointer: "/teams/0/members/0"},
+		{name: "put new nested field", method: http.MethodPut, path: "/public/%s/teams/1/name", body: `"core"`, status: http.StatusCreated, pointer: "/teams/0/name"},
+		{name: "put child", method: http.MethodPut, path: "/public/%s/users/1/posts/1", body: `{"title": "edited"}`, status: http.StatusOK, pointer: "/posts/0"},
+		{name: "patch child", method: http.MethodPatch, path: "/public/%s/users/1/posts/1", body: `{"title": "edited"}`, status: http.StatusOK, pointer: "/posts/0"},
+	}
+	for _, write := range writes {
+		t.Run(write.name, func(t *testing.T) {
+			s := newTestServer(t, itemsFixture)
+			rec := s.do(t, write.method, fmt.Sprintf(write.path, s.fileId), write.body)
+			if rec.Code != write.status {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, write.status, rec.Body.String())
+			}
+
+			tokens, err := jsonpatch.ParsePointer(write.pointer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := jsonpatch.Get(s.contents(t), tokens)
+			if err != nil {
+				t.Fatalf("cannot get %s: %v", write.pointer, err)
+			}
+			if got := decodeBody[any](t, rec); !reflect.DeepEqual(got, want) {
+				t.Errorf("got response %v, want %v", got, want)
+			}
+			if etag, _ := computeETag(want); rec.Header().Get("ETag") != etag {
+				t.Errorf("got etag %s, want %s", rec.Header().Get("ETag"), etag)
+			}
+		})
+	}
+}
@@ -1,17 +1,25 @@
 package jsonfile
 
 import (
+	"cmp"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"slices"
 	"strings"
 
 	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/pl3lee/restjson/internal/auth"
 	"github.com/pl3lee/restjson/internal/database"
-	"github.com/pl3lee/restjson/internal/s3util"
+	"github.com/pl3lee/restjson/internal/jsonpatch"
+	"github.com/pl3lee/restjson/internal/jsonschema"
+	"github.com/pl3lee/restjson/internal/storage"
 	"github.com/pl3lee/restjson/internal/utils"
 )
 
@@ -28,6 +36,73 @@ type JsonMetadataResponse struct {
 	UserID     uuid.UUID `json:"userId"`
 	FileName   string    `json:"fileName"`
 	ModifiedAt time.Time `json:"modifiedAt"`
+	// size of the contents in bytes, and the maximum size allowed by the plan of the user
+	Size    int64 `json:"size"`
+	MaxSize int64 `json:"maxSize"`
+}
+
+// JsonSettingsRequest only changes the settings it contains, an empty string resets a setting to its default.
+// ResourceIdFields is merged with the current id fields, and a null id field removes the override for that resource.
+type JsonSettingsRequest struct {
+	IdStrategy       *string            `json:"idStrategy"`
+	IdField          *string            `json:"idField"`
+	ResourceIdFields map[string]*string `json:"resourceIdFields"`
+	ForeignKeySuffix *string            `json:"foreignKeySuffix"`
+}
+
+type JsonSettingsResponse struct {
+	IdStrategy       string            `json:"idStrategy"`
+	IdField          string            `json:"idField"`
+	ResourceIdFields map[string]string `json:"resourceIdFields"`
+	ForeignKeySuffix string            `json:"foreignKeySuffix"`
+}
+
+type JsonRelationshipsRequest struct {
+	Relationships []Relationship `json:"relationships"`
+}
+
+type JsonRelationshipsResponse struct {
+	Relationships []Relationship `json:"relationships"`
+}
+
+type JsonSchemasResponse struct {
+	File      json.RawMessage            `json:"file"`
+	Resources map[string]json.RawMessage `json:"resources"`
+}
+
+type InferredSchemasResponse struct {
+	File      map[string]any            `json:"file"`
+	Resources map[string]map[string]any `json:"resources"`
+}
+
+type JsonRevisionResponse struct {
+	Revision   int32     `json:"revision"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UserID     uuid.UUID `json:"userId"`
+	Source     string    `json:"source"`
+	ApiKeyName string    `json:"apiKeyName,omitempty"`
+	Size       int64     `json:"size"`
+	// Checksum is empty for revisions recorded before checksums were kept
+	Checksum string `json:"sha256,omitempty"`
+}
+
+type JsonSnapshotRequest struct {
+	Name string `json:"name"`
+}
+
+type JsonSnapshotResponse struct {
+	Name      string    `json:"name"`
+	Revision  int32     `json:"revision"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type JsonDiffResponse struct {
+	From int32 `json:"from"`
+	// nil when comparing with the current contents
+	To      *int32                `json:"to"`
+	Patch   []jsonpatch.Operation `json:"patch"`
+	Summary jsonpatch.Summary     `json:"summary"`
 }
 
 type Route struct {
@@ -77,27 +152,34 @@ func (cfg *JsonConfig) HandlerCreateJson(w http.ResponseWriter, r *http.Request)
 	fileId := uuid.New()
 
 	emptyJson := map[string]any{}
-	err = s3util.UploadJsonToS3(r.Context(), cfg.S3Client, cfg.Rdb, cfg.S3Bucket, userId, fileId, emptyJson)
+	_, err = storage.PutJson(r.Context(), cfg.Store, cfg.Rdb, userId, fileId, emptyJson)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "error uploading empty JSON to s3", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "error saving empty JSON", err)
 		return
 	}
 	file, err := cfg.Db.CreateNewJson(r.Context(), database.CreateNewJsonParams{
 		ID:       fileId,
 		UserID:   userId,
 		FileName: createReq.FileName,
-		Url:      fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s/%s.json", cfg.S3Bucket, cfg.S3Region, userId.String(), fileId.String()),
+		Url:      cfg.Store.URL(storage.FileKey(userId, fileId)),
 	})
 	if err != nil {
 		utils.RespondWithError(w, http.StatusBadRequest, "cannot create new json", err)
 		return
 	}
+	if _, err := cfg.recordRevision(r.Context(), userId, fileId, emptyJson); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error recording json revision", err)
+		return
+	}
 	utils.RespondWithJSON(w, http.StatusOK, file)
 }
 
 func (cfg *JsonConfig) HandlerUpdateJson(w http.ResponseWriter, r *http.Request) {
 	userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
 	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+	if !checkIfMatch(w, r, r.Context().Value(FileContentContextKey)) {
+		return
+	}
 
 	var jsonData any
 	if err := json.NewDecoder(r.Body).Decode(&jsonData); err != nil {
@@ -106,34 +188,51 @@ func (cfg *JsonConfig) HandlerUpdateJson(w http.ResponseWriter, r *http.Request)
 	}
 	defer r.Body.Close()
 
-	if err := s3util.UploadJsonToS3(r.Context(), cfg.S3Client, cfg.Rdb, cfg.S3Bucket, userId, fileMetadata.ID, jsonData); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "error uploading JSON to s3", err)
+	if !cfg.saveFileContents(w, r, fileMetadata, jsonData) {
 		return
 	}
 
-	fileContents, err := s3util.GetJsonFromS3(r.Context(), cfg.S3Client, cfg.Rdb, cfg.S3Bucket, userId, fileMetadata.ID)
+	fileContents, err := storage.GetJson(r.Context(), cfg.Store, cfg.Rdb, userId, fileMetadata.ID)
 	if err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "cannot get updated json file from s3", err)
+		utils.RespondWithError(w, http.StatusInternalServerError, "cannot get updated json file", err)
 		return
 	}
+	setETag(w, fileContents)
 	utils.RespondWithJSON(w, http.StatusOK, fileContents)
 }
 
 func (cfg *JsonConfig) HandlerGetJsonMetadata(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
 	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
 
-	response := JsonMetadataResponse{
-		ID:       fileMetadata.ID,
-		UserID:   fileMetadata.UserID,
-		FileName: fileMetadata.FileName,
+	data, err := json.Marshal(r.Context().Value(FileContentContextKey))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error computing json file size", err)
+		return
+	}
+	maxSize, err := cfg.maxFileSize(r.Context(), userId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error getting maximum file size", err)
+		return
 	}
-	utils.RespondWithJSON(w, http.StatusOK, response)
 
+	utils.RespondWithJSON(w, http.StatusOK, jsonMetadataResponse(fileMetadata, int64(len(data)), maxSize))
+}
+
+func jsonMetadataResponse(file database.JsonFile, size int64, maxSize int64) JsonMetadataResponse {
+	return JsonMetadataResponse{
+		ID:         file.ID,
+		UserID:     file.UserID,
+		FileName:   file.FileName,
+		ModifiedAt: file.UpdatedAt,
+		Size:       size,
+		MaxSize:    maxSize,
+	}
 }
 
 func (cfg *JsonConfig) HandlerGetJson(w http.ResponseWriter, r *http.Request) {
 	fileContents := r.Context().Value(FileContentContextKey)
-	utils.RespondWithJSON(w, http.StatusOK, fileContents)
+	respondWithETag(w, r, http.StatusOK, fileContents)
 }
 
 func (cfg *JsonConfig) HandlerGetJsonFiles(w http.ResponseWriter, r *http.Request) {
@@ -145,21 +244,42 @@ func (cfg *JsonConfig) HandlerGetJsonFiles(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// the latest revision of a file records the size of its current contents
+	revisionSizes, err := cfg.Db.GetLatestJsonRevisionSizes(r.Context(), userId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error getting json file sizes", err)
+		return
+	}
+	sizes := map[uuid.UUID]int64{}
+	for _, revisionSize := range revisionSizes {
+		sizes[revisionSize.JsonFileID] = revisionSize.Size
+	}
+	maxSize, err := cfg.maxFileSize(r.Context(), userId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error getting maximum file size", err)
+		return
+	}
+
 	jsonFilesResponse := []JsonMetadataResponse{}
 	for _, file := range jsonFiles {
-		fileMetadata := JsonMetadataResponse{
-			ID:         file.ID,
-			UserID:     file.UserID,
-			FileName:   file.FileName,
-			ModifiedAt: file.UpdatedAt,
+		size, ok := sizes[file.ID]
+		if !ok {
+			// files created before revisions were recorded only have their stored contents
+			data, err := cfg.Store.Get(r.Context(), storage.FileKey(userId, file.ID))
+			if err != nil && !errors.Is(err, storage.ErrNotFound) {
+				utils.RespondWithError(w, http.StatusInternalServerError, "error getting json file size", err)
+				return
+			}
+			size = int64(len(data))
 		}
-		jsonFilesResponse = append(jsonFilesResponse, fileMetadata)
+		jsonFilesResponse = append(jsonFilesResponse, jsonMetadataResponse(file, size, maxSize))
 	}
 
 	utils.RespondWithJSON(w, http.StatusOK, jsonFilesResponse)
 }
 
 func (cfg *JsonConfig) HandlerRenameJsonFile(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
 	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
 
 	var renameReq RenameJsonRequest
@@ -183,104 +303,618 @@ func (cfg *JsonConfig) HandlerRenameJsonFile(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	response := JsonMetadataResponse{
-		ID:       renamedJsonFile.ID,
-		UserID:   renamedJsonFile.UserID,
-		FileName: renamedJsonFile.FileName,
+	data, err := json.Marshal(r.Context().Value(FileContentContextKey))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error computing json file size", err)
+		return
+	}
+	maxSize, err := cfg.maxFileSize(r.Context(), userId)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error getting maximum file size", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, jsonMetadataResponse(renamedJsonFile, int64(len(data)), maxSize))
+}
+
+func jsonSettingsResponse(fileMetadata database.JsonFile) JsonSettingsResponse {
+	resourceIdFields := map[string]string{}
+	if err := json.Unmarshal(fileMetadata.ResourceIdFields, &resourceIdFields); err != nil {
+		log.Printf("invalid resource id fields for json file %s: %v", fileMetadata.ID, err)
+	}
+	return JsonSettingsResponse{
+		IdStrategy:       fileMetadata.IdStrategy,
+		IdField:          fileMetadata.IdField,
+		ResourceIdFields: resourceIdFields,
+		ForeignKeySuffix: fileMetadata.ForeignKeySuffix,
+	}
+}
+
+func (cfg *JsonConfig) HandlerGetJsonSettings(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+
+	utils.RespondWithJSON(w, http.StatusOK, jsonSettingsResponse(fileMetadata))
+}
+
+func (cfg *JsonConfig) HandlerUpdateJsonSettings(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+
+	var settingsReq JsonSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&settingsReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	defer r.Body.Close()
+
+	// settings missing from the request keep their current value
+	settings := jsonSettingsResponse(fileMetadata)
+	if settingsReq.IdStrategy != nil {
+		settings.IdStrategy = cmp.Or(*settingsReq.IdStrategy, IdStrategyIncrement)
+	}
+	if !isValidIdStrategy(settings.IdStrategy) {
+		utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("id strategy must be %q or %q", IdStrategyIncrement, IdStrategyUUID), nil)
+		return
+	}
+	if settingsReq.IdField != nil {
+		settings.IdField = cmp.Or(*settingsReq.IdField, defaultIdField)
+	}
+	if settingsReq.ForeignKeySuffix != nil {
+		settings.ForeignKeySuffix = cmp.Or(*settingsReq.ForeignKeySuffix, defaultForeignKeySuffix)
+	}
+	if settings.ResourceIdFields == nil {
+		settings.ResourceIdFields = map[string]string{}
+	}
+	for resource, idField := range settingsReq.ResourceIdFields {
+		if idField == nil {
+			delete(settings.ResourceIdFields, resource)
+			continue
+		}
+		if *idField == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("id field of resource %q cannot be empty", resource), nil)
+			return
+		}
+		settings.ResourceIdFields[resource] = *idField
+	}
+	resourceIdFields, err := json.Marshal(settings.ResourceIdFields)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error encoding resource id fields", err)
+		return
+	}
+
+	updatedJsonFile, err := cfg.Db.UpdateJsonFileSettings(r.Context(), database.UpdateJsonFileSettingsParams{
+		ID:               fileMetadata.ID,
+		IdStrategy:       settings.IdStrategy,
+		IdField:          settings.IdField,
+		ResourceIdFields: resourceIdFields,
+		ForeignKeySuffix: settings.ForeignKeySuffix,
+	})
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error updating json file settings", err)
+		return
 	}
 
+	utils.RespondWithJSON(w, http.StatusOK, jsonSettingsResponse(updatedJsonFile))
+}
+
+func (cfg *JsonConfig) HandlerGetJsonRelationships(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+
+	utils.RespondWithJSON(w, http.StatusOK, JsonRelationshipsResponse{
+		Relationships: fileRelationships(fileMetadata),
+	})
+}
+
+func (cfg *JsonConfig) HandlerUpdateJsonRelationships(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+
+	var relationshipsReq JsonRelationshipsRequest
+	if err := json.NewDecoder(r.Body).Decode(&relationshipsReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	defer r.Body.Close()
+
+	if relationshipsReq.Relationships == nil {
+		relationshipsReq.Relationships = []Relationship{}
+	}
+	for i, relationship := range relationshipsReq.Relationships {
+		if relationship.Resource == "" || relationship.Field == "" || relationship.References == "" {
+			utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("relationship %d must have a resource, field and references", i), nil)
+			return
+		}
+		if relationship.OnDelete == "" {
+			relationshipsReq.Relationships[i].OnDelete = OnDeleteRestrict
+		}
+		if !isValidOnDelete(relationshipsReq.Relationships[i].OnDelete) {
+			utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("onDelete of relationship %d must be %q, %q or %q", i, OnDeleteRestrict, OnDeleteCascade, OnDeleteSetNull), nil)
+			return
+		}
+	}
+	relationships, err := json.Marshal(relationshipsReq.Relationships)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error encoding relationships", err)
+		return
+	}
+	// a reference that is already broken would make every later write fail
+	if fileContents, ok := r.Context().Value(FileContentContextKey).(map[string]any); ok {
+		updatedMetadata := fileMetadata
+		updatedMetadata.Relationships = relationships
+		var referenceErr *ReferenceError
+		if err := checkReferences(updatedMetadata, fileContents); errors.As(err, &referenceErr) {
+			respondWithReferenceError(w, referenceErr)
+			return
+		}
+	}
+
+	updatedJsonFile, err := cfg.Db.UpdateJsonFileRelationships(r.Context(), database.UpdateJsonFileRelationshipsParams{
+		ID:            fileMetadata.ID,
+		Relationships: relationships,
+	})
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error updating json file relationships", err)
+		return
+	}
+
+	utils.RespondWithJSON(w, http.StatusOK, JsonRelationshipsResponse{
+		Relationships: fileRelationships(updatedJsonFile),
+	})
+}
+
+func (cfg *JsonConfig) HandlerGetJsonSchemas(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+
+	schemas, err := cfg.Db.GetJsonSchemas(r.Context(), fileMetadata.ID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error getting json schemas", err)
+		return
+	}
+	response := JsonSchemasResponse{
+		Resources: map[string]json.RawMessage{},
+	}
+	for _, schema := range schemas {
+		if schema.Resource == "" {
+			response.File = schema.Schema
+			continue
+		}
+		response.Resources[schema.Resource] = schema.Schema
+	}
+	utils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *JsonConfig) HandlerGetJsonRevisions(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+
+	revisions, err := cfg.Db.GetJsonRevisions(r.Context(), fileMetadata.ID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error getting json revisions", err)
+		return
+	}
+	response := []JsonRevisionResponse{}
+	for _, revision := range revisions {
+		response = append(response, JsonRevisionResponse{
+			Revision:   revision.Revision,
+			CreatedAt:  revision.CreatedAt,
+			UserID:     revision.UserID,
+			Source:     revision.Source,
+			ApiKeyName: revision.ApiKeyName,
+			Size:       revision.Size,
+			Checksum:   revision.Checksum,
+		})
+	}
 	utils.RespondWithJSON(w, http.StatusOK, response)
 }
 
+// HandlerGetJsonRevision responds with the contents of a json file at a revision
+func (cfg *JsonConfig) HandlerGetJsonRevision(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+
+	_, contents, ok := cfg.getRevisionContents(w, r, fileMetadata, chi.URLParam(r, "rev"))
+	if !ok {
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, contents)
+}
+
+// HandlerRestoreJsonRevision replaces the contents of a json file with the contents at a revision.
+// The restore is saved as a new revision, so it can be undone like any other write.
+func (cfg *JsonConfig) HandlerRestoreJsonRevision(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+	if !checkIfMatch(w, r, r.Context().Value(FileContentContextKey)) {
+		return
+	}
+
+	_, contents, ok := cfg.getRevisionContents(w, r, fileMetadata, chi.URLParam(r, "rev"))
+	if !ok {
+		return
+	}
+	if !cfg.saveFileContents(w, r, fileMetadata, contents) {
+		return
+	}
+	setETag(w, contents)
+	utils.RespondWithJSON(w, http.StatusOK, contents)
+}
+
+// HandlerGetJsonDiff compares the contents of a json file at the revision from with the revision to,
+// or with the current contents when to is not given
+func (cfg *JsonConfig) HandlerGetJsonDiff(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+
+	from := r.URL.Query().Get("from")
+	if from == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "from is required", nil)
+		return
+	}
+	fromRevision, fromContents, ok := cfg.getRevisionContents(w, r, fileMetadata, from)
+	if !ok {
+		return
+	}
+
+	response := JsonDiffResponse{From: fromRevision.Revision}
+	toContents := r.Context().Value(FileContentContextKey)
+	if to := r.URL.Query().Get("to"); to != "" {
+		toRevision, contents, ok := cfg.getRevisionContents(w, r, fileMetadata, to)
+		if !ok {
+			return
+		}
+		response.To = &toRevision.Revision
+		toContents = contents
+	}
+
+	response.Patch, response.Summary = jsonpatch.Diff(fromContents, toContents)
+	utils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+// HandlerCreateJsonSnapshot names the current contents of a json file, so that they can be restored later.
+// Creating a snapshot with the name of an existing one moves it to the current contents.
+func (cfg *JsonConfig) HandlerCreateJsonSnapshot(w http.ResponseWriter, r *http.Request) {
+	userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+	fileContents := r.Context().Value(FileContentContextKey)
+
+	var snapshotReq JsonSnapshotRequest
+	if err := utils.DecodeRequest(r, &snapshotReq); err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	if snapshotReq.Name == "" {
+		utils.RespondWithError(w, http.StatusBadRequest, "snapshot name cannot be empty", nil)
+		return
+	}
+
+	revision, err := cfg.currentRevision(r.Context(), userId, fileMetadata, fileContents)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error getting current revision", err)
+		return
+	}
+	snapshot, err := cfg.Db.UpsertJsonSnapshot(r.Context(), database.UpsertJsonSnapshotParams{
+		JsonFileID: fileMetadata.ID,
+		Name:       snapshotReq.Name,
+		Revision:   revision.Revision,
+	})
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error saving json snapshot", err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusCreated, snapshotResponse(snapshot))
+}
+
+func (cfg *JsonConfig) HandlerGetJsonSnapshots(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+
+	snapshots, err := cfg.Db.GetJsonSnapshots(r.Context(), fileMetadata.ID)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error getting json snapshots", err)
+		return
+	}
+	response := []JsonSnapshotResponse{}
+	for _, snapshot := range snapshots {
+		response = append(response, snapshotResponse(snapshot))
+	}
+	utils.RespondWithJSON(w, http.StatusOK, response)
+}
+
+func (cfg *JsonConfig) HandlerDeleteJsonSnapshot(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+
+	err := cfg.Db.DeleteJsonSnapshot(r.Context(), database.DeleteJsonSnapshotParams{
+		JsonFileID: fileMetadata.ID,
+		Name:       chi.URLParam(r, "name"),
+	})
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error deleting json snapshot", err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusNoContent, nil)
+}
+
+func snapshotResponse(snapshot database.JsonSnapshot) JsonSnapshotResponse {
+	return JsonSnapshotResponse{
+		Name:      snapshot.Name,
+		Revision:  snapshot.Revision,
+		CreatedAt: snapshot.CreatedAt,
+		UpdatedAt: snapshot.UpdatedAt,
+	}
+}
+
+// HandlerInferJsonSchema infers a schema for the whole file and for each of its resources from the current contents,
+// which can be attached as they are or used as a starting point
+func (cfg *JsonConfig) HandlerInferJsonSchema(w http.ResponseWriter, r *http.Request) {
+	fileContents := r.Context().Value(FileContentContextKey)
+
+	response := InferredSchemasResponse{
+		File:      jsonschema.Infer(fileContents),
+		Resources: map[string]map[string]any{},
+	}
+	if fileContentsMap, ok := fileContents.(map[string]any); ok {
+		for resource, value := range fileContentsMap {
+			response.Resources[resource] = jsonschema.Infer(value)
+		}
+	}
+	respondWithETag(w, r, http.StatusOK, response)
+}
+
+// HandlerUpdateJsonSchema attaches a schema to the file, or to a resource when the route has one.
+// The current contents must already match the schema, so that every later write can be validated against it.
+func (cfg *JsonConfig) HandlerUpdateJsonSchema(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+	fileContents := r.Context().Value(FileContentContextKey)
+	resource := chi.URLParam(r, "resource")
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
+		return
+	}
+	defer r.Body.Close()
+
+	schema, err := jsonschema.Parse(body)
+	if err != nil {
+		var schemaErr *jsonschema.SchemaError
+		if errors.As(err, &schemaErr) {
+			utils.RespondWithErrorDetails(w, http.StatusBadRequest, "invalid json schema", schemaErr, err)
+			return
+		}
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid json schema", err)
+		return
+	}
+	if validationErrors := validateWithSchema(schema, resource, fileContents); len(validationErrors) > 0 {
+		utils.RespondWithErrorDetails(w, http.StatusUnprocessableEntity, "json does not match schema", validationErrors, nil)
+		return
+	}
+	schemaJson, err := json.Marshal(schema)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error encoding json schema", err)
+		return
+	}
+
+	savedSchema, err := cfg.Db.UpsertJsonSchema(r.Context(), database.UpsertJsonSchemaParams{
+		JsonFileID: fileMetadata.ID,
+		Resource:   resource,
+		Schema:     schemaJson,
+	})
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error saving json schema", err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusOK, savedSchema.Schema)
+}
+
+func (cfg *JsonConfig) HandlerDeleteJsonSchema(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+
+	err := cfg.Db.DeleteJsonSchema(r.Context(), database.DeleteJsonSchemaParams{
+		JsonFileID: fileMetadata.ID,
+		Resource:   chi.URLParam(r, "resource"),
+	})
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error deleting json schema", err)
+		return
+	}
+	utils.RespondWithJSON(w, http.StatusNoContent, nil)
+}
+
 func (cfg *JsonConfig) HandlerDeleteJsonFile(w http.ResponseWriter, r *http.Request) {
 	userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
 	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+	if !checkIfMatch(w, r, r.Context().Value(FileContentContextKey)) {
+		return
+	}
 
 	err := cfg.Db.DeleteJsonFile(r.Context(), fileMetadata.ID)
 	if err != nil {
 		utils.RespondWithError(w, http.StatusInternalServerError, "error deleting json file from db", err)
 		return
 	}
-	if err := s3util.DeleteFileFromS3(r.Context(), cfg.S3Client, cfg.Rdb, cfg.S3Bucket, userId, fileMetadata.ID); err != nil {
-		utils.RespondWithError(w, http.StatusInternalServerError, "error deleting json file from s3", err)
+	if err := storage.DeleteJson(r.Context(), cfg.Store, cfg.Rdb, userId, fileMetadata.ID); err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error deleting json file from storage", err)
 		return
 	}
 	utils.RespondWithJSON(w, http.StatusNoContent, nil)
 }
 
 func (cfg *JsonConfig) HandlerGetDynamicRoutes(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
 	fileContents, ok := r.Context().Value(FileContentContextKey).(map[string]any)
 	if !ok {
 		utils.RespondWithError(w, http.StatusBadRequest, "json file is not a map", nil)
 		return
 	}
 	routes := []Route{}
-	for key, val := range fileContents {
-		if strings.Contains(key, " ") {
+	for _, key := range routeKeys(fileContents) {
+		routes = append(routes, resourceRoutes(fileMetadata, fmt.Sprintf("/%s", key), key, fileContents[key], 0)...)
+	}
+	for _, relation := range detectRelations(fileMetadata, fileContents) {
+		routes = append(routes, childRoutes(fileMetadata, relation)...)
+	}
+	utils.RespondWithJSON(w, http.StatusOK, routes)
+}
+
+// childRoutes lists the routes of the children of a parent item, for example /posts/:id/comments
+func childRoutes(fileMetadata database.JsonFile, relation relation) []Route {
+	url := fmt.Sprintf("/%s/:%s/%s", relation.parent, resourceIdField(fileMetadata, relation.parent), relation.child)
+	childIdField := resourceIdField(fileMetadata, relation.child)
+	return []Route{
+		{
+			Method:      "GET",
+			Url:         url,
+			Description: fmt.Sprintf("Gets the %s whose %s matches", relation.child, relation.foreignKey),
+		},
+		{
+			Method:      "POST",
+			Url:         url,
+			Description: fmt.Sprintf("Creates a new resource in %s with %s set", relation.child, relation.foreignKey),
+		},
+		{
+			Method:      "GET",
+			Url:         fmt.Sprintf("%s/:%s", url, childIdField),
+			Description: fmt.Sprintf("Gets a resource from %s by %s", relation.child, childIdField),
+		},
+		{
+			Method:      "PUT",
+			Url:         fmt.Sprintf("%s/:%s", url, childIdField),
+			Description: fmt.Sprintf("Replaces a resource from %s with %s", relation.child, childIdField),
+		},
+		{
+			Method:      "PATCH",
+			Url:         fmt.Sprintf("%s/:%s", url, childIdField),
+			Description: fmt.Sprintf("Partially updates a resource from %s with %s", relation.child, childIdField),
+		},
+		{
+			Method:      "DELETE",
+			Url:         fmt.Sprintf("%s/:%s", url, childIdField),
+			Description: fmt.Sprintf("Deletes a resource from %s with %s", relation.child, childIdField),
+		},
+	}
+}
+
+// maxRouteDepth limits how deep nested resources are listed, so large files do not produce endless routes
+const maxRouteDepth = 3
+
+// routeKeys returns the sorted keys of an object that can be used in a url
+func routeKeys(object map[string]any) []string {
+	keys := []string{}
+	for key := range object {
+		if strings.Contains(key, " ") || strings.Contains(key, "/") {
 			// skip keys with spaces, for example "hello world"
 			// since this results in invalid url
 			continue
 		}
-		switch val.(type) {
-		case map[string]any:
-			routes = append(routes, Route{
-				Method:      "GET",
-				Url:         fmt.Sprintf("/%s", key),
-				Description: "Gets the entire resource",
-			})
-			routes = append(routes, Route{
-				Method:      "PUT",
-				Url:         fmt.Sprintf("/%s", key),
-				Description: "Replaces the entire resource",
-			})
-			routes = append(routes, Route{
-				Method:      "PATCH",
-				Url:         fmt.Sprintf("/%s", key),
-				Description: "Partially updates the resource",
-			})
-		case []any:
-			routes = append(routes, Route{
-				Method:      "GET",
-				Url:         fmt.Sprintf("/%s", key),
-				Description: "Gets the entire resource array",
-			})
-			routes = append(routes, Route{
-				Method:      "GET",
-				Url:         fmt.Sprintf("/%s/:id", key),
-				Description: "Gets a resource from resource array by id",
-			})
-			routes = append(routes, Route{
-				Method:      "POST",
-				Url:         fmt.Sprintf("/%s", key),
-				Description: "Creates a new resource and adds it to the resource array",
-			})
-			routes = append(routes, Route{
-				Method:      "PUT",
-				Url:         fmt.Sprintf("/%s/:id", key),
-				Description: "Replaces a resource from resource array with id",
-			})
-			routes = append(routes, Route{
-				Method:      "PATCH",
-				Url:         fmt.Sprintf("/%s/:id", key),
-				Description: "Partially updates a resource from resource array with id",
-			})
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// resourceRoutes lists the routes of the value at url, followed by the routes of the resources nested in it.
+// depth is 0 for top level resources, which cannot be deleted.
+func resourceRoutes(fileMetadata database.JsonFile, url string, key string, val any, depth int) []Route {
+	routes := []Route{}
+	switch val := val.(type) {
+	case map[string]any:
+		routes = append(routes, Route{
+			Method:      "GET",
+			Url:         url,
+			Description: "Gets the entire resource",
+		})
+		routes = append(routes, Route{
+			Method:      "PUT",
+			Url:         url,
+			Description: "Replaces the entire resource",
+		})
+		routes = append(routes, Route{
+			Method:      "PATCH",
+			Url:         url,
+			Description: "Partially updates the resource",
+		})
+		if depth > 0 {
 			routes = append(routes, Route{
 				Method:      "DELETE",
-				Url:         fmt.Sprintf("/%s/:id", key),
-				Description: "Deletes a resource from resource array with id",
-			})
-		case string, int, float64, bool, nil:
-			routes = append(routes, Route{
-				Method:      "GET",
-				Url:         fmt.Sprintf("/%s", key),
-				Description: "Gets the entire resource",
+				Url:         url,
+				Description: "Deletes the resource",
 			})
+		}
+		if depth < maxRouteDepth {
+			for _, childKey := range routeKeys(val) {
+				routes = append(routes, resourceRoutes(fileMetadata, fmt.Sprintf("%s/%s", url, childKey), childKey, val[childKey], depth+1)...)
+			}
+		}
+	case []any:
+		idField := resourceIdField(fileMetadata, key)
+		if len(val) > 0 && !hasIds(val) {
+			// items without ids are addressed by their position in the array
+			idField = "index"
+		}
+		routes = append(routes, Route{
+			Method:      "GET",
+			Url:         url,
+			Description: "Gets the entire resource array",
+		})
+		routes = append(routes, Route{
+			Method:      "GET",
+			Url:         fmt.Sprintf("%s/:%s", url, idField),
+			Description: fmt.Sprintf("Gets a resource from resource array by %s", idField),
+		})
+		routes = append(routes, Route{
+			Method:      "POST",
+			Url:         url,
+			Description: "Creates a new resource and adds it to the resource array",
+		})
+		routes = append(routes, Route{
+			Method:      "PUT",
+			Url:         fmt.Sprintf("%s/:%s", url, idField),
+			Description: fmt.Sprintf("Replaces a resource from resource array with %s", idField),
+		})
+		routes = append(routes, Route{
+			Method:      "PATCH",
+			Url:         fmt.Sprintf("%s/:%s", url, idField),
+			Description: fmt.Sprintf("Partially updates a resource from resource array with %s", idField),
+		})
+		routes = append(routes, Route{
+			Method:      "DELETE",
+			Url:         fmt.Sprintf("%s/:%s", url, idField),
+			Description: fmt.Sprintf("Deletes a resource from resource array with %s", idField),
+		})
+		if depth < maxRouteDepth {
+			// nested objects and arrays of items, using the first item that has the field as an example
+			nested := map[string]any{}
+			for _, item := range val {
+				itemMap, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				for childKey, childVal := range itemMap {
+					if _, seen := nested[childKey]; seen {
+						continue
+					}
+					switch childVal.(type) {
+					case map[string]any, []any:
+						nested[childKey] = childVal
+					}
+				}
+			}
+			for _, childKey := range routeKeys(nested) {
+				childUrl := fmt.Sprintf("%s/:%s/%s", url, idField, childKey)
+				routes = append(routes, resourceRoutes(fileMetadata, childUrl, childKey, nested[childKey], depth+1)...)
+			}
+		}
+	case string, int, float64, bool, nil:
+		routes = append(routes, Route{
+			Method:      "GET",
+			Url:         url,
+			Description: "Gets the entire resource",
+		})
+		routes = append(routes, Route{
+			Method:      "PUT",
+			Url:         url,
+			Description: "Replaces the entire resource",
+		})
+		if depth > 0 {
 			routes = append(routes, Route{
-				Method:      "PUT",
-				Url:         fmt.Sprintf("/%s", key),
-				Description: "Replaces the entire resource",
+				Method:      "DELETE",
+				Url:         url,
+				Description: "Deletes the resource",
 			})
 		}
 	}
-	utils.RespondWithJSON(w, http.StatusOK, routes)
+	return routes
 }
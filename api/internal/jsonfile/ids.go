@@ -0,0 +1,109 @@
+package jsonfile
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/pl3lee/restjson/internal/database"
+)
+
+const defaultIdField = "id"
+
+// id strategies decide how ids are generated for new resource items that do not have one
+const (
+	IdStrategyIncrement = "increment"
+	IdStrategyUUID      = "uuid"
+)
+
+func isValidIdStrategy(strategy string) bool {
+	return strategy == IdStrategyIncrement || strategy == IdStrategyUUID
+}
+
+// resourceIdField returns the field that identifies items of the given resource.
+// A per resource setting takes precedence over the setting of the file.
+func resourceIdField(fileMetadata database.JsonFile, resource string) string {
+	resourceIdFields := map[string]string{}
+	if err := json.Unmarshal(fileMetadata.ResourceIdFields, &resourceIdFields); err == nil {
+		if idField := resourceIdFields[resource]; idField != "" {
+			return idField
+		}
+	}
+	if fileMetadata.IdField != "" {
+		return fileMetadata.IdField
+	}
+	return defaultIdField
+}
+
+// generateId creates an id for a new item in items.
+// Incrementing ids continue from the largest integer id already in the array.
+func generateId(items []any, idField string, strategy string) any {
+	if strategy == IdStrategyUUID {
+		return uuid.NewString()
+	}
+
+	maxId := 0
+	for _, item := range items {
+		itemMap, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		switch id := itemMap[idField].(type) {
+		case float64:
+			maxId = max(maxId, int(id))
+		case string:
+			if number, err := strconv.Atoi(id); err == nil {
+				maxId = max(maxId, number)
+			}
+		}
+	}
+	return maxId + 1
+}
+
+// assignId makes sure newItem has an id that is not used by any of items, generating one if it is missing.
+// It returns the id of the new item, or false if the id given by the client is already taken.
+func assignId(items []any, newItem map[string]any, idField string, strategy string) (string, bool) {
+	if newItem[idField] == nil {
+		newItem[idField] = generateId(items, idField, strategy)
+	} else if findItemIndex(items, idField, stringifyValue(newItem[idField])) != -1 {
+		return "", false
+	}
+	return stringifyValue(newItem[idField]), true
+}
+
+// findItemIndex returns the index of the item with the given id, or -1 if there is none
+func findItemIndex(items []any, idField string, id string) int {
+	for index, item := range items {
+		itemMap, ok := item.(map[string]any)
+		if !ok {
+			continue
+		}
+		itemId, ok := itemMap[idField]
+		if ok && itemId != nil && stringifyValue(itemId) == id {
+			return index
+		}
+	}
+	return -1
+}
+
+// keepItemId makes sure updatedItem, which replaces items[index], has an id that no other item uses.
+// An item without an id keeps the id of the item it replaces, since removing it would make the item unreachable.
+// It returns false if the id belongs to another item.
+func keepItemId(items []any, index int, updatedItem map[string]any, idField string) bool {
+	if updatedItem[idField] == nil {
+		if currentId, ok := items[index].(map[string]any)[idField]; ok {
+			updatedItem[idField] = currentId
+		}
+		return true
+	}
+	for i, item := range items {
+		itemMap, ok := item.(map[string]any)
+		if i == index || !ok || itemMap[idField] == nil {
+			continue
+		}
+		if stringifyValue(itemMap[idField]) == stringifyValue(updatedItem[idField]) {
+			return false
+		}
+	}
+	return true
+}
@@ -1,14 +1,21 @@
 package jsonfile
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/pl3lee/restjson/internal/auth"
 	"github.com/pl3lee/restjson/internal/database"
-	"github.com/pl3lee/restjson/internal/s3util"
+	"github.com/pl3lee/restjson/internal/filelock"
+	"github.com/pl3lee/restjson/internal/storage"
 	"github.com/pl3lee/restjson/internal/utils"
 )
 
@@ -18,6 +25,14 @@ const FileMetadataContextKey contextKey = "fileId"
 const FileContentContextKey contextKey = "fileContent"
 const ResourceDataContextKey contextKey = "resourceData"
 const ResourceArrayContextKey contextKey = "resourceArray"
+const MaxFileSizeContextKey contextKey = "maxFileSize"
+
+const (
+	// fileLockTTL is how long a write lock is held at most, in case the request never releases it
+	fileLockTTL = 60 * time.Second
+	// fileLockWait is how long a write waits for other writes to the same file to finish
+	fileLockWait = 10 * time.Second
+)
 
 // JsonFileMiddleware ensures that the user has access to the requested JSON file.
 // This middleware depends on the authMiddleware to run first, which sets the user ID in the context.
@@ -49,11 +64,77 @@ func (cfg *JsonConfig) JsonFileMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// FileWriteLockMiddleware serializes writes to the same JSON file, so that concurrent requests
+// do not overwrite each other's changes. It must run before JsonFileContentMiddleware,
+// so that the file contents are read while holding the lock.
+func (cfg *JsonConfig) FileWriteLockMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+
+		ctx, cancel := context.WithTimeout(r.Context(), fileLockWait)
+		defer cancel()
+		lock, err := filelock.Acquire(ctx, cfg.Rdb, fmt.Sprintf("lock:json:%s", fileMetadata.ID.String()), fileLockTTL)
+		if errors.Is(err, filelock.ErrNotAcquired) {
+			utils.RespondWithError(w, http.StatusServiceUnavailable, "json file is busy, try again later", err)
+			return
+		}
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "error locking json file", err)
+			return
+		}
+		defer func() {
+			// the request context might already be cancelled, but the lock should still be released
+			if err := lock.Release(context.Background()); err != nil {
+				log.Printf("error releasing lock for json file %s: %v", fileMetadata.ID, err)
+			}
+		}()
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// MaxFileSizeMiddleware limits request bodies to the maximum size of a json file on the plan of the user,
+// responding with 413 to larger requests. The limit is added to the context, for the check of the updated contents.
+func (cfg *JsonConfig) MaxFileSizeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+		userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
+
+		maxSize, err := cfg.maxFileSize(r.Context(), userId)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusInternalServerError, "error getting maximum file size", err)
+			return
+		}
+		// read the whole body here, so that handlers do not need to tell a body that is too large from invalid json
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSize))
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondWithFileSizeError(w, &fileSizeError{Limit: maxSize})
+			return
+		}
+		if err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "error reading request body", err)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := context.WithValue(r.Context(), MaxFileSizeContextKey, maxSize)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
 func (cfg *JsonConfig) JsonFileContentMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
 		fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
-		fileContents, err := s3util.GetJsonFromS3(r.Context(), cfg.S3Client, cfg.Rdb, cfg.S3Bucket, userId, fileMetadata.ID)
+		fileContents, err := storage.GetJson(r.Context(), cfg.Store, cfg.Rdb, userId, fileMetadata.ID)
 		if err != nil {
 			utils.RespondWithError(w, http.StatusInternalServerError, "error getting json from s3", err)
 			return
@@ -85,6 +166,11 @@ func (cfg *JsonConfig) ResourceArrayMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		items, ok := r.Context().Value(ResourceDataContextKey).([]any)
 		if !ok {
+			if chi.URLParam(r, "id") != "" {
+				// for resources that are objects, /{resource}/{id} refers to a field of the object
+				cfg.HandlerResourcePath(w, r)
+				return
+			}
 			utils.RespondWithError(w, http.StatusBadRequest, "resource is not an array", nil)
 			return
 		}
@@ -0,0 +1,66 @@
+package jsonfile
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+func TestConcurrentCreatesAreNotLost(t *testing.T) {
+	s := newTestServer(t, `{"posts": []}`)
+
+	const requests = 200
+	var wg sync.WaitGroup
+	statuses := make(chan int, requests)
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rec := s.do(t, http.MethodPost, fmt.Sprintf("/public/%s/posts", s.fileId), map[string]any{"title": fmt.Sprintf("post %d", i)})
+			statuses <- rec.Code
+		}()
+	}
+	wg.Wait()
+	close(statuses)
+	for status := range statuses {
+		if status != http.StatusCreated {
+			t.Errorf("got status %d, want %d", status, http.StatusCreated)
+		}
+	}
+
+	posts := s.contents(t)["posts"].([]any)
+	if len(posts) != requests {
+		t.Fatalf("got %d posts, want %d", len(posts), requests)
+	}
+	ids := map[float64]bool{}
+	titles := map[string]bool{}
+	for _, post := range posts {
+		post := post.(map[string]any)
+		ids[post["id"].(float64)] = true
+		titles[post["title"].(string)] = true
+	}
+	if len(ids) != requests {
+		t.Errorf("got %d distinct ids, want %d", len(ids), requests)
+	}
+	if len(titles) != requests {
+		t.Errorf("got %d distinct titles, want %d", len(titles), requests)
+	}
+	if revisions := s.db.fileRevisions(s.fileId); len(revisions) != requests {
+		t.Errorf("got %d revisions, want %d", len(revisions), requests)
+	}
+	if keys := s.redis.Keys(); len(keys) != 1 {
+		// only the cached contents are left, every lock was released
+		t.Errorf("got redis keys %v, want only the cached contents", keys)
+	}
+}
+
+func TestReadsDoNotWaitForWriteLock(t *testing.T) {
+	s := newTestServer(t, `{"posts": []}`)
+	s.redis.Set(fmt.Sprintf("lock:json:%s", s.fileId), "held by another request")
+
+	// reads do not wait for the lock
+	if rec := s.do(t, http.MethodGet, fmt.Sprintf("/public/%s/posts", s.fileId), nil); rec.Code != http.StatusOK {
+		t.Fatalf("got status %d for a read, want %d", rec.Code, http.StatusOK)
+	}
+}
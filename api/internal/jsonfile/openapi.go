@@ -0,0 +1,708 @@
+package jsonfile
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"github.com/pl3lee/restjson/internal/database"
+	"github.com/pl3lee/restjson/internal/jsonschema"
+	"github.com/pl3lee/restjson/internal/utils"
+	"github.com/pl3lee/restjson/internal/yamlutil"
+)
+
+const openAPIVersion = "3.1.0"
+
+// maxListExamples is the number of real items used as the example of a list response
+const maxListExamples = 3
+
+type OpenAPIDocument struct {
+	OpenAPI    string                     `json:"openapi"`
+	Info       OpenAPIInfo                `json:"info"`
+	Servers    []OpenAPIServer            `json:"servers"`
+	Security   []map[string][]string      `json:"security"`
+	Tags       []OpenAPITag               `json:"tags,omitempty"`
+	Paths      map[string]OpenAPIPathItem `json:"paths"`
+	Components OpenAPIComponents          `json:"components"`
+}
+
+type OpenAPIInfo struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type OpenAPIServer struct {
+	Url string `json:"url"`
+}
+
+type OpenAPITag struct {
+	Name string `json:"name"`
+}
+
+type OpenAPIPathItem struct {
+	Parameters []OpenAPIParameter `json:"parameters,omitempty"`
+	Get        *OpenAPIOperation  `json:"get,omitempty"`
+	Post       *OpenAPIOperation  `json:"post,omitempty"`
+	Put        *OpenAPIOperation  `json:"put,omitempty"`
+	Patch      *OpenAPIOperation  `json:"patch,omitempty"`
+	Delete     *OpenAPIOperation  `json:"delete,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	OperationId string                     `json:"operationId"`
+	Summary     string                     `json:"summary"`
+	Tags        []string                   `json:"tags,omitempty"`
+	Parameters  []OpenAPIParameter         `json:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]OpenAPIResponse `json:"responses"`
+}
+
+type OpenAPIParameter struct {
+	Ref         string         `json:"$ref,omitempty"`
+	Name        string         `json:"name,omitempty"`
+	In          string         `json:"in,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Required    bool           `json:"required,omitempty"`
+	Schema      map[string]any `json:"schema,omitempty"`
+}
+
+type OpenAPIRequestBody struct {
+	Required bool                        `json:"required"`
+	Content  map[string]OpenAPIMediaType `json:"content"`
+}
+
+type OpenAPIResponse struct {
+	Ref         string                      `json:"$ref,omitempty"`
+	Description string                      `json:"description,omitempty"`
+	Headers     map[string]OpenAPIHeader    `json:"headers,omitempty"`
+	Content     map[string]OpenAPIMediaType `json:"content,omitempty"`
+}
+
+type OpenAPIHeader struct {
+	Description string         `json:"description"`
+	Schema      map[string]any `json:"schema"`
+}
+
+type OpenAPIMediaType struct {
+	Schema  map[string]any `json:"schema"`
+	Example any            `json:"example,omitempty"`
+}
+
+type OpenAPIComponents struct {
+	Schemas         map[string]any                   `json:"schemas"`
+	Parameters      map[string]OpenAPIParameter      `json:"parameters"`
+	Responses       map[string]OpenAPIResponse       `json:"responses"`
+	SecuritySchemes map[string]OpenAPISecurityScheme `json:"securitySchemes"`
+}
+
+type OpenAPISecurityScheme struct {
+	Type        string `json:"type"`
+	Scheme      string `json:"scheme"`
+	Description string `json:"description"`
+}
+
+func (cfg *JsonConfig) HandlerGetOpenAPIJson(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+	fileContents := r.Context().Value(FileContentContextKey)
+
+	respondWithETag(w, r, http.StatusOK, cfg.openAPIDocument(fileMetadata, fileContents))
+}
+
+func (cfg *JsonConfig) HandlerGetOpenAPIYaml(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+	fileContents := r.Context().Value(FileContentContextKey)
+
+	data, err := yamlutil.Marshal(cfg.openAPIDocument(fileMetadata, fileContents))
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error encoding openapi document", err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// openAPIDocument describes the public api of a json file, with schemas and examples taken from its contents
+func (cfg *JsonConfig) openAPIDocument(fileMetadata database.JsonFile, fileContents any) OpenAPIDocument {
+	fileSchema := inferSchema(fileContents)
+	doc := OpenAPIDocument{
+		OpenAPI: openAPIVersion,
+		Info: OpenAPIInfo{
+			Title:       fileMetadata.FileName,
+			Description: fmt.Sprintf("Generated by RestJSON from the contents of %s", fileMetadata.FileName),
+			Version:     fileMetadata.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		},
+		Servers: []OpenAPIServer{
+			{Url: fmt.Sprintf("%s/public/%s", cfg.BaseURL, fileMetadata.ID)},
+		},
+		Security: []map[string][]string{
+			{"bearerAuth": {}},
+		},
+		Paths: map[string]OpenAPIPathItem{},
+		Components: OpenAPIComponents{
+			Schemas: map[string]any{
+				"File":      fileSchema,
+				"Error":     errorSchema(),
+				"JsonPatch": jsonPatchSchema(),
+			},
+			Parameters: queryParameters(),
+			Responses: map[string]OpenAPIResponse{
+				"BadRequest":         errorResponse("Invalid request or query parameter"),
+				"NotFound":           errorResponse("Resource not found"),
+				"Conflict":           errorResponse("Conflicting id or reference"),
+				"PreconditionFailed": errorResponse("If-Match does not match the current ETag"),
+				"UnprocessableEntity": errorResponse(
+					"The body does not match the schema of the file, or references items that do not exist",
+				),
+			},
+			SecuritySchemes: map[string]OpenAPISecurityScheme{
+				"bearerAuth": {
+					Type:        "http",
+					Scheme:      "bearer",
+					Description: "An API key created in the RestJSON dashboard",
+				},
+			},
+		},
+	}
+
+	fileContentsMap, ok := fileContents.(map[string]any)
+	if !ok {
+		return doc
+	}
+	names := map[string]bool{"File": true, "Error": true, "JsonPatch": true}
+	// names of the item schemas of array resources, used again by the routes of their children
+	itemSchemaNames := map[string]string{}
+	inputSchemaNames := map[string]string{}
+	for _, resource := range routeKeys(fileContentsMap) {
+		doc.Tags = append(doc.Tags, OpenAPITag{Name: resource})
+		value := fileContentsMap[resource]
+		items, isArray := value.([]any)
+		if !isArray {
+			schemaName := uniqueSchemaName(names, pascalCase(resource))
+			doc.Components.Schemas[schemaName] = inferSchema(value)
+			addObjectPaths(doc.Paths, resource, schemaName, value)
+			continue
+		}
+
+		schemaName := uniqueSchemaName(names, pascalCase(singularize(resource)))
+		itemSchema := itemsSchema(items)
+		doc.Components.Schemas[schemaName] = itemSchema
+		idField := resourceIdField(fileMetadata, resource)
+		inputName := uniqueSchemaName(names, schemaName+"Input")
+		doc.Components.Schemas[inputName] = inputSchema(itemSchema, idField)
+		itemSchemaNames[resource] = schemaName
+		inputSchemaNames[resource] = inputName
+		addArrayPaths(doc.Paths, fileMetadata, resource, schemaName, inputName, itemSchema, items)
+	}
+
+	for _, relation := range detectRelations(fileMetadata, fileContentsMap) {
+		addChildPaths(doc.Paths, fileMetadata, fileContentsMap, relation, itemSchemaNames[relation.child], inputSchemaNames[relation.child])
+	}
+	return doc
+}
+
+func addObjectPaths(paths map[string]OpenAPIPathItem, resource string, schemaName string, example any) {
+	name := pascalCase(resource)
+	paths["/"+resource] = OpenAPIPathItem{
+		Get: &OpenAPIOperation{
+			OperationId: "get" + name,
+			Summary:     fmt.Sprintf("Gets %s", resource),
+			Tags:        []string{resource},
+			Responses: map[string]OpenAPIResponse{
+				"200": jsonResponse(fmt.Sprintf("The value of %s", resource), refSchema(schemaName), example),
+				"404": refResponse("NotFound"),
+			},
+		},
+		Put: &OpenAPIOperation{
+			OperationId: "replace" + name,
+			Summary:     fmt.Sprintf("Replaces %s", resource),
+			Tags:        []string{resource},
+			RequestBody: jsonRequestBody(refSchema(schemaName), example),
+			Responses:   writeResponses(fmt.Sprintf("The updated value of %s", resource), refSchema(schemaName)),
+		},
+		Patch: &OpenAPIOperation{
+			OperationId: "update" + name,
+			Summary:     fmt.Sprintf("Partially updates %s", resource),
+			Tags:        []string{resource},
+			RequestBody: patchRequestBody(refSchema(schemaName)),
+			Responses:   writeResponses(fmt.Sprintf("The updated value of %s", resource), refSchema(schemaName)),
+		},
+	}
+}
+
+func addArrayPaths(paths map[string]OpenAPIPathItem, fileMetadata database.JsonFile, resource string, schemaName string, inputName string, itemSchema map[string]any, items []any) {
+	idField := resourceIdField(fileMetadata, resource)
+	singular := pascalCase(singularize(resource))
+	plural := pascalCase(resource)
+	if singular == plural {
+		singular += "Item"
+	}
+
+	var itemExample any
+	if len(items) > 0 {
+		itemExample = items[0]
+	}
+	listParameters := append(filterParameters(itemSchema), commonListParameters()...)
+
+	paths["/"+resource] = OpenAPIPathItem{
+		Get: &OpenAPIOperation{
+			OperationId: "list" + plural,
+			Summary:     fmt.Sprintf("Lists %s, with optional filters, sorting and pagination", resource),
+			Tags:        []string{resource},
+			Parameters:  listParameters,
+			Responses: map[string]OpenAPIResponse{
+				"200": listResponse(resource, schemaName, items[:min(len(items), maxListExamples)]),
+				"400": refResponse("BadRequest"),
+			},
+		},
+		Post: &OpenAPIOperation{
+			OperationId: "create" + singular,
+			Summary:     fmt.Sprintf("Creates an item in %s, generating its %s when it is missing", resource, idField),
+			Tags:        []string{resource},
+			RequestBody: jsonRequestBody(refSchema(inputName), withoutField(itemExample, idField)),
+			Responses:   createResponses(schemaName, itemExample),
+		},
+	}
+
+	itemPath := fmt.Sprintf("/%s/{%s}", resource, idField)
+	paths[itemPath] = OpenAPIPathItem{
+		Parameters: []OpenAPIParameter{idParameter(idField, propertySchema(itemSchema, idField))},
+		Get: &OpenAPIOperation{
+			OperationId: "get" + singular,
+			Summary:     fmt.Sprintf("Gets an item of %s by %s", resource, idField),
+			Tags:        []string{resource},
+			Parameters: []OpenAPIParameter{
+				{Ref: "#/components/parameters/embed"},
+				{Ref: "#/components/parameters/expand"},
+			},
+			Responses: map[string]OpenAPIResponse{
+				"200": jsonResponse(fmt.Sprintf("The item of %s", resource), refSchema(schemaName), itemExample),
+				"404": refResponse("NotFound"),
+			},
+		},
+		Put: &OpenAPIOperation{
+			OperationId: "replace" + singular,
+			Summary:     fmt.Sprintf("Replaces an item of %s", resource),
+			Tags:        []string{resource},
+			RequestBody: jsonRequestBody(refSchema(schemaName), itemExample),
+			Responses:   writeResponses("The updated item", refSchema(schemaName)),
+		},
+		Patch: &OpenAPIOperation{
+			OperationId: "update" + singular,
+			Summary:     fmt.Sprintf("Partially updates an item of %s", resource),
+			Tags:        []string{resource},
+			RequestBody: patchRequestBody(refSchema(inputName)),
+			Responses:   writeResponses("The updated item", refSchema(schemaName)),
+		},
+		Delete: &OpenAPIOperation{
+			OperationId: "delete" + singular,
+			Summary:     fmt.Sprintf("Deletes an item of %s", resource),
+			Tags:        []string{resource},
+			Responses:   deleteResponses(),
+		},
+	}
+}
+
+// addChildPaths adds the routes of the children of a parent item, such as /posts/{postId}/comments
+func addChildPaths(paths map[string]OpenAPIPathItem, fileMetadata database.JsonFile, fileContents map[string]any, relation relation, schemaName string, inputName string) {
+	parentIdField := resourceIdField(fileMetadata, relation.parent)
+	childIdField := resourceIdField(fileMetadata, relation.child)
+	parentItems := fileContents[relation.parent].([]any)
+	children := fileContents[relation.child].([]any)
+	childSchema := itemsSchema(children)
+	prefix := pascalCase(singularize(relation.parent))
+
+	parentParameter := idParameter(relation.foreignKey, propertySchema(itemsSchema(parentItems), parentIdField))
+	parentParameter.Description = fmt.Sprintf("The %s of the item of %s", parentIdField, relation.parent)
+
+	var childExample any
+	if len(children) > 0 {
+		childExample = children[0]
+	}
+	collectionPath := fmt.Sprintf("/%s/{%s}/%s", relation.parent, relation.foreignKey, relation.child)
+	paths[collectionPath] = OpenAPIPathItem{
+		Parameters: []OpenAPIParameter{parentParameter},
+		Get: &OpenAPIOperation{
+			OperationId: fmt.Sprintf("list%s%s", prefix, pascalCase(relation.child)),
+			Summary:     fmt.Sprintf("Lists the %s whose %s matches", relation.child, relation.foreignKey),
+			Tags:        []string{relation.child},
+			Parameters:  append(filterParameters(childSchema), commonListParameters()...),
+			Responses: map[string]OpenAPIResponse{
+				"200": listResponse(relation.child, schemaName, children[:min(len(children), maxListExamples)]),
+				"400": refResponse("BadRequest"),
+				"404": refResponse("NotFound"),
+			},
+		},
+		Post: &OpenAPIOperation{
+			OperationId: fmt.Sprintf("create%s%s", prefix, schemaName),
+			Summary:     fmt.Sprintf("Creates an item in %s with %s set", relation.child, relation.foreignKey),
+			Tags:        []string{relation.child},
+			RequestBody: jsonRequestBody(refSchema(inputName), withoutField(withoutField(childExample, childIdField), relation.foreignKey)),
+			Responses:   createResponses(schemaName, childExample),
+		},
+	}
+}
+
+// inferSchema infers the schema of a value, for use inside the document
+func inferSchema(value any) map[string]any {
+	schema := jsonschema.Infer(value)
+	delete(schema, "$schema")
+	return schema
+}
+
+// itemsSchema infers the schema of the items of an array
+func itemsSchema(items []any) map[string]any {
+	schema, ok := inferSchema(items)["items"].(map[string]any)
+	if !ok {
+		return map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	if _, ok := schema["properties"]; !ok && schema["type"] == "object" {
+		schema["properties"] = map[string]any{}
+	}
+	return schema
+}
+
+// inputSchema is the schema of new items, whose id can be left out
+func inputSchema(itemSchema map[string]any, idField string) map[string]any {
+	input := map[string]any{}
+	for key, value := range itemSchema {
+		input[key] = value
+	}
+	if required, ok := itemSchema["required"].([]any); ok {
+		inputRequired := []any{}
+		for _, field := range required {
+			if field != idField {
+				inputRequired = append(inputRequired, field)
+			}
+		}
+		if len(inputRequired) > 0 {
+			input["required"] = inputRequired
+		} else {
+			delete(input, "required")
+		}
+	}
+	return input
+}
+
+// propertySchema returns the schema of a property of an object schema, or a string schema when it is unknown
+func propertySchema(schema map[string]any, property string) map[string]any {
+	properties, _ := schema["properties"].(map[string]any)
+	if propertySchema, ok := properties[property].(map[string]any); ok {
+		return propertySchema
+	}
+	return map[string]any{"type": "string"}
+}
+
+func idParameter(name string, idSchema map[string]any) OpenAPIParameter {
+	return OpenAPIParameter{
+		Name:     name,
+		In:       "path",
+		Required: true,
+		Schema:   idSchema,
+	}
+}
+
+// filterParameters lists the query parameters that filter items by the fields of their schema
+func filterParameters(itemSchema map[string]any) []OpenAPIParameter {
+	parameters := []OpenAPIParameter{}
+	properties, _ := itemSchema["properties"].(map[string]any)
+	for _, field := range routeKeys(properties) {
+		property, _ := properties[field].(map[string]any)
+		types := schemaTypes(property)
+		switch {
+		case types["array"]:
+			parameters = append(parameters, OpenAPIParameter{
+				Name:        field + "_contains",
+				In:          "query",
+				Description: fmt.Sprintf("Items whose %s contains the value", field),
+				Schema:      map[string]any{"type": "string"},
+			})
+		case types["object"]:
+			continue
+		default:
+			valueSchema := map[string]any{"type": "string"}
+			if types["number"] || types["integer"] {
+				valueSchema = map[string]any{"type": "number"}
+			}
+			parameters = append(parameters,
+				OpenAPIParameter{
+					Name:        field,
+					In:          "query",
+					Description: fmt.Sprintf("Items whose %s equals the value, repeat to match any of several values", field),
+					Schema:      valueSchema,
+				},
+				OpenAPIParameter{
+					Name:        field + "_ne",
+					In:          "query",
+					Description: fmt.Sprintf("Items whose %s does not equal the value", field),
+					Schema:      valueSchema,
+				},
+			)
+			if types["number"] || types["integer"] || property["format"] == "date" || property["format"] == "date-time" {
+				parameters = append(parameters,
+					OpenAPIParameter{
+						Name:        field + "_gte",
+						In:          "query",
+						Description: fmt.Sprintf("Items whose %s is greater than or equal to the value", field),
+						Schema:      valueSchema,
+					},
+					OpenAPIParameter{
+						Name:        field + "_lte",
+						In:          "query",
+						Description: fmt.Sprintf("Items whose %s is less than or equal to the value", field),
+						Schema:      valueSchema,
+					},
+				)
+			}
+			if types["string"] {
+				parameters = append(parameters, OpenAPIParameter{
+					Name:        field + "_like",
+					In:          "query",
+					Description: fmt.Sprintf("Items whose %s matches the regular expression, ignoring case", field),
+					Schema:      valueSchema,
+				})
+			}
+		}
+	}
+	return parameters
+}
+
+func schemaTypes(schema map[string]any) map[string]bool {
+	types := map[string]bool{}
+	switch t := schema["type"].(type) {
+	case string:
+		types[t] = true
+	case []any:
+		for _, name := range t {
+			if name, ok := name.(string); ok {
+				types[name] = true
+			}
+		}
+	}
+	return types
+}
+
+func commonListParameters() []OpenAPIParameter {
+	parameters := []OpenAPIParameter{}
+	for _, name := range []string{"sort", "order", "page", "limit", "start", "end", "embed", "expand"} {
+		parameters = append(parameters, OpenAPIParameter{Ref: "#/components/parameters/" + name})
+	}
+	return parameters
+}
+
+// queryParameters are the reserved query parameters shared by every list route
+func queryParameters() map[string]OpenAPIParameter {
+	query := func(name string, description string, schema map[string]any) OpenAPIParameter {
+		return OpenAPIParameter{Name: name, In: "query", Description: description, Schema: schema}
+	}
+	stringSchema := map[string]any{"type": "string"}
+	integerSchema := map[string]any{"type": "integer", "minimum": 0}
+	return map[string]OpenAPIParameter{
+		"sort":   query("_sort", "Comma separated fields to sort by, nested fields use dots", stringSchema),
+		"order":  query("_order", "Comma separated asc or desc, one for each field of _sort", stringSchema),
+		"page":   query("_page", "Page number starting at 1, used with _limit", map[string]any{"type": "integer", "minimum": 1}),
+		"limit":  query("_limit", fmt.Sprintf("Number of items per page, %d by default", defaultPageLimit), integerSchema),
+		"start":  query("_start", "Index of the first item to return", integerSchema),
+		"end":    query("_end", "Index after the last item to return", integerSchema),
+		"embed":  query("_embed", "Comma separated child resources to include, such as comments", stringSchema),
+		"expand": query("_expand", "Comma separated parents to include, such as user for userId", stringSchema),
+	}
+}
+
+func refSchema(name string) map[string]any {
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+func refResponse(name string) OpenAPIResponse {
+	return OpenAPIResponse{Ref: "#/components/responses/" + name}
+}
+
+func etagHeader() map[string]OpenAPIHeader {
+	return map[string]OpenAPIHeader{
+		"ETag": {
+			Description: "Strong etag of the returned value, for If-None-Match and If-Match",
+			Schema:      map[string]any{"type": "string"},
+		},
+	}
+}
+
+func jsonResponse(description string, schema map[string]any, example any) OpenAPIResponse {
+	return OpenAPIResponse{
+		Description: description,
+		Headers:     etagHeader(),
+		Content: map[string]OpenAPIMediaType{
+			"application/json": {Schema: schema, Example: example},
+		},
+	}
+}
+
+func listResponse(resource string, schemaName string, example []any) OpenAPIResponse {
+	response := jsonResponse(fmt.Sprintf("The matching items of %s", resource), map[string]any{
+		"type":  "array",
+		"items": refSchema(schemaName),
+	}, example)
+	response.Headers["X-Total-Count"] = OpenAPIHeader{
+		Description: "Number of matching items before pagination",
+		Schema:      map[string]any{"type": "integer"},
+	}
+	response.Headers["Link"] = OpenAPIHeader{
+		Description: "Links to the first, previous, next and last pages",
+		Schema:      map[string]any{"type": "string"},
+	}
+	return response
+}
+
+func errorResponse(description string) OpenAPIResponse {
+	return OpenAPIResponse{
+		Description: description,
+		Content: map[string]OpenAPIMediaType{
+			"application/json": {Schema: refSchema("Error")},
+		},
+	}
+}
+
+func createResponses(schemaName string, example any) map[string]OpenAPIResponse {
+	created := jsonResponse("The created item", refSchema(schemaName), example)
+	created.Headers["Location"] = OpenAPIHeader{
+		Description: "Url of the created item",
+		Schema:      map[string]any{"type": "string"},
+	}
+	return map[string]OpenAPIResponse{
+		"201": created,
+		"400": refResponse("BadRequest"),
+		"409": refResponse("Conflict"),
+		"412": refResponse("PreconditionFailed"),
+		"422": refResponse("UnprocessableEntity"),
+	}
+}
+
+// writeResponses are the responses of writes, which return the value they wrote
+func writeResponses(description string, schema map[string]any) map[string]OpenAPIResponse {
+	return map[string]OpenAPIResponse{
+		"200": {
+			Description: description,
+			Headers:     etagHeader(),
+			Content: map[string]OpenAPIMediaType{
+				"application/json": {Schema: schema},
+			},
+		},
+		"400": refResponse("BadRequest"),
+		"404": refResponse("NotFound"),
+		"409": refResponse("Conflict"),
+		"412": refResponse("PreconditionFailed"),
+		"422": refResponse("UnprocessableEntity"),
+	}
+}
+
+// deleteResponses are the responses of deletes, which return the whole updated file
+func deleteResponses() map[string]OpenAPIResponse {
+	return map[string]OpenAPIResponse{
+		"200": {
+			Description: "The updated file",
+			Content: map[string]OpenAPIMediaType{
+				"application/json": {Schema: refSchema("File")},
+			},
+		},
+		"404": refResponse("NotFound"),
+		"409": refResponse("Conflict"),
+		"412": refResponse("PreconditionFailed"),
+		"422": refResponse("UnprocessableEntity"),
+	}
+}
+
+func jsonRequestBody(schema map[string]any, example any) *OpenAPIRequestBody {
+	return &OpenAPIRequestBody{
+		Required: true,
+		Content: map[string]OpenAPIMediaType{
+			"application/json": {Schema: schema, Example: example},
+		},
+	}
+}
+
+func patchRequestBody(schema map[string]any) *OpenAPIRequestBody {
+	return &OpenAPIRequestBody{
+		Required: true,
+		Content: map[string]OpenAPIMediaType{
+			"application/json":  {Schema: schema},
+			mergePatchMediaType: {Schema: schema},
+			jsonPatchMediaType:  {Schema: refSchema("JsonPatch")},
+		},
+	}
+}
+
+func errorSchema() map[string]any {
+	return map[string]any{
+		"type":     "object",
+		"required": []any{"error"},
+		"properties": map[string]any{
+			"error":   map[string]any{"type": "string"},
+			"details": map[string]any{},
+		},
+	}
+}
+
+func jsonPatchSchema() map[string]any {
+	return map[string]any{
+		"type": "array",
+		"items": map[string]any{
+			"type":     "object",
+			"required": []any{"op", "path"},
+			"properties": map[string]any{
+				"op":    map[string]any{"enum": []any{"add", "remove", "replace", "move", "copy", "test"}},
+				"path":  map[string]any{"type": "string"},
+				"from":  map[string]any{"type": "string"},
+				"value": map[string]any{},
+			},
+		},
+	}
+}
+
+// withoutField returns a copy of an example item without one of its fields
+func withoutField(example any, field string) any {
+	exampleMap, ok := example.(map[string]any)
+	if !ok {
+		return example
+	}
+	result := map[string]any{}
+	for key, value := range exampleMap {
+		if key != field {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// pascalCase turns a resource key like blog_posts or blog-posts into BlogPosts
+func pascalCase(name string) string {
+	var builder strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			builder.WriteRune(unicode.ToUpper(r))
+			upper = false
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	result := builder.String()
+	if result == "" || unicode.IsDigit(rune(result[0])) {
+		result = "Resource" + result
+	}
+	return result
+}
+
+// uniqueSchemaName appends a number to name when another resource already uses it
+func uniqueSchemaName(names map[string]bool, name string) string {
+	unique := name
+	for i := 2; names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	names[unique] = true
+	return unique
+}
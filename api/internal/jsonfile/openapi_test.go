@@ -0,0 +1,55 @@
+package jsonfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"testing"
+
+	"github.com/pl3lee/restjson/internal/yamlutil"
+)
+
+// openAPIFixture has resources and fields whose names YAML reads as something else when they are not quoted
+const openAPIFixture = `{
+	"on": [{"id": 1, "null": null, "1e3": 1000, "-draft": true, "a: b": "x #y", "#tag": "yes"}],
+	"null": {"off": "~"},
+	"1e3": [1, 2],
+	"-draft": "no"
+}`
+
+func TestOpenAPIYamlMatchesJson(t *testing.T) {
+	s := newTestServer(t, openAPIFixture)
+	jsonRec := s.do(t, http.MethodGet, fmt.Sprintf("/jsonfiles/%s/openapi.json", s.fileId), nil)
+	if jsonRec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", jsonRec.Code, jsonRec.Body.String())
+	}
+	yamlRec := s.do(t, http.MethodGet, fmt.Sprintf("/jsonfiles/%s/openapi.yaml", s.fileId), nil)
+	if yamlRec.Code != http.StatusOK {
+		t.Fatalf("got status %d: %s", yamlRec.Code, yamlRec.Body.String())
+	}
+	if contentType := yamlRec.Header().Get("Content-Type"); contentType != "application/yaml" {
+		t.Errorf("got content type %s, want application/yaml", contentType)
+	}
+
+	// the yaml document is the json document as written by yamlutil, whose tests check that it reads back the same
+	want, err := yamlutil.Marshal(json.RawMessage(jsonRec.Body.Bytes()))
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if yamlRec.Body.String() != string(want) {
+		t.Errorf("got yaml\n%s\nwant\n%s", yamlRec.Body.String(), want)
+	}
+
+	doc := decodeBody[OpenAPIDocument](t, jsonRec)
+	paths := slices.Sorted(maps.Keys(doc.Paths))
+	if want := []string{"/-draft", "/1e3", "/1e3/{id}", "/null", "/on", "/on/{id}"}; !slices.Equal(paths, want) {
+		t.Errorf("got paths %v, want %v", paths, want)
+	}
+	itemSchema, _ := doc.Components.Schemas["On"].(map[string]any)
+	properties, _ := itemSchema["properties"].(map[string]any)
+	if got, want := slices.Sorted(maps.Keys(properties)), []string{"#tag", "-draft", "1e3", "a: b", "id", "null"}; !slices.Equal(got, want) {
+		t.Errorf("got properties %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,82 @@
+package jsonfile
+
+import (
+	"encoding/json"
+	"errors"
+	"maps"
+	"mime"
+	"net/http"
+
+	"github.com/pl3lee/restjson/internal/jsonpatch"
+	"github.com/pl3lee/restjson/internal/utils"
+)
+
+const (
+	jsonPatchMediaType  = "application/json-patch+json"
+	mergePatchMediaType = "application/merge-patch+json"
+)
+
+// applyPatchRequest applies the body of a PATCH request to current, depending on its content type:
+// application/json-patch+json is applied as a JSON Patch (RFC 6902),
+// application/merge-patch+json is merged recursively as a JSON Merge Patch (RFC 7396),
+// and application/json is merged shallowly into current, which must be an object.
+// It responds with an error and returns false if the patch cannot be applied.
+func applyPatchRequest(w http.ResponseWriter, r *http.Request, current any) (any, bool) {
+	mediaType := "application/json"
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		parsedMediaType, _, err := mime.ParseMediaType(contentType)
+		if err != nil {
+			utils.RespondWithError(w, http.StatusUnsupportedMediaType, "invalid content type", err)
+			return nil, false
+		}
+		mediaType = parsedMediaType
+	}
+	defer r.Body.Close()
+
+	switch mediaType {
+	case jsonPatchMediaType:
+		var operations []jsonpatch.Operation
+		if err := json.NewDecoder(r.Body).Decode(&operations); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid json patch, expected an array of operations", err)
+			return nil, false
+		}
+		patched, err := jsonpatch.Apply(current, operations)
+		if err != nil {
+			var patchErr *jsonpatch.Error
+			if errors.As(err, &patchErr) {
+				msg := "json patch cannot be applied"
+				if errors.Is(err, jsonpatch.ErrTestFailed) {
+					msg = "json patch test operation failed"
+				}
+				utils.RespondWithErrorDetails(w, http.StatusUnprocessableEntity, msg, patchErr, err)
+				return nil, false
+			}
+			utils.RespondWithError(w, http.StatusUnprocessableEntity, "json patch cannot be applied", err)
+			return nil, false
+		}
+		return patched, true
+	case mergePatchMediaType:
+		var mergePatch any
+		if err := json.NewDecoder(r.Body).Decode(&mergePatch); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid json merge patch", err)
+			return nil, false
+		}
+		return jsonpatch.MergePatch(current, mergePatch), true
+	case "application/json":
+		currentMap, ok := current.(map[string]any)
+		if !ok {
+			utils.RespondWithError(w, http.StatusBadRequest, "resource is not a map", nil)
+			return nil, false
+		}
+		var partialUpdate map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&partialUpdate); err != nil {
+			utils.RespondWithError(w, http.StatusBadRequest, "invalid request body", err)
+			return nil, false
+		}
+		maps.Copy(currentMap, partialUpdate)
+		return currentMap, true
+	default:
+		utils.RespondWithError(w, http.StatusUnsupportedMediaType, "content type must be application/json, "+jsonPatchMediaType+" or "+mergePatchMediaType, nil)
+		return nil, false
+	}
+}
@@ -0,0 +1,97 @@
+package jsonfile
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/pl3lee/restjson/internal/database"
+	"github.com/pl3lee/restjson/internal/jsonpatch"
+)
+
+// resourcePathSegments returns the unescaped segments of the requested path after the file id,
+// for example [users 1 addresses 2] for /public/{fileId}/users/1/addresses/2
+func resourcePathSegments(r *http.Request) []string {
+	rawSegments := []string{chi.URLParam(r, "resource")}
+	if id := chi.URLParam(r, "id"); id != "" {
+		rawSegments = append(rawSegments, id)
+	}
+	if rest := strings.Trim(chi.URLParam(r, "*"), "/"); rest != "" {
+		rawSegments = append(rawSegments, strings.Split(rest, "/")...)
+	}
+
+	segments := []string{}
+	for _, segment := range rawSegments {
+		if unescaped, err := url.PathUnescape(segment); err == nil {
+			segment = unescaped
+		}
+		segments = append(segments, segment)
+	}
+	return segments
+}
+
+// resolveResourcePath converts url path segments into JSON Pointer tokens into fileContents.
+// Segments select object fields by name, and array items by their id field
+// (or by index, for arrays whose items have no ids).
+// The last segment may name an object field that does not exist yet, in which case exists is false.
+func resolveResourcePath(fileMetadata database.JsonFile, fileContents any, segments []string) ([]string, bool, error) {
+	tokens := []string{}
+	current := fileContents
+	for i, segment := range segments {
+		isLast := i == len(segments)-1
+		switch v := current.(type) {
+		case map[string]any:
+			next, ok := v[segment]
+			if !ok {
+				if isLast {
+					return append(tokens, segment), false, nil
+				}
+				return nil, false, fmt.Errorf("%s not found", "/"+strings.Join(segments[:i+1], "/"))
+			}
+			tokens = append(tokens, segment)
+			current = next
+		case []any:
+			// the id field is configured by the name of the field holding the array
+			arrayKey := ""
+			if len(tokens) > 0 {
+				arrayKey = tokens[len(tokens)-1]
+			}
+			index := findItemIndex(v, resourceIdField(fileMetadata, arrayKey), segment)
+			if index == -1 {
+				if number, err := strconv.Atoi(segment); err == nil && number >= 0 && number < len(v) && !hasIds(v) {
+					index = number
+				}
+			}
+			if index == -1 {
+				return nil, false, fmt.Errorf("%s not found", "/"+strings.Join(segments[:i+1], "/"))
+			}
+			tokens = append(tokens, strconv.Itoa(index))
+			current = v[index]
+		default:
+			return nil, false, fmt.Errorf("%s is not an object or array", "/"+strings.Join(segments[:i], "/"))
+		}
+	}
+	return tokens, true, nil
+}
+
+// hasIds reports whether items are objects that can be looked up by id
+func hasIds(items []any) bool {
+	for _, item := range items {
+		if _, ok := item.(map[string]any); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// valueAt returns the value at tokens, which must have been resolved by resolveResourcePath
+func valueAt(fileContents any, tokens []string) any {
+	value, err := jsonpatch.Get(fileContents, tokens)
+	if err != nil {
+		return nil
+	}
+	return value
+}
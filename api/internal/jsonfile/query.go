@@ -0,0 +1,590 @@
+package jsonfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// defaultPageLimit is the number of items per page when _page is given without _limit
+const defaultPageLimit = 10
+
+// filterOperators are the suffixes that can be appended to a field in the query string,
+// for example ?price_gte=10 or ?name_like=foo
+var filterOperators = map[string]bool{
+	"gte":      true,
+	"lte":      true,
+	"ne":       true,
+	"like":     true,
+	"contains": true,
+}
+
+// reservedParams are the query parameters that sort, paginate and embed items instead of filtering them
+var reservedParams = []string{"_sort", "_order", "_page", "_limit", "_start", "_end", "_embed", "_expand"}
+
+// QueryError describes an invalid query parameter, it is returned to the client as error details
+type QueryError struct {
+	Param    string `json:"param"`
+	Operator string `json:"operator,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("invalid query parameter %s: %s", e.Param, e.Message)
+}
+
+type filter struct {
+	param    string
+	path     []string
+	operator string
+	values   []string
+	patterns []*regexp.Regexp
+}
+
+// parseFilters turns query parameters into filters on the given items.
+// Reserved parameters (for example _sort) are skipped, other parameters starting with an underscore
+// filter fields starting with one, such as ?_id=1, and must name a field of the items.
+// Nested fields are separated by dots, for example ?author.name=bob
+func parseFilters(query url.Values, items []any) ([]filter, error) {
+	filters := []filter{}
+	for param, values := range query {
+		if param == "" || slices.Contains(reservedParams, param) {
+			continue
+		}
+
+		field, operator := param, ""
+		if idx := strings.LastIndex(param, "_"); idx > 0 {
+			prefix, suffix := param[:idx], param[idx+1:]
+			if filterOperators[suffix] {
+				field, operator = prefix, suffix
+			} else if !fieldExists(items, splitFieldPath(param)) {
+				// the suffix is not part of a field name, so it must be an operator we do not know about
+				return nil, &QueryError{
+					Param:    param,
+					Operator: suffix,
+					Message:  fmt.Sprintf("unknown operator %q, supported operators are _gte, _lte, _ne, _like and _contains", suffix),
+				}
+			}
+		}
+		if strings.HasPrefix(field, "_") && !fieldExists(items, splitFieldPath(field)) {
+			// most likely a misspelled reserved parameter, such as _sortt
+			return nil, &QueryError{
+				Param:   param,
+				Message: fmt.Sprintf("unknown parameter, reserved parameters are %s", strings.Join(reservedParams, ", ")),
+			}
+		}
+
+		f := filter{
+			param:    param,
+			path:     splitFieldPath(field),
+			operator: operator,
+			values:   values,
+		}
+		if operator == "like" {
+			for _, value := range values {
+				pattern, err := regexp.Compile("(?i)" + value)
+				if err != nil {
+					return nil, &QueryError{
+						Param:    param,
+						Operator: operator,
+						Message:  fmt.Sprintf("invalid pattern %q", value),
+					}
+				}
+				f.patterns = append(f.patterns, pattern)
+			}
+		}
+		filters = append(filters, f)
+	}
+	return filters, nil
+}
+
+// filterItems returns the items that match every filter
+func filterItems(items []any, filters []filter) []any {
+	result := []any{}
+	for _, item := range items {
+		matchesAll := true
+		for _, f := range filters {
+			if !f.matches(item) {
+				matchesAll = false
+				break
+			}
+		}
+		if matchesAll {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func (f filter) matches(item any) bool {
+	fieldValue, ok := getFieldPath(item, f.path)
+	if !ok {
+		// a missing field is never equal to anything
+		return f.operator == "ne"
+	}
+
+	switch f.operator {
+	case "":
+		// multiple values for the same field match any of them, e.g. ?id=1&id=2
+		for _, value := range f.values {
+			if stringifyValue(fieldValue) == value {
+				return true
+			}
+		}
+		return false
+	case "ne":
+		for _, value := range f.values {
+			if stringifyValue(fieldValue) == value {
+				return false
+			}
+		}
+		return true
+	case "gte":
+		for _, value := range f.values {
+			if compareToQueryValue(fieldValue, value) < 0 {
+				return false
+			}
+		}
+		return true
+	case "lte":
+		for _, value := range f.values {
+			if compareToQueryValue(fieldValue, value) > 0 {
+				return false
+			}
+		}
+		return true
+	case "like":
+		for _, pattern := range f.patterns {
+			if pattern.MatchString(stringifyValue(fieldValue)) {
+				return true
+			}
+		}
+		return false
+	case "contains":
+		for _, value := range f.values {
+			switch v := fieldValue.(type) {
+			case []any:
+				for _, element := range v {
+					if stringifyValue(element) == value {
+						return true
+					}
+				}
+			case string:
+				if strings.Contains(v, value) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return false
+}
+
+func splitFieldPath(field string) []string {
+	return strings.Split(field, ".")
+}
+
+// getFieldPath walks through nested objects (and arrays, by index) following path
+func getFieldPath(value any, path []string) (any, bool) {
+	current := value
+	for _, key := range path {
+		switch v := current.(type) {
+		case map[string]any:
+			next, ok := v[key]
+			if !ok {
+				return nil, false
+			}
+			current = next
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			current = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func fieldExists(items []any, path []string) bool {
+	for _, item := range items {
+		if _, ok := getFieldPath(item, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// stringifyValue formats a json value the same way it would appear in a url,
+// so that ?id=1 matches both {"id": 1} and {"id": "1"}
+func stringifyValue(value any) string {
+	if value == nil {
+		return "null"
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// compareToQueryValue compares numerically when both sides are numbers, otherwise as strings
+func compareToQueryValue(fieldValue any, queryValue string) int {
+	if number, ok := fieldValue.(float64); ok {
+		if queryNumber, err := strconv.ParseFloat(queryValue, 64); err == nil {
+			switch {
+			case number < queryNumber:
+				return -1
+			case number > queryNumber:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+	return strings.Compare(stringifyValue(fieldValue), queryValue)
+}
+
+// queryItems applies filtering, sorting and pagination from the query string to items.
+// It sets the X-Total-Count header, and the Link header when the result is paginated.
+func (cfg *JsonConfig) queryItems(w http.ResponseWriter, r *http.Request, items []any) ([]any, error) {
+	query := r.URL.Query()
+
+	result, total, page, err := selectItems(query, items)
+	if err != nil {
+		return nil, err
+	}
+
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+	if page == nil {
+		return result, nil
+	}
+	if links := page.links(cfg.BaseURL+r.URL.Path, query, total); len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
+	return result, nil
+}
+
+// selectItems filters, sorts and paginates items as described by query.
+// It returns the selected items, the number of items matching the filters and the pagination, which is nil if there is none.
+func selectItems(query url.Values, items []any) ([]any, int, *pagination, error) {
+	filters, err := parseFilters(query, items)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	sortKeys, err := parseSort(query)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+	page, err := parsePagination(query)
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	result := filterItems(items, filters)
+	sortItems(result, sortKeys)
+
+	total := len(result)
+	if page == nil {
+		return result, total, nil, nil
+	}
+	start, end := page.bounds(total)
+	return result[start:end], total, page, nil
+}
+
+type sortKey struct {
+	path []string
+	desc bool
+}
+
+// parseSort reads _sort and _order, multiple keys are comma separated,
+// for example ?_sort=lastName,firstName&_order=asc,desc
+func parseSort(query url.Values) ([]sortKey, error) {
+	sortParam := query.Get("_sort")
+	if sortParam == "" {
+		return nil, nil
+	}
+	fields := strings.Split(sortParam, ",")
+	orders := []string{}
+	if orderParam := query.Get("_order"); orderParam != "" {
+		orders = strings.Split(orderParam, ",")
+	}
+	if len(orders) > len(fields) {
+		return nil, &QueryError{
+			Param:   "_order",
+			Message: "more orders than sort fields given",
+		}
+	}
+
+	sortKeys := []sortKey{}
+	for i, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			return nil, &QueryError{
+				Param:   "_sort",
+				Message: "sort field cannot be empty",
+			}
+		}
+		key := sortKey{path: splitFieldPath(field)}
+		if i < len(orders) {
+			switch strings.ToLower(strings.TrimSpace(orders[i])) {
+			case "asc":
+			case "desc":
+				key.desc = true
+			default:
+				return nil, &QueryError{
+					Param:   "_order",
+					Message: fmt.Sprintf("order %q must be asc or desc", orders[i]),
+				}
+			}
+		}
+		sortKeys = append(sortKeys, key)
+	}
+	return sortKeys, nil
+}
+
+// sortItems sorts items in place by the given keys, keeping the original order of equal items
+func sortItems(items []any, sortKeys []sortKey) {
+	if len(sortKeys) == 0 {
+		return
+	}
+	slices.SortStableFunc(items, func(a, b any) int {
+		for _, key := range sortKeys {
+			aValue, _ := getFieldPath(a, key.path)
+			bValue, _ := getFieldPath(b, key.path)
+			cmp := compareValues(aValue, bValue)
+			if cmp == 0 {
+				continue
+			}
+			if key.desc {
+				return -cmp
+			}
+			return cmp
+		}
+		return 0
+	})
+}
+
+// typeRank orders values of different json types: null < boolean < number < string < array < object
+func typeRank(value any) int {
+	switch value.(type) {
+	case nil:
+		return 0
+	case bool:
+		return 1
+	case float64:
+		return 2
+	case string:
+		return 3
+	case []any:
+		return 4
+	default:
+		return 5
+	}
+}
+
+// compareValues gives a total order over json values, so that sorting mixed types is deterministic
+func compareValues(a, b any) int {
+	aRank, bRank := typeRank(a), typeRank(b)
+	if aRank != bRank {
+		return aRank - bRank
+	}
+	switch a := a.(type) {
+	case nil:
+		return 0
+	case bool:
+		bBool := b.(bool)
+		switch {
+		case a == bBool:
+			return 0
+		case !a:
+			return -1
+		default:
+			return 1
+		}
+	case float64:
+		bNumber := b.(float64)
+		switch {
+		case a < bNumber:
+			return -1
+		case a > bNumber:
+			return 1
+		default:
+			return 0
+		}
+	case string:
+		return strings.Compare(a, b.(string))
+	default:
+		// arrays and objects have no natural order, compare their encoding instead
+		aJson, _ := json.Marshal(a)
+		bJson, _ := json.Marshal(b)
+		return strings.Compare(string(aJson), string(bJson))
+	}
+}
+
+// pagination is either page based (_page and _limit) or slice based (_start, _end and _limit)
+type pagination struct {
+	page  int
+	limit int
+	start int
+	end   int
+	paged bool
+}
+
+func parsePagination(query url.Values) (*pagination, error) {
+	parseInt := func(param string, min int) (int, bool, error) {
+		value := query.Get(param)
+		if value == "" {
+			return 0, false, nil
+		}
+		number, err := strconv.Atoi(value)
+		if err != nil || number < min {
+			return 0, false, &QueryError{
+				Param:   param,
+				Message: fmt.Sprintf("must be an integer greater than or equal to %d", min),
+			}
+		}
+		return number, true, nil
+	}
+
+	page, hasPage, err := parseInt("_page", 1)
+	if err != nil {
+		return nil, err
+	}
+	limit, hasLimit, err := parseInt("_limit", 1)
+	if err != nil {
+		return nil, err
+	}
+	start, hasStart, err := parseInt("_start", 0)
+	if err != nil {
+		return nil, err
+	}
+	end, hasEnd, err := parseInt("_end", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case hasPage && (hasStart || hasEnd):
+		return nil, &QueryError{
+			Param:   "_page",
+			Message: "_page cannot be combined with _start or _end",
+		}
+	case hasPage:
+		if !hasLimit {
+			limit = defaultPageLimit
+		}
+		// the end of the page, page*limit, has to fit in an int
+		if page > math.MaxInt/limit {
+			return nil, &QueryError{
+				Param:   "_page",
+				Message: "_page is too large for the given _limit",
+			}
+		}
+		return &pagination{page: page, limit: limit, paged: true}, nil
+	case hasStart || hasEnd:
+		if hasEnd && hasLimit {
+			return nil, &QueryError{
+				Param:   "_end",
+				Message: "_end cannot be combined with _limit",
+			}
+		}
+		if hasEnd && end < start {
+			return nil, &QueryError{
+				Param:   "_end",
+				Message: "_end must be greater than or equal to _start",
+			}
+		}
+		if !hasEnd && !hasLimit {
+			return &pagination{start: start, end: -1}, nil
+		}
+		if hasLimit {
+			if limit > math.MaxInt-start {
+				return nil, &QueryError{
+					Param:   "_limit",
+					Message: "_start plus _limit is too large",
+				}
+			}
+			end = start + limit
+		}
+		return &pagination{start: start, end: end}, nil
+	case hasLimit:
+		return &pagination{start: 0, end: limit}, nil
+	}
+	return nil, nil
+}
+
+// bounds returns the slice of the total items covered by the pagination
+func (p *pagination) bounds(total int) (int, int) {
+	start, end := p.start, p.end
+	if p.paged {
+		start = (p.page - 1) * p.limit
+		end = start + p.limit
+	}
+	start = min(max(start, 0), total)
+	if end < 0 || end > total {
+		end = total
+	}
+	return start, max(end, start)
+}
+
+// links builds RFC 8288 links to the first, previous, next and last pages
+func (p *pagination) links(baseURL string, query url.Values, total int) []string {
+	link := func(rel string, set map[string]int) string {
+		q := url.Values{}
+		for key, values := range query {
+			q[key] = slices.Clone(values)
+		}
+		for key, value := range set {
+			q.Set(key, strconv.Itoa(value))
+		}
+		return fmt.Sprintf("<%s?%s>; rel=\"%s\"", baseURL, q.Encode(), rel)
+	}
+
+	links := []string{}
+	if p.paged {
+		lastPage := 1
+		if total > 0 {
+			// rounds up without computing total+limit, which can overflow
+			lastPage = (total-1)/p.limit + 1
+		}
+		links = append(links, link("first", map[string]int{"_page": 1}))
+		if p.page > 1 {
+			links = append(links, link("prev", map[string]int{"_page": min(p.page-1, lastPage)}))
+		}
+		if p.page < lastPage {
+			links = append(links, link("next", map[string]int{"_page": p.page + 1}))
+		}
+		links = append(links, link("last", map[string]int{"_page": lastPage}))
+		return links
+	}
+
+	if p.end < 0 {
+		// open ended slice, everything after _start is already returned
+		return links
+	}
+	size := p.end - p.start
+	if size == 0 {
+		return links
+	}
+	window := func(start int) map[string]int {
+		if query.Has("_limit") {
+			return map[string]int{"_start": start}
+		}
+		return map[string]int{"_start": start, "_end": start + size}
+	}
+	lastStart := max(total-size, 0)
+	links = append(links, link("first", window(0)))
+	if p.start > 0 {
+		links = append(links, link("prev", window(max(p.start-size, 0))))
+	}
+	if p.end < total {
+		links = append(links, link("next", window(p.end)))
+	}
+	links = append(links, link("last", window(lastStart)))
+	return links
+}
@@ -0,0 +1,238 @@
+package jsonfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func queryFixture(t *testing.T) []any {
+	t.Helper()
+	var items []any
+	err := json.Unmarshal([]byte(`[
+		{"id": 1, "_rev": 1, "name": "Alice", "age": 30, "tags": ["admin"], "address": {"city": "Paris"}},
+		{"id": 2, "_rev": 2, "name": "bob", "age": 25, "tags": ["user"], "address": {"city": "Berlin"}},
+		{"id": "3", "name": "Carol", "age": 35, "tags": ["user", "admin"]},
+		{"id": 4, "name": "dave", "age": 25, "tags": [], "address": {"city": "Paris"}}
+	]`), &items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return items
+}
+
+// itemIds returns the ids of items as strings, so that numeric and string ids compare the same
+func itemIds(items []any) []string {
+	ids := []string{}
+	for _, item := range items {
+		ids = append(ids, stringifyValue(item.(map[string]any)["id"]))
+	}
+	return ids
+}
+
+func TestSelectItemsFilters(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"", []string{"1", "2", "3", "4"}},
+		{"id=1", []string{"1"}},
+		{"id=3", []string{"3"}},
+		{"id=1&id=4", []string{"1", "4"}},
+		{"age=25", []string{"2", "4"}},
+		{"age_ne=25", []string{"1", "3"}},
+		{"age_gte=30", []string{"1", "3"}},
+		{"age_lte=25", []string{"2", "4"}},
+		{"age_gte=26&age_lte=34", []string{"1"}},
+		{"name_like=^a", []string{"1"}},
+		{"name_like=O", []string{"2", "3"}},
+		{"tags_contains=admin", []string{"1", "3"}},
+		{"name_contains=ar", []string{"3"}},
+		{"address.city=Paris", []string{"1", "4"}},
+		{"address.city_ne=Paris", []string{"2", "3"}},
+		{"tags.0=user", []string{"2", "3"}},
+		{"missing=1", []string{}},
+		{"_rev=2", []string{"2"}},
+		{"_rev_ne=2", []string{"1", "3", "4"}},
+		{"_embed=comments", []string{"1", "2", "3", "4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			result, total, _, err := selectItems(query, queryFixture(t))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := itemIds(result); !slices.Equal(got, tt.want) {
+				t.Errorf("got ids %v, want %v", got, tt.want)
+			}
+			if total != len(tt.want) {
+				t.Errorf("got total %d, want %d", total, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestSelectItemsSort(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"_sort=age", []string{"2", "4", "1", "3"}},
+		{"_sort=age&_order=desc", []string{"3", "1", "2", "4"}},
+		{"_sort=age,name&_order=asc,desc", []string{"4", "2", "1", "3"}},
+		{"_sort=name", []string{"1", "3", "2", "4"}},
+		{"_sort=address.city", []string{"3", "2", "1", "4"}},
+		// numbers sort before strings
+		{"_sort=id", []string{"1", "2", "4", "3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			result, _, _, err := selectItems(query, queryFixture(t))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := itemIds(result); !slices.Equal(got, tt.want) {
+				t.Errorf("got ids %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelectItemsPagination(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"_page=1&_limit=3", []string{"1", "2", "3"}},
+		{"_page=2&_limit=3", []string{"4"}},
+		{"_page=3&_limit=3", []string{}},
+		{"_page=1", []string{"1", "2", "3", "4"}},
+		{"_limit=2", []string{"1", "2"}},
+		{"_start=1", []string{"2", "3", "4"}},
+		{"_start=1&_end=3", []string{"2", "3"}},
+		{"_start=1&_limit=2", []string{"2", "3"}},
+		{"_start=10", []string{}},
+		{"_start=2&_end=2", []string{}},
+		{"_sort=age&_order=desc&_page=2&_limit=2", []string{"2", "4"}},
+		{"age=25&_limit=1", []string{"2"}},
+		{fmt.Sprintf("_page=%d&_limit=1", math.MaxInt), []string{}},
+		{fmt.Sprintf("_page=2&_limit=%d", math.MaxInt/2), []string{}},
+		{fmt.Sprintf("_start=%d", math.MaxInt), []string{}},
+		{fmt.Sprintf("_start=1&_limit=%d", math.MaxInt-1), []string{"2", "3", "4"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			result, total, _, err := selectItems(query, queryFixture(t))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := itemIds(result); !slices.Equal(got, tt.want) {
+				t.Errorf("got ids %v, want %v", got, tt.want)
+			}
+			if query.Get("age") == "" && total != 4 {
+				t.Errorf("got total %d, want 4", total)
+			}
+		})
+	}
+}
+
+func TestSelectItemsErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		param string
+	}{
+		{"age_gt=1", "age_gt"},
+		{"missing_gt=1", "missing_gt"},
+		{"address.city_in=Paris", "address.city_in"},
+		{"_sortt=age", "_sortt"},
+		{"_unknown=1", "_unknown"},
+		{"_missing_ne=1", "_missing_ne"},
+		{"name_like=(", "name_like"},
+		{"_sort=", ""},
+		{"_sort=age,", "_sort"},
+		{"_sort=age&_order=asc,desc", "_order"},
+		{"_sort=age&_order=up", "_order"},
+		{"_page=0", "_page"},
+		{"_page=a", "_page"},
+		{"_limit=0", "_limit"},
+		{"_start=-1", "_start"},
+		{"_end=-1", "_end"},
+		{"_page=1&_start=0", "_page"},
+		{"_start=0&_end=2&_limit=2", "_end"},
+		{"_start=3&_end=2", "_end"},
+		{"_page=4611686018427387904&_limit=4", "_page"},
+		{fmt.Sprintf("_page=%d", math.MaxInt/defaultPageLimit+1), "_page"},
+		{"_page=99999999999999999999", "_page"},
+		{fmt.Sprintf("_start=%d&_limit=1", math.MaxInt), "_limit"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			query, _ := url.ParseQuery(tt.query)
+			_, _, _, err := selectItems(query, queryFixture(t))
+			if tt.param == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var queryErr *QueryError
+			if !errors.As(err, &queryErr) {
+				t.Fatalf("got error %v, want a QueryError", err)
+			}
+			if queryErr.Param != tt.param {
+				t.Errorf("got error for %s, want %s", queryErr.Param, tt.param)
+			}
+		})
+	}
+}
+
+func TestPaginationBounds(t *testing.T) {
+	tests := []struct {
+		page      pagination
+		total     int
+		wantStart int
+		wantEnd   int
+	}{
+		{pagination{page: 1, limit: 10, paged: true}, 25, 0, 10},
+		{pagination{page: 3, limit: 10, paged: true}, 25, 20, 25},
+		{pagination{page: 4, limit: 10, paged: true}, 25, 25, 25},
+		{pagination{page: math.MaxInt, limit: 1, paged: true}, 25, 25, 25},
+		{pagination{start: 5, end: -1}, 25, 5, 25},
+		{pagination{start: 5, end: 50}, 25, 5, 25},
+		{pagination{start: 50, end: 60}, 25, 25, 25},
+		{pagination{start: -4, end: 0}, 25, 0, 0},
+	}
+	for i, tt := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			start, end := tt.page.bounds(tt.total)
+			if start != tt.wantStart || end != tt.wantEnd {
+				t.Errorf("got bounds [%d:%d], want [%d:%d]", start, end, tt.wantStart, tt.wantEnd)
+			}
+		})
+	}
+}
+
+func TestPaginationLinks(t *testing.T) {
+	query, _ := url.ParseQuery(fmt.Sprintf("_page=2&_limit=%d", math.MaxInt/2))
+	page, err := parsePagination(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	links := page.links("http://localhost/users", query, 5)
+	want := []string{
+		fmt.Sprintf(`<http://localhost/users?_limit=%d&_page=1>; rel="first"`, math.MaxInt/2),
+		fmt.Sprintf(`<http://localhost/users?_limit=%d&_page=1>; rel="prev"`, math.MaxInt/2),
+		fmt.Sprintf(`<http://localhost/users?_limit=%d&_page=1>; rel="last"`, math.MaxInt/2),
+	}
+	if !slices.Equal(links, want) {
+		t.Errorf("got links %v, want %v", links, want)
+	}
+}
@@ -0,0 +1,251 @@
+package jsonfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/pl3lee/restjson/internal/database"
+	"github.com/pl3lee/restjson/internal/jsonpatch"
+	"github.com/pl3lee/restjson/internal/utils"
+)
+
+const (
+	OnDeleteRestrict = "restrict"
+	OnDeleteCascade  = "cascade"
+	OnDeleteSetNull  = "set-null"
+)
+
+// Relationship declares that the field of items in resource references items of another resource,
+// and what happens to those items when the referenced item is deleted
+type Relationship struct {
+	Resource   string `json:"resource"`
+	Field      string `json:"field"`
+	References string `json:"references"`
+	OnDelete   string `json:"onDelete"`
+}
+
+func isValidOnDelete(onDelete string) bool {
+	return onDelete == OnDeleteRestrict || onDelete == OnDeleteCascade || onDelete == OnDeleteSetNull
+}
+
+// fileRelationships returns the relationships declared for the json file
+func fileRelationships(fileMetadata database.JsonFile) []Relationship {
+	relationships := []Relationship{}
+	if len(fileMetadata.Relationships) == 0 {
+		return relationships
+	}
+	if err := json.Unmarshal(fileMetadata.Relationships, &relationships); err != nil {
+		log.Printf("invalid relationships for json file %s: %v", fileMetadata.ID, err)
+		return []Relationship{}
+	}
+	return relationships
+}
+
+// ReferenceError describes a reference that breaks a declared relationship,
+// either a reference to an item that does not exist or an item that cannot be deleted because it is referenced
+type ReferenceError struct {
+	Resource   string `json:"resource"`
+	Field      string `json:"field"`
+	Value      any    `json:"value"`
+	References string `json:"references"`
+	Item       any    `json:"item,omitempty"`
+	Message    string `json:"message"`
+
+	// stillReferenced is set when a restrict rule prevented the delete of the referenced item
+	stillReferenced bool
+}
+
+func (e *ReferenceError) Error() string {
+	return fmt.Sprintf("%s.%s: %s", e.Resource, e.Field, e.Message)
+}
+
+// summary returns the message of the response to a write that failed with the error
+func (e *ReferenceError) summary() string {
+	if e.stillReferenced {
+		return "resource item is still referenced"
+	}
+	return "invalid reference"
+}
+
+// respondWithReferenceError responds with 409 when an item could not be deleted, and 422 for any other broken reference
+func respondWithReferenceError(w http.ResponseWriter, err *ReferenceError) {
+	code := http.StatusUnprocessableEntity
+	if err.stillReferenced {
+		code = http.StatusConflict
+	}
+	utils.RespondWithErrorDetails(w, code, err.summary(), err, err)
+}
+
+// enforceReferences applies the relationships of a json file to contents that are about to replace previous,
+// whichever route changed them. Referenced items missing from contents were deleted, so the onDelete rules
+// of the relationships referencing them are applied, then every reference has to refer to an existing item.
+// contents are only updated with the result of the rules when no reference is broken.
+func enforceReferences(fileMetadata database.JsonFile, previous any, contents any) error {
+	relationships := fileRelationships(fileMetadata)
+	contentsMap, ok := contents.(map[string]any)
+	if len(relationships) == 0 || !ok {
+		return nil
+	}
+	previousMap, _ := previous.(map[string]any)
+
+	updatedContents, err := applyDeleteRules(fileMetadata, contentsMap, removedItems(fileMetadata, relationships, previousMap, contentsMap))
+	if err != nil {
+		return err
+	}
+	if err := checkReferences(fileMetadata, updatedContents); err != nil {
+		return err
+	}
+	for resource, value := range updatedContents {
+		contentsMap[resource] = value
+	}
+	return nil
+}
+
+// removedItems returns the items of previous that are referenced by a relationship and no longer in contents.
+// An item whose id changed was removed as well, since references to its old id are broken.
+func removedItems(fileMetadata database.JsonFile, relationships []Relationship, previous map[string]any, contents map[string]any) []deletedItem {
+	removed := []deletedItem{}
+	seen := map[string]bool{}
+	for _, relationship := range relationships {
+		resource := relationship.References
+		if seen[resource] {
+			continue
+		}
+		seen[resource] = true
+
+		previousItems, _ := previous[resource].([]any)
+		if len(previousItems) == 0 {
+			continue
+		}
+		idField := resourceIdField(fileMetadata, resource)
+		ids := idSet(contents[resource], idField)
+		for _, item := range previousItems {
+			itemMap, ok := item.(map[string]any)
+			if !ok || itemMap[idField] == nil || ids[stringifyValue(itemMap[idField])] {
+				continue
+			}
+			removed = append(removed, deletedItem{resource: resource, item: item})
+		}
+	}
+	return removed
+}
+
+// idSet returns the ids of the items of a resource, as strings
+func idSet(value any, idField string) map[string]bool {
+	ids := map[string]bool{}
+	items, _ := value.([]any)
+	for _, item := range items {
+		itemMap, ok := item.(map[string]any)
+		if !ok || itemMap[idField] == nil {
+			continue
+		}
+		ids[stringifyValue(itemMap[idField])] = true
+	}
+	return ids
+}
+
+// checkReferences makes sure that every reference of every item in fileContents refers to an existing item
+func checkReferences(fileMetadata database.JsonFile, fileContents map[string]any) error {
+	referencedIds := map[string]map[string]bool{}
+	for _, relationship := range fileRelationships(fileMetadata) {
+		idField := resourceIdField(fileMetadata, relationship.References)
+		ids, ok := referencedIds[relationship.References]
+		if !ok {
+			ids = idSet(fileContents[relationship.References], idField)
+			referencedIds[relationship.References] = ids
+		}
+
+		items, _ := fileContents[relationship.Resource].([]any)
+		for _, item := range items {
+			itemMap, ok := item.(map[string]any)
+			if !ok {
+				continue
+			}
+			value, ok := itemMap[relationship.Field]
+			if !ok || value == nil || ids[stringifyValue(value)] {
+				continue
+			}
+			return &ReferenceError{
+				Resource:   relationship.Resource,
+				Field:      relationship.Field,
+				Value:      value,
+				References: relationship.References,
+				Item:       itemMap[resourceIdField(fileMetadata, relationship.Resource)],
+				Message:    fmt.Sprintf("no item in %s has %s %v", relationship.References, idField, value),
+			}
+		}
+	}
+	return nil
+}
+
+type deletedItem struct {
+	resource string
+	item     any
+}
+
+// applyDeleteRules applies the onDelete rule of every relationship referencing an item that was removed.
+// Items referencing it are deleted (cascade, which applies the rules again for them) or have their reference set to null (set-null).
+// The rules are applied to a copy of fileContents, which is returned,
+// so the file is left untouched when a restrict rule prevents the delete.
+func applyDeleteRules(fileMetadata database.JsonFile, fileContents map[string]any, removed []deletedItem) (map[string]any, error) {
+	relationships := fileRelationships(fileMetadata)
+	if len(relationships) == 0 || len(removed) == 0 {
+		return fileContents, nil
+	}
+
+	contents := jsonpatch.DeepCopy(fileContents).(map[string]any)
+	queue := slices.Clone(removed)
+	for len(queue) > 0 {
+		deleted := queue[0]
+		queue = queue[1:]
+		deletedMap, ok := deleted.item.(map[string]any)
+		if !ok {
+			continue
+		}
+		deletedId, ok := deletedMap[resourceIdField(fileMetadata, deleted.resource)]
+		if !ok || deletedId == nil {
+			continue
+		}
+
+		for _, relationship := range relationships {
+			if relationship.References != deleted.resource {
+				continue
+			}
+			items, ok := contents[relationship.Resource].([]any)
+			if !ok {
+				continue
+			}
+			kept := []any{}
+			for _, item := range items {
+				itemMap, ok := item.(map[string]any)
+				reference, hasReference := itemMap[relationship.Field]
+				if !ok || !hasReference || reference == nil || stringifyValue(reference) != stringifyValue(deletedId) {
+					kept = append(kept, item)
+					continue
+				}
+				switch relationship.OnDelete {
+				case OnDeleteCascade:
+					queue = append(queue, deletedItem{resource: relationship.Resource, item: item})
+				case OnDeleteSetNull:
+					itemMap[relationship.Field] = nil
+					kept = append(kept, item)
+				default:
+					return nil, &ReferenceError{
+						Resource:        relationship.Resource,
+						Field:           relationship.Field,
+						Value:           deletedId,
+						References:      relationship.References,
+						Item:            itemMap[resourceIdField(fileMetadata, relationship.Resource)],
+						Message:         fmt.Sprintf("item of %s is still referenced by %s", relationship.References, relationship.Resource),
+						stillReferenced: true,
+					}
+				}
+			}
+			contents[relationship.Resource] = kept
+		}
+	}
+	return contents, nil
+}
@@ -0,0 +1,208 @@
+package jsonfile
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+const referencesFixture = `{
+	"users": [{"id": 1}, {"id": 2}],
+	"posts": [{"id": 1, "userId": 1}, {"id": 2, "userId": 2}],
+	"comments": [{"id": 1, "postId": 1}, {"id": 2, "postId": 2}]
+}`
+
+// newReferencesServer serves referencesFixture, where posts reference users with the given onDelete rule
+// and comments reference posts with set-null
+func newReferencesServer(t *testing.T, onDelete string) *testServer {
+	t.Helper()
+	s := newTestServer(t, referencesFixture)
+	rec := s.do(t, http.MethodPut, fmt.Sprintf("/jsonfiles/%s/relationships", s.fileId), map[string]any{
+		"relationships": []Relationship{
+			{Resource: "posts", Field: "userId", References: "users", OnDelete: onDelete},
+			{Resource: "comments", Field: "postId", References: "posts", OnDelete: OnDeleteSetNull},
+		},
+	})
+	if rec.Code != http.StatusOK {
+		t.Fatalf("error setting relationships: %d %s", rec.Code, rec.Body.String())
+	}
+	return s
+}
+
+func decodeJson(t *testing.T, value string) map[string]any {
+	t.Helper()
+	decoded := map[string]any{}
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		t.Fatalf("invalid json %q: %v", value, err)
+	}
+	return decoded
+}
+
+func TestDeleteRules(t *testing.T) {
+	// every way of removing user 1
+	writes := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+	}{
+		{name: "delete item", method: http.MethodDelete, path: "/public/%s/users/1"},
+		{name: "replace resource", method: http.MethodPut, path: "/public/%s/users", body: `[{"id": 2}]`},
+		{name: "patch resource", method: http.MethodPatch, path: "/public/%s/users", contentType: jsonPatchMediaType, body: `[{"op": "remove", "path": "/0"}]`},
+		{name: "patch file", method: http.MethodPatch, path: "/public/%s", body: `{"users": [{"id": 2}]}`},
+		{name: "change id through nested path", method: http.MethodPut, path: "/public/%s/users/1/id", body: `3`},
+		{name: "replace file", method: http.MethodPut, path: "/jsonfiles/%s", body: `{
+			"users": [{"id": 2}],
+			"posts": [{"id": 1, "userId": 1}, {"id": 2, "userId": 2}],
+			"comments": [{"id": 1, "postId": 1}, {"id": 2, "postId": 2}]
+		}`},
+	}
+	rules := []struct {
+		onDelete string
+		status   int
+		posts    string
+		comments string
+	}{
+		{
+			onDelete: OnDeleteRestrict,
+			status:   http.StatusConflict,
+			posts:    `[{"id": 1, "userId": 1}, {"id": 2, "userId": 2}]`,
+			comments: `[{"id": 1, "postId": 1}, {"id": 2, "postId": 2}]`,
+		},
+		{
+			onDelete: OnDeleteCascade,
+			status:   http.StatusOK,
+			posts:    `[{"id": 2, "userId": 2}]`,
+			comments: `[{"id": 1, "postId": null}, {"id": 2, "postId": 2}]`,
+		},
+		{
+			onDelete: OnDeleteSetNull,
+			status:   http.StatusOK,
+			posts:    `[{"id": 1, "userId": null}, {"id": 2, "userId": 2}]`,
+			comments: `[{"id": 1, "postId": 1}, {"id": 2, "postId": 2}]`,
+		},
+	}
+	for _, rule := range rules {
+		for _, write := range writes {
+			t.Run(rule.onDelete+"/"+write.name, func(t *testing.T) {
+				s := newReferencesServer(t, rule.onDelete)
+				contentType := cmp.Or(write.contentType, "application/json")
+				rec := s.doWithContentType(t, write.method, fmt.Sprintf(write.path, s.fileId), contentType, write.body)
+				if rec.Code != rule.status {
+					t.Fatalf("got status %d, want %d: %s", rec.Code, rule.status, rec.Body.String())
+				}
+
+				contents := s.contents(t)
+				want := decodeJson(t, fmt.Sprintf(`{"posts": %s, "comments": %s}`, rule.posts, rule.comments))
+				if !reflect.DeepEqual(contents["posts"], want["posts"]) {
+					t.Errorf("got posts %v, want %v", contents["posts"], want["posts"])
+				}
+				if !reflect.DeepEqual(contents["comments"], want["comments"]) {
+					t.Errorf("got comments %v, want %v", contents["comments"], want["comments"])
+				}
+				if users := contents["users"].([]any); rule.status == http.StatusConflict && len(users) != 2 {
+					t.Errorf("got users %v after a restricted delete, want both users", users)
+				}
+			})
+		}
+	}
+}
+
+func TestDeleteRulesOfChildRoute(t *testing.T) {
+	s := newReferencesServer(t, OnDeleteRestrict)
+
+	rec := s.do(t, http.MethodDelete, fmt.Sprintf("/public/%s/users/1/posts/1", s.fileId), nil)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	want := decodeJson(t, `{
+		"users": [{"id": 1}, {"id": 2}],
+		"posts": [{"id": 2, "userId": 2}],
+		"comments": [{"id": 1, "postId": null}, {"id": 2, "postId": 2}]
+	}`)
+	if contents := s.contents(t); !reflect.DeepEqual(contents, want) {
+		t.Errorf("got contents %v, want %v", contents, want)
+	}
+	// the response shows the result of the rules
+	if body := decodeBody[map[string]any](t, rec); !reflect.DeepEqual(body, want) {
+		t.Errorf("got response %v, want %v", body, want)
+	}
+
+	rec = s.do(t, http.MethodDelete, fmt.Sprintf("/public/%s/users/2", s.fileId), nil)
+	if rec.Code != http.StatusConflict {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusConflict, rec.Body.String())
+	}
+	details := decodeBody[struct {
+		Error   string         `json:"error"`
+		Details ReferenceError `json:"details"`
+	}](t, rec)
+	if details.Error != "resource item is still referenced" || details.Details.Resource != "posts" || details.Details.Item != float64(2) {
+		t.Errorf("got response %s", rec.Body.String())
+	}
+}
+
+func TestReferencesAreChecked(t *testing.T) {
+	// every way of making post 1 reference a user that does not exist
+	writes := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{name: "create item", method: http.MethodPost, path: "/public/%s/posts", body: `{"userId": 9}`},
+		{name: "replace item", method: http.MethodPut, path: "/public/%s/posts/1", body: `{"id": 1, "userId": 9}`},
+		{name: "patch item", method: http.MethodPatch, path: "/public/%s/posts/1", body: `{"userId": 9}`},
+		{name: "replace resource", method: http.MethodPut, path: "/public/%s/posts", body: `[{"id": 1, "userId": 9}]`},
+		{name: "patch file", method: http.MethodPatch, path: "/public/%s", body: `{"posts": [{"id": 1, "userId": 9}]}`},
+		{name: "nested path", method: http.MethodPut, path: "/public/%s/posts/1/userId", body: `9`},
+		{name: "replace file", method: http.MethodPut, path: "/jsonfiles/%s", body: `{"users": [{"id": 1}, {"id": 2}], "posts": [{"id": 1, "userId": 9}]}`},
+	}
+	for _, write := range writes {
+		t.Run(write.name, func(t *testing.T) {
+			s := newReferencesServer(t, OnDeleteRestrict)
+			rec := s.do(t, write.method, fmt.Sprintf(write.path, s.fileId), write.body)
+			if rec.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusUnprocessableEntity, rec.Body.String())
+			}
+			if contents := s.contents(t); !reflect.DeepEqual(contents, decodeJson(t, referencesFixture)) {
+				t.Errorf("contents changed to %v", contents)
+			}
+		})
+	}
+
+	s := newReferencesServer(t, OnDeleteRestrict)
+	if rec := s.do(t, http.MethodPatch, fmt.Sprintf("/public/%s/posts/1", s.fileId), `{"userId": 2}`); rec.Code != http.StatusOK {
+		t.Fatalf("got status %d for a valid reference, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
+
+func TestRelationshipsAreCheckedAgainstContents(t *testing.T) {
+	s := newTestServer(t, `{"users": [{"id": 1}], "posts": [{"id": 1, "userId": 1}, {"id": 2, "userId": 9}]}`)
+	relationships := map[string]any{
+		"relationships": []Relationship{{Resource: "posts", Field: "userId", References: "users"}},
+	}
+	rec := s.do(t, http.MethodPut, fmt.Sprintf("/jsonfiles/%s/relationships", s.fileId), relationships)
+	if rec.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusUnprocessableEntity, rec.Body.String())
+	}
+	details := decodeBody[struct {
+		Details ReferenceError `json:"details"`
+	}](t, rec)
+	if details.Details.Resource != "posts" || details.Details.Item != float64(2) || details.Details.Value != float64(9) {
+		t.Errorf("got response %s", rec.Body.String())
+	}
+	if got := fileRelationships(s.db.file(s.fileId)); len(got) != 0 {
+		t.Errorf("got relationships %v, want none to be saved", got)
+	}
+
+	if rec := s.do(t, http.MethodPatch, fmt.Sprintf("/public/%s/posts/2", s.fileId), `{"userId": 1}`); rec.Code != http.StatusOK {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	if rec := s.do(t, http.MethodPut, fmt.Sprintf("/jsonfiles/%s/relationships", s.fileId), relationships); rec.Code != http.StatusOK {
+		t.Fatalf("got status %d once the reference is fixed, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+}
@@ -0,0 +1,235 @@
+package jsonfile
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"strings"
+
+	"github.com/pl3lee/restjson/internal/database"
+)
+
+const defaultForeignKeySuffix = "Id"
+
+// singularize turns a resource name into its singular form, for example posts into post
+func singularize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"), strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss"):
+		return name[:len(name)-1]
+	}
+	return name
+}
+
+// pluralize turns a singular name into the name of its resource, for example user into users
+func pluralize(name string) string {
+	switch {
+	case strings.HasSuffix(name, "y") && len(name) > 1 && !strings.ContainsAny(name[len(name)-2:len(name)-1], "aeiou"):
+		return name[:len(name)-1] + "ies"
+	case strings.HasSuffix(name, "s"), strings.HasSuffix(name, "x"), strings.HasSuffix(name, "ch"), strings.HasSuffix(name, "sh"):
+		return name + "es"
+	}
+	return name + "s"
+}
+
+// foreignKeySuffix is appended to a singular resource name to form the name of a reference field,
+// Id by default (postId), but some files use another convention like _id (post_id)
+func foreignKeySuffix(fileMetadata database.JsonFile) string {
+	if fileMetadata.ForeignKeySuffix == "" {
+		return defaultForeignKeySuffix
+	}
+	return fileMetadata.ForeignKeySuffix
+}
+
+// foreignKey returns the field that references items of resource, for example postId for posts
+func foreignKey(fileMetadata database.JsonFile, resource string) string {
+	return singularize(resource) + foreignKeySuffix(fileMetadata)
+}
+
+// parentResource finds the resource a singular name refers to, for example users for user
+func parentResource(fileContents map[string]any, name string) (string, bool) {
+	for _, candidate := range []string{pluralize(name), name} {
+		if _, ok := fileContents[candidate].([]any); ok {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// relationParams reads a relation query parameter, which can be repeated or comma separated
+func relationParams(query url.Values, param string) []string {
+	names := []string{}
+	for _, value := range query[param] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// embedRelations handles the _embed and _expand query parameters for items of resource.
+// ?_embed=comments adds the comments whose postId matches each post,
+// ?_expand=user adds the user referenced by the userId of each item.
+// Items are copied, so the file contents are not modified.
+func embedRelations(fileMetadata database.JsonFile, fileContents map[string]any, resource string, items []any, query url.Values) ([]any, error) {
+	embeds := relationParams(query, "_embed")
+	expands := relationParams(query, "_expand")
+	if len(embeds) == 0 && len(expands) == 0 {
+		return items, nil
+	}
+
+	for _, embed := range embeds {
+		if _, ok := fileContents[embed].([]any); !ok {
+			return nil, &QueryError{
+				Param:   "_embed",
+				Message: fmt.Sprintf("resource %q does not exist or is not an array", embed),
+			}
+		}
+	}
+	parents := map[string]string{}
+	for _, expand := range expands {
+		parent, ok := parentResource(fileContents, expand)
+		if !ok {
+			return nil, &QueryError{
+				Param:   "_expand",
+				Message: fmt.Sprintf("no resource found for %q", expand),
+			}
+		}
+		parents[expand] = parent
+	}
+
+	idField := resourceIdField(fileMetadata, resource)
+	childForeignKey := foreignKey(fileMetadata, resource)
+	result := []any{}
+	for _, item := range items {
+		itemMap, ok := item.(map[string]any)
+		if !ok {
+			result = append(result, item)
+			continue
+		}
+		itemMap = maps.Clone(itemMap)
+
+		for _, embed := range embeds {
+			children := []any{}
+			if itemId, ok := itemMap[idField]; ok && itemId != nil {
+				for _, child := range fileContents[embed].([]any) {
+					childMap, ok := child.(map[string]any)
+					if !ok {
+						continue
+					}
+					if reference, ok := childMap[childForeignKey]; ok && reference != nil && stringifyValue(reference) == stringifyValue(itemId) {
+						children = append(children, child)
+					}
+				}
+			}
+			itemMap[embed] = children
+		}
+
+		for _, expand := range expands {
+			reference, ok := itemMap[expand+foreignKeySuffix(fileMetadata)]
+			if !ok || reference == nil {
+				continue
+			}
+			parent := parents[expand]
+			parentItems := fileContents[parent].([]any)
+			if index := findItemIndex(parentItems, resourceIdField(fileMetadata, parent), stringifyValue(reference)); index != -1 {
+				itemMap[expand] = parentItems[index]
+			}
+		}
+		result = append(result, itemMap)
+	}
+	return result, nil
+}
+
+// relation is a parent-child relationship between two resources detected from a foreign key,
+// for example comments referencing posts through postId
+type relation struct {
+	parent     string
+	child      string
+	foreignKey string
+}
+
+// detectRelations finds resources whose items reference items of another resource by foreign key
+func detectRelations(fileMetadata database.JsonFile, fileContents map[string]any) []relation {
+	relations := []relation{}
+	for _, parent := range routeKeys(fileContents) {
+		if _, ok := fileContents[parent].([]any); !ok {
+			continue
+		}
+		key := foreignKey(fileMetadata, parent)
+		for _, child := range routeKeys(fileContents) {
+			children, ok := fileContents[child].([]any)
+			if !ok || child == parent {
+				continue
+			}
+			if fieldExists(children, []string{key}) {
+				relations = append(relations, relation{
+					parent:     parent,
+					child:      child,
+					foreignKey: key,
+				})
+			}
+		}
+	}
+	return relations
+}
+
+// childRoute is a path like /posts/1/comments, referring to the comments whose postId is the id of post 1
+type childRoute struct {
+	relation
+	parentId any
+	childId  string
+}
+
+// resolveChildRoute checks whether segments refer to the children of an item, either
+// /{parent}/{id}/{child} or /{parent}/{id}/{child}/{childId}.
+// Fields of the parent item take precedence, so /posts/1/comments refers to the comments field of post 1 if it has one.
+func resolveChildRoute(fileMetadata database.JsonFile, fileContents map[string]any, segments []string) (childRoute, bool) {
+	if len(segments) != 3 && len(segments) != 4 {
+		return childRoute{}, false
+	}
+	parent, child := segments[0], segments[2]
+	parentItems, ok := fileContents[parent].([]any)
+	if !ok {
+		return childRoute{}, false
+	}
+	if _, ok := fileContents[child].([]any); !ok {
+		return childRoute{}, false
+	}
+	index := findItemIndex(parentItems, resourceIdField(fileMetadata, parent), segments[1])
+	if index == -1 {
+		return childRoute{}, false
+	}
+	parentItem := parentItems[index].(map[string]any)
+	if _, ok := parentItem[child]; ok {
+		return childRoute{}, false
+	}
+
+	route := childRoute{
+		relation: relation{
+			parent:     parent,
+			child:      child,
+			foreignKey: foreignKey(fileMetadata, parent),
+		},
+		parentId: parentItem[resourceIdField(fileMetadata, parent)],
+	}
+	if len(segments) == 4 {
+		route.childId = segments[3]
+	}
+	return route, true
+}
+
+// belongsTo reports whether item references the parent of the route
+func (route childRoute) belongsTo(item any) bool {
+	itemMap, ok := item.(map[string]any)
+	if !ok {
+		return false
+	}
+	reference, ok := itemMap[route.foreignKey]
+	return ok && reference != nil && stringifyValue(reference) == stringifyValue(route.parentId)
+}
@@ -0,0 +1,144 @@
+package jsonfile
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/pl3lee/restjson/internal/auth"
+	"github.com/pl3lee/restjson/internal/database"
+	"github.com/pl3lee/restjson/internal/storage"
+	"github.com/pl3lee/restjson/internal/utils"
+)
+
+const (
+	revisionSourceSession = "session"
+	revisionSourceApiKey  = "api_key"
+)
+
+// recordRevision stores contents as the next revision of a json file, along with who wrote it.
+// Writes through the public api are attributed to the api key in the context, other writes to the session.
+func (cfg *JsonConfig) recordRevision(ctx context.Context, userId uuid.UUID, fileId uuid.UUID, contents any) (database.JsonRevision, error) {
+	data, err := json.Marshal(contents)
+	if err != nil {
+		return database.JsonRevision{}, fmt.Errorf("recordRevision: error marshalling contents: %w", err)
+	}
+	return cfg.recordRevisionData(ctx, userId, fileId, data)
+}
+
+// recordRevisionData is recordRevision for contents that are already marshalled
+func (cfg *JsonConfig) recordRevisionData(ctx context.Context, userId uuid.UUID, fileId uuid.UUID, data []byte) (database.JsonRevision, error) {
+	params := database.CreateJsonRevisionParams{
+		JsonFileID: fileId,
+		UserID:     userId,
+		Source:     revisionSourceSession,
+		Size:       int64(len(data)),
+		Checksum:   storage.Checksum(data),
+	}
+	if apiKey, ok := ctx.Value(auth.ApiKeyContextKey).(database.ApiKey); ok {
+		params.Source = revisionSourceApiKey
+		params.ApiKeyID = uuid.NullUUID{UUID: apiKey.ID, Valid: true}
+		params.ApiKeyName = apiKey.Name
+	}
+	revision, err := cfg.Db.CreateJsonRevision(ctx, params)
+	if err != nil {
+		return database.JsonRevision{}, fmt.Errorf("recordRevisionData: error creating revision: %w", err)
+	}
+
+	if _, err := storage.PutJsonRevision(ctx, cfg.Store, userId, fileId, revision.Revision, data); err != nil {
+		cfg.discardRevision(ctx, userId, revision)
+		return database.JsonRevision{}, fmt.Errorf("recordRevisionData: %w", err)
+	}
+	return revision, nil
+}
+
+// discardRevision removes a revision whose write did not go through, so that the history only has saved contents
+func (cfg *JsonConfig) discardRevision(ctx context.Context, userId uuid.UUID, revision database.JsonRevision) {
+	err := cfg.Db.DeleteJsonRevision(ctx, database.DeleteJsonRevisionParams{
+		JsonFileID: revision.JsonFileID,
+		Revision:   revision.Revision,
+	})
+	if err != nil {
+		log.Printf("discardRevision: error deleting revision %d: %v", revision.Revision, err)
+	}
+	if err := storage.DeleteJsonRevision(ctx, cfg.Store, userId, revision.JsonFileID, revision.Revision); err != nil {
+		log.Printf("discardRevision: %v", err)
+	}
+}
+
+// pruneRevisions deletes the revisions of a json file older than the newest cfg.MaxRevisions, except the ones snapshots point at.
+// Errors are only logged, since the write that recorded the newest revision already went through.
+func (cfg *JsonConfig) pruneRevisions(ctx context.Context, userId uuid.UUID, fileId uuid.UUID) {
+	if cfg.MaxRevisions <= 0 {
+		return
+	}
+	revisions, err := cfg.Db.DeleteOldJsonRevisions(ctx, database.DeleteOldJsonRevisionsParams{
+		JsonFileID: fileId,
+		Keep:       int32(cfg.MaxRevisions),
+	})
+	if err != nil {
+		log.Printf("pruneRevisions: error deleting old revisions of %s: %v", fileId, err)
+		return
+	}
+	for _, revision := range revisions {
+		if err := storage.DeleteJsonRevision(ctx, cfg.Store, userId, fileId, revision); err != nil {
+			log.Printf("pruneRevisions: %v", err)
+		}
+	}
+}
+
+// getRevision looks up a revision of a json file by its number,
+// responding with 400 when the number is not valid or 404 when there is no such revision
+func (cfg *JsonConfig) getRevision(w http.ResponseWriter, r *http.Request, fileMetadata database.JsonFile, rev string) (database.JsonRevision, bool) {
+	number, err := strconv.ParseInt(rev, 10, 32)
+	if err != nil || number < 1 {
+		utils.RespondWithError(w, http.StatusBadRequest, "invalid revision", err)
+		return database.JsonRevision{}, false
+	}
+	revision, err := cfg.Db.GetJsonRevision(r.Context(), database.GetJsonRevisionParams{
+		JsonFileID: fileMetadata.ID,
+		Revision:   int32(number),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		utils.RespondWithError(w, http.StatusNotFound, fmt.Sprintf("revision %d not found", number), err)
+		return database.JsonRevision{}, false
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error getting json revision", err)
+		return database.JsonRevision{}, false
+	}
+	return revision, true
+}
+
+// getRevisionContents responds with an error and returns false if the revision does not exist or cannot be read
+func (cfg *JsonConfig) getRevisionContents(w http.ResponseWriter, r *http.Request, fileMetadata database.JsonFile, rev string) (database.JsonRevision, any, bool) {
+	revision, ok := cfg.getRevision(w, r, fileMetadata, rev)
+	if !ok {
+		return database.JsonRevision{}, nil, false
+	}
+	contents, err := storage.GetJsonRevision(r.Context(), cfg.Store, fileMetadata.UserID, fileMetadata.ID, revision.Revision)
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "cannot get json revision from storage", err)
+		return database.JsonRevision{}, nil, false
+	}
+	return revision, contents, true
+}
+
+// currentRevision returns the revision holding the current contents of a json file.
+// Files written before revisions were recorded have none, so the current contents are recorded first.
+func (cfg *JsonConfig) currentRevision(ctx context.Context, userId uuid.UUID, fileMetadata database.JsonFile, contents any) (database.JsonRevision, error) {
+	revision, err := cfg.Db.GetLatestJsonRevision(ctx, fileMetadata.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return cfg.recordRevision(ctx, userId, fileMetadata.ID, contents)
+	}
+	if err != nil {
+		return database.JsonRevision{}, fmt.Errorf("currentRevision: error getting latest revision: %w", err)
+	}
+	return revision, nil
+}
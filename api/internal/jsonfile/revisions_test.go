@@ -0,0 +1,38 @@
+package jsonfile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/pl3lee/restjson/internal/storage"
+)
+
+func TestRevisionsArePruned(t *testing.T) {
+	s := newTestServer(t, `{"posts": []}`)
+	s.cfg.MaxRevisions = 3
+
+	for i := range 5 {
+		rec := s.do(t, http.MethodPost, fmt.Sprintf("/public/%s/posts", s.fileId), fmt.Sprintf(`{"title": "post %d"}`, i))
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+		}
+	}
+
+	revisions := s.db.fileRevisions(s.fileId)
+	if len(revisions) != 3 || revisions[0].Revision != 3 || revisions[2].Revision != 5 {
+		t.Fatalf("got revisions %+v, want revisions 3 to 5", revisions)
+	}
+	ctx := context.Background()
+	for revision := int32(1); revision <= 5; revision++ {
+		_, err := storage.GetJsonRevision(ctx, s.cfg.Store, s.userId, s.fileId, revision)
+		if revision < 3 && !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("got error %v for pruned revision %d, want storage.ErrNotFound", err, revision)
+		}
+		if revision >= 3 && err != nil {
+			t.Errorf("got error %v for revision %d, want it to be kept", err, revision)
+		}
+	}
+}
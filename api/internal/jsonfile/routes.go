@@ -0,0 +1,95 @@
+package jsonfile
+
+import (
+	"github.com/go-chi/chi/v5"
+)
+
+// WebRoutes registers the routes used by the web app to manage json files.
+// Authentication is up to the caller, the routes expect the user id in the context.
+func (cfg *JsonConfig) WebRoutes(r chi.Router) {
+	r.Post("/jsonfiles", cfg.HandlerCreateJson)
+	r.Get("/jsonfiles", cfg.HandlerGetJsonFiles)
+
+	r.Group(func(r chi.Router) {
+		r.Use(cfg.JsonFileMiddleware)
+
+		r.Get("/jsonfiles/{fileId}/settings", cfg.HandlerGetJsonSettings)
+		r.Patch("/jsonfiles/{fileId}/settings", cfg.HandlerUpdateJsonSettings)
+		r.Get("/jsonfiles/{fileId}/relationships", cfg.HandlerGetJsonRelationships)
+		r.Get("/jsonfiles/{fileId}/schemas", cfg.HandlerGetJsonSchemas)
+		r.Delete("/jsonfiles/{fileId}/schemas", cfg.HandlerDeleteJsonSchema)
+		r.Delete("/jsonfiles/{fileId}/schemas/{resource}", cfg.HandlerDeleteJsonSchema)
+		r.Get("/jsonfiles/{fileId}/revisions", cfg.HandlerGetJsonRevisions)
+		r.Get("/jsonfiles/{fileId}/revisions/{rev}", cfg.HandlerGetJsonRevision)
+		r.Get("/jsonfiles/{fileId}/snapshots", cfg.HandlerGetJsonSnapshots)
+		r.Delete("/jsonfiles/{fileId}/snapshots/{name}", cfg.HandlerDeleteJsonSnapshot)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(cfg.JsonFileMiddleware)
+		r.Use(cfg.MaxFileSizeMiddleware)
+		r.Use(cfg.FileWriteLockMiddleware)
+		r.Use(cfg.JsonFileContentMiddleware)
+
+		r.Get("/jsonfiles/{fileId}", cfg.HandlerGetJson)
+		r.Get("/jsonfiles/{fileId}/metadata", cfg.HandlerGetJsonMetadata)
+		r.Patch("/jsonfiles/{fileId}", cfg.HandlerRenameJsonFile)
+		r.Put("/jsonfiles/{fileId}", cfg.HandlerUpdateJson)
+		r.Delete("/jsonfiles/{fileId}", cfg.HandlerDeleteJsonFile)
+
+		r.Get("/jsonfiles/{fileId}/routes", cfg.HandlerGetDynamicRoutes)
+		r.Post("/jsonfiles/{fileId}/revisions/{rev}/restore", cfg.HandlerRestoreJsonRevision)
+		r.Get("/jsonfiles/{fileId}/diff", cfg.HandlerGetJsonDiff)
+		r.Post("/jsonfiles/{fileId}/snapshots", cfg.HandlerCreateJsonSnapshot)
+
+		r.Get("/jsonfiles/{fileId}/schema", cfg.HandlerInferJsonSchema)
+		r.Get("/jsonfiles/{fileId}/openapi.json", cfg.HandlerGetOpenAPIJson)
+		r.Get("/jsonfiles/{fileId}/openapi.yaml", cfg.HandlerGetOpenAPIYaml)
+		r.Get("/jsonfiles/{fileId}/types", cfg.HandlerGetTypes)
+		// relationships and schemas are checked against the current contents before they are attached
+		r.Put("/jsonfiles/{fileId}/relationships", cfg.HandlerUpdateJsonRelationships)
+		r.Put("/jsonfiles/{fileId}/schemas", cfg.HandlerUpdateJsonSchema)
+		r.Put("/jsonfiles/{fileId}/schemas/{resource}", cfg.HandlerUpdateJsonSchema)
+	})
+}
+
+// PublicRoutes registers the routes of the public api generated from the contents of json files.
+// Authentication and rate limiting are up to the caller, the routes expect the user id in the context.
+func (cfg *JsonConfig) PublicRoutes(r chi.Router) {
+	r.Group(func(r chi.Router) {
+		r.Use(cfg.JsonFileMiddleware)
+		r.Use(cfg.MaxFileSizeMiddleware)
+		r.Use(cfg.FileWriteLockMiddleware)
+		r.Use(cfg.JsonFileContentMiddleware)
+
+		r.Get("/{fileId}", cfg.HandlerGetJson)
+		r.Patch("/{fileId}", cfg.HandlerPartialUpdateJson)
+		r.Post("/{fileId}/graphql", cfg.HandlerGraphQL)
+		r.Post("/{fileId}/_reset", cfg.HandlerResetJson)
+
+		r.Group(func(r chi.Router) {
+			r.Use(cfg.ResourceMiddleware)
+
+			r.Get("/{fileId}/{resource}", cfg.HandlerGetResource)
+			r.Put("/{fileId}/{resource}", cfg.HandlerUpdateResource)
+			r.Patch("/{fileId}/{resource}", cfg.HandlerPartialUpdateResource)
+
+			// paths nested deeper than an item, e.g. /{fileId}/users/1/addresses/2
+			r.Get("/{fileId}/{resource}/*", cfg.HandlerResourcePath)
+			r.Post("/{fileId}/{resource}/*", cfg.HandlerResourcePath)
+			r.Put("/{fileId}/{resource}/*", cfg.HandlerResourcePath)
+			r.Patch("/{fileId}/{resource}/*", cfg.HandlerResourcePath)
+			r.Delete("/{fileId}/{resource}/*", cfg.HandlerResourcePath)
+
+			r.Group(func(r chi.Router) {
+				r.Use(cfg.ResourceArrayMiddleware)
+
+				r.Get("/{fileId}/{resource}/{id}", cfg.HandlerGetResourceItem)
+				r.Post("/{fileId}/{resource}", cfg.HandlerCreateResourceItem)
+				r.Put("/{fileId}/{resource}/{id}", cfg.HandlerUpdateResourceItem)
+				r.Patch("/{fileId}/{resource}/{id}", cfg.HandlerPartialUpdateResourceItem)
+				r.Delete("/{fileId}/{resource}/{id}", cfg.HandlerDeleteResourceItem)
+			})
+		})
+	})
+}
@@ -0,0 +1,132 @@
+package jsonfile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/pl3lee/restjson/internal/auth"
+	"github.com/pl3lee/restjson/internal/database"
+	"github.com/pl3lee/restjson/internal/jsonschema"
+	"github.com/pl3lee/restjson/internal/storage"
+	"github.com/pl3lee/restjson/internal/utils"
+)
+
+// validateContents validates the contents of a json file against the schemas attached to it.
+// The schema of the file validates the whole document, and the schema of a resource validates the value of that resource.
+func (cfg *JsonConfig) validateContents(ctx context.Context, fileMetadata database.JsonFile, contents any) ([]jsonschema.ValidationError, error) {
+	schemas, err := cfg.Db.GetJsonSchemas(ctx, fileMetadata.ID)
+	if err != nil {
+		return nil, fmt.Errorf("validateContents: error getting schemas: %w", err)
+	}
+
+	validationErrors := []jsonschema.ValidationError{}
+	for _, schema := range schemas {
+		compiled, err := jsonschema.Parse(schema.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("validateContents: invalid schema for resource %q: %w", schema.Resource, err)
+		}
+		validationErrors = append(validationErrors, validateWithSchema(compiled, schema.Resource, contents)...)
+	}
+	return validationErrors, nil
+}
+
+// validateWithSchema validates the whole contents when resource is empty, otherwise only the value of resource
+func validateWithSchema(schema *jsonschema.Schema, resource string, contents any) []jsonschema.ValidationError {
+	if resource == "" {
+		return schema.Validate(contents)
+	}
+	contentsMap, ok := contents.(map[string]any)
+	if !ok {
+		return nil
+	}
+	value, ok := contentsMap[resource]
+	if !ok {
+		return nil
+	}
+	return schema.ValidateAt(value, []string{resource})
+}
+
+// saveFileContents validates the updated contents of a json file against its schemas and uploads them.
+// It responds with 422 and the location of every validation error when the contents do not match,
+// with 422 or 409 when they break a relationship (see respondWithReferenceError),
+// with 413 when they are larger than the plan of the user allows,
+// or with 500 when they cannot be saved, and returns false in all cases.
+func (cfg *JsonConfig) saveFileContents(w http.ResponseWriter, r *http.Request, fileMetadata database.JsonFile, contents any) bool {
+	userId := r.Context().Value(auth.UserIDContextKey).(uuid.UUID)
+
+	validationErrors, err := cfg.writeFileContents(r.Context(), userId, fileMetadata, contents)
+	var sizeErr *fileSizeError
+	if errors.As(err, &sizeErr) {
+		respondWithFileSizeError(w, sizeErr)
+		return false
+	}
+	var referenceErr *ReferenceError
+	if errors.As(err, &referenceErr) {
+		respondWithReferenceError(w, referenceErr)
+		return false
+	}
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "failed to save updated file contents", err)
+		return false
+	}
+	if len(validationErrors) > 0 {
+		utils.RespondWithErrorDetails(w, http.StatusUnprocessableEntity, "json does not match schema", validationErrors, nil)
+		return false
+	}
+	return true
+}
+
+// writeFileContents uploads the contents of a json file and records them as a new revision if they match its schemas,
+// otherwise nothing is uploaded and the validation errors are returned.
+// The relationships of the file are enforced first: the onDelete rules of items removed since the current contents
+// are applied to contents, and a broken reference returns a *ReferenceError without uploading anything.
+// Contents larger than the maximum file size of the user are not uploaded either, and return a *fileSizeError.
+func (cfg *JsonConfig) writeFileContents(ctx context.Context, userId uuid.UUID, fileMetadata database.JsonFile, contents any) ([]jsonschema.ValidationError, error) {
+	if len(fileRelationships(fileMetadata)) > 0 {
+		// handlers update the contents they read in place, so the current contents are read again
+		previous, err := storage.GetJson(ctx, cfg.Store, cfg.Rdb, userId, fileMetadata.ID)
+		if err != nil {
+			return nil, fmt.Errorf("writeFileContents: error getting current contents: %w", err)
+		}
+		if err := enforceReferences(fileMetadata, previous, contents); err != nil {
+			return nil, err
+		}
+	}
+
+	validationErrors, err := cfg.validateContents(ctx, fileMetadata, contents)
+	if err != nil {
+		return nil, fmt.Errorf("writeFileContents: error validating json against schema: %w", err)
+	}
+	if len(validationErrors) > 0 {
+		return validationErrors, nil
+	}
+
+	data, err := json.Marshal(contents)
+	if err != nil {
+		return nil, fmt.Errorf("writeFileContents: error marshalling contents: %w", err)
+	}
+	maxSize, err := cfg.maxFileSize(ctx, userId)
+	if err != nil {
+		return nil, fmt.Errorf("writeFileContents: %w", err)
+	}
+	if int64(len(data)) > maxSize {
+		return nil, &fileSizeError{Size: int64(len(data)), Limit: maxSize}
+	}
+
+	revision, err := cfg.recordRevisionData(ctx, userId, fileMetadata.ID, data)
+	if err != nil {
+		return nil, fmt.Errorf("writeFileContents: failed to record revision: %w", err)
+	}
+	// the marshalled data is checked against the size limit, stored as the revision and cached,
+	// so it is uploaded from memory rather than encoded again into a pipe
+	if _, err := storage.PutJsonData(ctx, cfg.Store, cfg.Rdb, userId, fileMetadata.ID, data); err != nil {
+		cfg.discardRevision(ctx, userId, revision)
+		return nil, fmt.Errorf("writeFileContents: failed to save file contents: %w", err)
+	}
+	cfg.pruneRevisions(ctx, userId, fileMetadata.ID)
+	return nil, nil
+}
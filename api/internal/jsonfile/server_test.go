@@ -0,0 +1,142 @@
+package jsonfile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/pl3lee/restjson/internal/auth"
+	"github.com/pl3lee/restjson/internal/database"
+	"github.com/pl3lee/restjson/internal/storage"
+	"github.com/redis/go-redis/v9"
+)
+
+// testServer serves the web and public routes of a single user, who owns a single json file,
+// backed by an in memory database, store and redis
+type testServer struct {
+	cfg    *JsonConfig
+	db     *fakeDb
+	redis  *miniredis.Miniredis
+	router http.Handler
+	userId uuid.UUID
+	fileId uuid.UUID
+}
+
+func newTestServer(t *testing.T, contents string) *testServer {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { rdb.Close() })
+
+	db := newFakeDb()
+	s := &testServer{
+		db:     db,
+		redis:  mr,
+		userId: uuid.New(),
+		fileId: uuid.New(),
+	}
+	s.cfg = &JsonConfig{
+		Db:              db.queries(),
+		BaseURL:         "http://localhost:8080",
+		Store:           storage.NewMemoryStore(),
+		Rdb:             rdb,
+		FreeFileLimit:   5,
+		ProFileLimit:    100,
+		FreeMaxFileSize: 1 << 20,
+		ProMaxFileSize:  10 << 20,
+	}
+
+	now := time.Now()
+	db.addUser(database.User{ID: s.userId, CreatedAt: now, UpdatedAt: now, Email: "test@example.com", Name: "test"})
+	db.addFile(database.JsonFile{
+		ID:               s.fileId,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+		UserID:           s.userId,
+		FileName:         "test",
+		Url:              s.cfg.Store.URL(storage.FileKey(s.userId, s.fileId)),
+		IdStrategy:       IdStrategyIncrement,
+		IdField:          defaultIdField,
+		ResourceIdFields: json.RawMessage(`{}`),
+		ForeignKeySuffix: defaultForeignKeySuffix,
+		Relationships:    json.RawMessage(`[]`),
+	})
+	var decoded any
+	if err := json.Unmarshal([]byte(contents), &decoded); err != nil {
+		t.Fatalf("invalid test contents: %v", err)
+	}
+	if _, err := storage.PutJson(context.Background(), s.cfg.Store, rdb, s.userId, s.fileId, decoded); err != nil {
+		t.Fatalf("error saving test contents: %v", err)
+	}
+
+	// the same routes as the api, with the user already authenticated
+	r := chi.NewRouter()
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), auth.UserIDContextKey, s.userId)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+	s.cfg.WebRoutes(r)
+	r.Route("/public", s.cfg.PublicRoutes)
+	s.router = r
+	return s
+}
+
+// do sends a request to the server, body is sent as is when it is a string and encoded as json otherwise
+func (s *testServer) do(t *testing.T, method string, path string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	return s.doWithContentType(t, method, path, "application/json", body)
+}
+
+func (s *testServer) doWithContentType(t *testing.T, method string, path string, contentType string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	switch body := body.(type) {
+	case nil:
+	case string:
+		reader = bytes.NewBufferString(body)
+	default:
+		data, err := json.Marshal(body)
+		if err != nil {
+			t.Fatalf("invalid request body: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec
+}
+
+// contents reads the current contents of the json file from the store, skipping the cache
+func (s *testServer) contents(t *testing.T) map[string]any {
+	t.Helper()
+	data, err := s.cfg.Store.Get(context.Background(), storage.FileKey(s.userId, s.fileId))
+	if err != nil {
+		t.Fatalf("error reading contents: %v", err)
+	}
+	contents := map[string]any{}
+	if err := json.Unmarshal(data, &contents); err != nil {
+		t.Fatalf("invalid contents: %v", err)
+	}
+	return contents
+}
+
+func decodeBody[T any](t *testing.T, rec *httptest.ResponseRecorder) T {
+	t.Helper()
+	var value T
+	if err := json.Unmarshal(rec.Body.Bytes(), &value); err != nil {
+		t.Fatalf("invalid response body %q: %v", rec.Body.String(), err)
+	}
+	return value
+}
@@ -0,0 +1,74 @@
+package jsonfile
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"testing"
+)
+
+func TestUpdateJsonSettings(t *testing.T) {
+	s := newTestServer(t, `{"articles": []}`)
+	path := fmt.Sprintf("/jsonfiles/%s/settings", s.fileId)
+
+	steps := []struct {
+		name   string
+		body   string
+		status int
+		want   JsonSettingsResponse
+	}{
+		{
+			name:   "set every setting",
+			body:   `{"idStrategy": "uuid", "idField": "_id", "resourceIdFields": {"articles": "slug", "users": "email"}, "foreignKeySuffix": "_id"}`,
+			status: http.StatusOK,
+			want:   JsonSettingsResponse{IdStrategy: "uuid", IdField: "_id", ResourceIdFields: map[string]string{"articles": "slug", "users": "email"}, ForeignKeySuffix: "_id"},
+		},
+		{
+			name:   "omitted settings are kept",
+			body:   `{"idStrategy": "increment"}`,
+			status: http.StatusOK,
+			want:   JsonSettingsResponse{IdStrategy: "increment", IdField: "_id", ResourceIdFields: map[string]string{"articles": "slug", "users": "email"}, ForeignKeySuffix: "_id"},
+		},
+		{
+			name:   "resource id fields are merged",
+			body:   `{"resourceIdFields": {"articles": "uuid", "users": null, "tags": "name"}}`,
+			status: http.StatusOK,
+			want:   JsonSettingsResponse{IdStrategy: "increment", IdField: "_id", ResourceIdFields: map[string]string{"articles": "uuid", "tags": "name"}, ForeignKeySuffix: "_id"},
+		},
+		{
+			name:   "empty strings reset to the defaults",
+			body:   `{"idStrategy": "", "idField": "", "foreignKeySuffix": ""}`,
+			status: http.StatusOK,
+			want:   JsonSettingsResponse{IdStrategy: "increment", IdField: "id", ResourceIdFields: map[string]string{"articles": "uuid", "tags": "name"}, ForeignKeySuffix: "Id"},
+		},
+		{
+			name:   "invalid id strategy",
+			body:   `{"idStrategy": "random", "idField": "key"}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "empty resource id field",
+			body:   `{"resourceIdFields": {"articles": ""}}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "rejected requests change nothing",
+			body:   `{}`,
+			status: http.StatusOK,
+			want:   JsonSettingsResponse{IdStrategy: "increment", IdField: "id", ResourceIdFields: map[string]string{"articles": "uuid", "tags": "name"}, ForeignKeySuffix: "Id"},
+		},
+	}
+	for _, step := range steps {
+		rec := s.do(t, http.MethodPatch, path, step.body)
+		if rec.Code != step.status {
+			t.Fatalf("%s: got status %d, want %d: %s", step.name, rec.Code, step.status, rec.Body.String())
+		}
+		if step.status != http.StatusOK {
+			continue
+		}
+		got := decodeBody[JsonSettingsResponse](t, rec)
+		if got.IdStrategy != step.want.IdStrategy || got.IdField != step.want.IdField || got.ForeignKeySuffix != step.want.ForeignKeySuffix || !maps.Equal(got.ResourceIdFields, step.want.ResourceIdFields) {
+			t.Errorf("%s: got settings %+v, want %+v", step.name, got, step.want)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package jsonfile
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/pl3lee/restjson/internal/utils"
+)
+
+// fileSizeError is returned when a json file would be larger than the plan of its owner allows.
+// Size is unknown, and zero, when the request body alone was already too large.
+type fileSizeError struct {
+	Size  int64 `json:"size,omitempty"`
+	Limit int64 `json:"limit"`
+}
+
+func (e *fileSizeError) Error() string {
+	return fmt.Sprintf("json file exceeds the maximum size of %d bytes", e.Limit)
+}
+
+func respondWithFileSizeError(w http.ResponseWriter, err *fileSizeError) {
+	utils.RespondWithErrorDetails(w, http.StatusRequestEntityTooLarge, err.Error(), err, nil)
+}
+
+// maxFileSize returns the maximum size in bytes of the json files of a user, which depends on their plan
+func (cfg *JsonConfig) maxFileSize(ctx context.Context, userId uuid.UUID) (int64, error) {
+	if maxSize, ok := ctx.Value(MaxFileSizeContextKey).(int64); ok {
+		return maxSize, nil
+	}
+	user, err := cfg.Db.GetUserById(ctx, userId)
+	if err != nil {
+		return 0, fmt.Errorf("maxFileSize: error getting user: %w", err)
+	}
+	if user.Subscribed {
+		return cfg.ProMaxFileSize, nil
+	}
+	return cfg.FreeMaxFileSize, nil
+}
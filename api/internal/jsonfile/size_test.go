@@ -0,0 +1,110 @@
+package jsonfile
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/pl3lee/restjson/internal/storage"
+)
+
+type fileSizeErrorResponse struct {
+	Error   string        `json:"error"`
+	Details fileSizeError `json:"details"`
+}
+
+func TestMetadataSizes(t *testing.T) {
+	s := newTestServer(t, `{"posts": []}`)
+	if rec := s.do(t, http.MethodPost, fmt.Sprintf("/public/%s/posts", s.fileId), `{"title": "hello"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusCreated, rec.Body.String())
+	}
+	data, err := json.Marshal(s.contents(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := int64(len(data))
+
+	rec := s.do(t, http.MethodGet, "/jsonfiles", nil)
+	files := decodeBody[[]JsonMetadataResponse](t, rec)
+	if len(files) != 1 || files[0].Size != size || files[0].MaxSize != s.cfg.FreeMaxFileSize {
+		t.Errorf("got files %+v, want size %d and max size %d", files, size, s.cfg.FreeMaxFileSize)
+	}
+
+	for _, req := range []struct {
+		method string
+		path   string
+		body   string
+	}{
+		{method: http.MethodGet, path: "/jsonfiles/%s/metadata"},
+		{method: http.MethodPatch, path: "/jsonfiles/%s", body: `{"fileName": "renamed"}`},
+	} {
+		rec := s.do(t, req.method, fmt.Sprintf(req.path, s.fileId), req.body)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("%s %s: got status %d, want %d: %s", req.method, req.path, rec.Code, http.StatusOK, rec.Body.String())
+		}
+		metadata := decodeBody[JsonMetadataResponse](t, rec)
+		if metadata.Size != size || metadata.MaxSize != s.cfg.FreeMaxFileSize || metadata.ModifiedAt.IsZero() {
+			t.Errorf("%s %s: got metadata %+v, want size %d and max size %d", req.method, req.path, metadata, size, s.cfg.FreeMaxFileSize)
+		}
+	}
+}
+
+func TestSizeOfFileWithoutRevisions(t *testing.T) {
+	s := newTestServer(t, `{"posts": [{"id": 1, "title": "hello"}]}`)
+	data, err := s.cfg.Store.Get(context.Background(), storage.FileKey(s.userId, s.fileId))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rec := s.do(t, http.MethodGet, "/jsonfiles", nil)
+	files := decodeBody[[]JsonMetadataResponse](t, rec)
+	if len(files) != 1 || files[0].Size != int64(len(data)) {
+		t.Errorf("got files %+v, want the size of the stored contents, %d", files, len(data))
+	}
+}
+
+func TestRequestBodyTooLarge(t *testing.T) {
+	s := newTestServer(t, `{"posts": []}`)
+	s.cfg.FreeMaxFileSize = 100
+
+	body := fmt.Sprintf(`{"title": %q}`, strings.Repeat("a", 100))
+	rec := s.do(t, http.MethodPost, fmt.Sprintf("/public/%s/posts", s.fileId), body)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusRequestEntityTooLarge, rec.Body.String())
+	}
+	got := decodeBody[fileSizeErrorResponse](t, rec)
+	if got.Error != "json file exceeds the maximum size of 100 bytes" || got.Details != (fileSizeError{Limit: 100}) {
+		t.Errorf("got response %s", rec.Body.String())
+	}
+	// the size of the file is unknown, since the body was not read
+	if strings.Contains(rec.Body.String(), `"size"`) {
+		t.Errorf("got response %s, want no size", rec.Body.String())
+	}
+}
+
+func TestUpdatedContentsTooLarge(t *testing.T) {
+	s := newTestServer(t, fmt.Sprintf(`{"posts": [{"id": 1, "title": %q}]}`, strings.Repeat("a", 60)))
+	s.cfg.FreeMaxFileSize = 100
+
+	// the body is under the limit, but the file it is added to would not be
+	body := fmt.Sprintf(`{"title": %q}`, strings.Repeat("b", 20))
+	rec := s.do(t, http.MethodPost, fmt.Sprintf("/public/%s/posts", s.fileId), body)
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("got status %d, want %d: %s", rec.Code, http.StatusRequestEntityTooLarge, rec.Body.String())
+	}
+	wantSize := len(fmt.Sprintf(`{"posts":[{"id":1,"title":%q},{"id":2,"title":%q}]}`, strings.Repeat("a", 60), strings.Repeat("b", 20)))
+	got := decodeBody[fileSizeErrorResponse](t, rec)
+	if got.Error != "json file exceeds the maximum size of 100 bytes" || got.Details != (fileSizeError{Size: int64(wantSize), Limit: 100}) {
+		t.Errorf("got response %s, want size %d", rec.Body.String(), wantSize)
+	}
+
+	if posts := s.contents(t)["posts"].([]any); len(posts) != 1 {
+		t.Errorf("got %d posts, want the write to be rejected", len(posts))
+	}
+	if revisions := s.db.fileRevisions(s.fileId); len(revisions) != 0 {
+		t.Errorf("got %d revisions, want none", len(revisions))
+	}
+}
@@ -0,0 +1,70 @@
+package jsonfile
+
+import (
+	"fmt"
+	"go/token"
+	"net/http"
+
+	"github.com/pl3lee/restjson/internal/database"
+	"github.com/pl3lee/restjson/internal/typegen"
+	"github.com/pl3lee/restjson/internal/utils"
+)
+
+// HandlerGetTypes generates type definitions for the resources of a json file,
+// ?lang= chooses typescript (the default), go or zod, and ?package= names the go package
+func (cfg *JsonConfig) HandlerGetTypes(w http.ResponseWriter, r *http.Request) {
+	fileMetadata := r.Context().Value(FileMetadataContextKey).(database.JsonFile)
+	fileContents, ok := r.Context().Value(FileContentContextKey).(map[string]any)
+	if !ok {
+		utils.RespondWithError(w, http.StatusBadRequest, "json file is not a map", nil)
+		return
+	}
+
+	lang := r.URL.Query().Get("lang")
+	if lang == "" {
+		lang = typegen.LangTypeScript
+	}
+	if !typegen.IsValidLang(lang) {
+		utils.RespondWithError(w, http.StatusBadRequest, fmt.Sprintf("lang must be %q, %q or %q", typegen.LangTypeScript, typegen.LangGo, typegen.LangZod), nil)
+		return
+	}
+	packageName := r.URL.Query().Get("package")
+	if packageName != "" && !token.IsIdentifier(packageName) {
+		utils.RespondWithError(w, http.StatusBadRequest, "package must be a valid go identifier", nil)
+		return
+	}
+
+	source, err := typegen.Generate(lang, typeDefinitions(fileContents), typegen.Options{
+		Package:  packageName,
+		Singular: singularize,
+	})
+	if err != nil {
+		utils.RespondWithError(w, http.StatusInternalServerError, "error generating types", err)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "// Code generated by RestJSON from %s. DO NOT EDIT.\n\n%s", fileMetadata.FileName, source)
+}
+
+// typeDefinitions names a type after each resource, walking the resources like HandlerGetDynamicRoutes.
+// Arrays of objects are named after their items, so posts becomes Post.
+func typeDefinitions(fileContents map[string]any) []typegen.Definition {
+	definitions := []typegen.Definition{}
+	for _, key := range routeKeys(fileContents) {
+		items, ok := fileContents[key].([]any)
+		if !ok || !hasIds(items) {
+			definitions = append(definitions, typegen.Definition{
+				Name:   pascalCase(key),
+				Schema: inferSchema(fileContents[key]),
+			})
+			continue
+		}
+
+		definitions = append(definitions, typegen.Definition{
+			Name:   itemTypeName(key),
+			Schema: itemsSchema(items),
+		})
+	}
+	return definitions
+}
@@ -0,0 +1,207 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+// maxLcsCells bounds the table used to align arrays, larger arrays are compared item by item
+const maxLcsCells = 1_000_000
+
+// Summary lists the paths added, removed and changed by a diff.
+// Removed paths point into the original document, added and changed paths into the updated one.
+type Summary struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+	Changed []string `json:"changed"`
+}
+
+// Diff returns a JSON Patch that turns from into to, along with a summary of the paths it touches.
+// Objects are compared field by field and arrays are aligned on their unchanged items,
+// so inserting an item at the start of an array is a single add rather than a change to every item.
+func Diff(from any, to any) ([]Operation, Summary) {
+	d := &differ{
+		operations: []Operation{},
+		summary:    Summary{Added: []string{}, Removed: []string{}, Changed: []string{}},
+	}
+	d.diff(from, to, location{})
+	return d.operations, d.summary
+}
+
+type differ struct {
+	operations []Operation
+	summary    Summary
+}
+
+// location is where a value is in the document being patched, which changes as operations are applied,
+// and where it is in the original and updated documents
+type location struct {
+	patch []string
+	from  []string
+	to    []string
+}
+
+// child is the location of a field or item whose key is the same in every document
+func (l location) child(key string) location {
+	return l.descend(key, key, key)
+}
+
+func (l location) descend(patch string, from string, to string) location {
+	return location{
+		patch: append(slices.Clone(l.patch), patch),
+		from:  append(slices.Clone(l.from), from),
+		to:    append(slices.Clone(l.to), to),
+	}
+}
+
+func (d *differ) diff(from any, to any, loc location) {
+	switch from := from.(type) {
+	case map[string]any:
+		if to, ok := to.(map[string]any); ok {
+			d.diffObjects(from, to, loc)
+			return
+		}
+	case []any:
+		if to, ok := to.([]any); ok {
+			d.diffArrays(from, to, loc)
+			return
+		}
+	}
+	if !Equal(from, to) {
+		d.operations = append(d.operations, Operation{Op: "replace", Path: FormatPointer(loc.patch), Value: rawValue(to)})
+		d.summary.Changed = append(d.summary.Changed, FormatPointer(loc.to))
+	}
+}
+
+func (d *differ) diffObjects(from map[string]any, to map[string]any, loc location) {
+	for _, key := range sortedKeys(from) {
+		if _, ok := to[key]; !ok {
+			d.remove(loc.child(key))
+		}
+	}
+	for _, key := range sortedKeys(to) {
+		if fromValue, ok := from[key]; ok {
+			d.diff(fromValue, to[key], loc.child(key))
+		} else {
+			d.add(loc.child(key), to[key])
+		}
+	}
+}
+
+// diffArrays aligns the items of both arrays on their longest common subsequence.
+// Items between two aligned items are compared pairwise, and the rest are removed or added.
+func (d *differ) diffArrays(from []any, to []any, loc location) {
+	// the patch is applied in order, so index is where the next item is in the document being patched
+	index, i, j := 0, 0, 0
+	for _, match := range commonItems(itemKeys(from), itemKeys(to)) {
+		index = d.diffRange(from, to, loc, index, i, match[0], j, match[1])
+		i, j = match[0]+1, match[1]+1
+		index++
+	}
+	d.diffRange(from, to, loc, index, i, len(from), j, len(to))
+}
+
+// diffRange compares from[i:iEnd] with to[j:jEnd], which are not aligned with each other,
+// and returns the index of the next item in the document being patched
+func (d *differ) diffRange(from []any, to []any, loc location, index int, i int, iEnd int, j int, jEnd int) int {
+	for ; i < iEnd && j < jEnd; i, j = i+1, j+1 {
+		d.diff(from[i], to[j], loc.descend(strconv.Itoa(index), strconv.Itoa(i), strconv.Itoa(j)))
+		index++
+	}
+	for ; i < iEnd; i++ {
+		d.remove(loc.descend(strconv.Itoa(index), strconv.Itoa(i), ""))
+	}
+	for ; j < jEnd; j++ {
+		d.add(loc.descend(strconv.Itoa(index), "", strconv.Itoa(j)), to[j])
+		index++
+	}
+	return index
+}
+
+func (d *differ) add(loc location, value any) {
+	d.operations = append(d.operations, Operation{Op: "add", Path: FormatPointer(loc.patch), Value: rawValue(value)})
+	d.summary.Added = append(d.summary.Added, FormatPointer(loc.to))
+}
+
+func (d *differ) remove(loc location) {
+	d.operations = append(d.operations, Operation{Op: "remove", Path: FormatPointer(loc.patch)})
+	d.summary.Removed = append(d.summary.Removed, FormatPointer(loc.from))
+}
+
+// itemKeys encodes every item of an array, so that items can be compared as strings.
+// encoding/json sorts the keys of maps, so equal items have equal keys.
+func itemKeys(items []any) []string {
+	keys := make([]string, len(items))
+	for i, item := range items {
+		keys[i] = string(rawValue(item))
+	}
+	return keys
+}
+
+// commonItems returns the positions of the items of a longest common subsequence of from and to, in order.
+// Unchanged items at the start and end are matched first, and the rest is only aligned if it is small enough.
+func commonItems(from []string, to []string) [][2]int {
+	matches := [][2]int{}
+	start := 0
+	for start < len(from) && start < len(to) && from[start] == to[start] {
+		matches = append(matches, [2]int{start, start})
+		start++
+	}
+	end := 0
+	for end < len(from)-start && end < len(to)-start && from[len(from)-1-end] == to[len(to)-1-end] {
+		end++
+	}
+
+	fromMiddle, toMiddle := from[start:len(from)-end], to[start:len(to)-end]
+	n, m := len(fromMiddle), len(toMiddle)
+	if n > 0 && m > 0 && n*m <= maxLcsCells {
+		// lengths[i][j] is the length of the longest common subsequence of fromMiddle[i:] and toMiddle[j:]
+		lengths := make([][]int, n+1)
+		for i := range lengths {
+			lengths[i] = make([]int, m+1)
+		}
+		for i := n - 1; i >= 0; i-- {
+			for j := m - 1; j >= 0; j-- {
+				if fromMiddle[i] == toMiddle[j] {
+					lengths[i][j] = lengths[i+1][j+1] + 1
+				} else {
+					lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+				}
+			}
+		}
+		for i, j := 0, 0; i < n && j < m; {
+			switch {
+			case fromMiddle[i] == toMiddle[j]:
+				matches = append(matches, [2]int{start + i, start + j})
+				i++
+				j++
+			case lengths[i+1][j] >= lengths[i][j+1]:
+				i++
+			default:
+				j++
+			}
+		}
+	}
+
+	for k := end; k > 0; k-- {
+		matches = append(matches, [2]int{len(from) - k, len(to) - k})
+	}
+	return matches
+}
+
+func sortedKeys(object map[string]any) []string {
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// rawValue encodes a decoded json value, which cannot fail
+func rawValue(value any) json.RawMessage {
+	data, _ := json.Marshal(value)
+	return data
+}
@@ -0,0 +1,115 @@
+package jsonpatch
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestDiffApply(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		to   string
+	}{
+		{"equal", `{"a": [1, {"b": null}]}`, `{"a": [1, {"b": null}]}`},
+		{"object members", `{"a": 1, "b": 2, "c": {"d": 3}}`, `{"b": 3, "c": {"e": 4}, "f": [5]}`},
+		{"escaped keys", `{"a/b": 1, "m~n": {"~1": 2}}`, `{"a/b": 2, "m~n": {"~1": 3, "/": 4}}`},
+		{"insert at the start", `[1, 2, 3]`, `[0, 1, 2, 3]`},
+		{"insert in the middle", `[1, 2, 3]`, `[1, 2, 2.5, 3]`},
+		{"remove items", `[1, 2, 3, 4, 5]`, `[2, 4]`},
+		{"reorder items", `["a", "b", "c", "d"]`, `["d", "c", "b", "a"]`},
+		{"replace every item", `[1, 2]`, `["x", "y", "z"]`},
+		{"empty arrays", `{"a": [], "b": [1]}`, `{"a": [1], "b": []}`},
+		{"duplicate items", `[1, 1, 2, 1]`, `[1, 2, 1, 1, 2]`},
+		{"changed items", `[{"id": 1, "name": "a"}, {"id": 2, "name": "b"}]`, `[{"id": 2, "name": "b"}, {"id": 1, "name": "c"}, {"id": 3}]`},
+		{"nested arrays", `{"a": [[1, 2], [3]]}`, `{"a": [[3], [1, 2, 4]]}`},
+		{"type changes", `{"a": [1], "b": {"c": 1}, "d": "1"}`, `{"a": {"0": 1}, "b": [1], "d": 1}`},
+		{"null values", `{"a": null, "b": 1}`, `{"a": 1, "b": null}`},
+		{"different roots", `{"a": 1}`, `[1]`},
+		{"scalar roots", `1`, `"1"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertDiffApplies(t, decode(t, tt.from), decode(t, tt.to))
+		})
+	}
+}
+
+// arrays too large to align are compared item by item, which still produces a valid patch
+func TestDiffLargeArrays(t *testing.T) {
+	from, to := []any{}, []any{"first"}
+	for i := 0; i < 1500; i++ {
+		from = append(from, float64(i))
+		if i%3 != 0 {
+			to = append(to, float64(i))
+		}
+	}
+	to = append(to, "last")
+	assertDiffApplies(t, from, to)
+}
+
+func assertDiffApplies(t *testing.T, from any, to any) {
+	t.Helper()
+	original := DeepCopy(from)
+	operations, _ := Diff(from, to)
+	result, err := Apply(from, operations)
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if !Equal(result, to) {
+		t.Errorf("got %v, want %v, with operations %s", result, to, formatOperations(operations))
+	}
+	if !Equal(from, original) {
+		t.Errorf("Diff changed from to %v", from)
+	}
+	if Equal(from, to) && len(operations) > 0 {
+		t.Errorf("got operations %s for equal documents", formatOperations(operations))
+	}
+}
+
+func TestDiffOperations(t *testing.T) {
+	operations, summary := Diff(
+		decode(t, `{"posts": [{"id": 1}, {"id": 2}], "title": "a", "old": true}`),
+		decode(t, `{"posts": [{"id": 0}, {"id": 1}, {"id": 2}], "title": "b", "new": true}`),
+	)
+	want := []string{
+		`remove /old`,
+		`add /new true`,
+		`add /posts/0 {"id":0}`,
+		`replace /title "b"`,
+	}
+	if got := formatOperations(operations); !reflect.DeepEqual(got, want) {
+		t.Errorf("got operations %q, want %q", got, want)
+	}
+	wantSummary := Summary{Added: []string{"/new", "/posts/0"}, Removed: []string{"/old"}, Changed: []string{"/title"}}
+	if !reflect.DeepEqual(summary, wantSummary) {
+		t.Errorf("got summary %+v, want %+v", summary, wantSummary)
+	}
+}
+
+// the summary points removed paths into from and the others into to, while the operations
+// point into the document as it is being patched
+func TestDiffSummaryPaths(t *testing.T) {
+	operations, summary := Diff(decode(t, `["a", "b", "c"]`), decode(t, `["b", "x", "c"]`))
+	want := []string{`remove /0`, `add /1 "x"`}
+	if got := formatOperations(operations); !reflect.DeepEqual(got, want) {
+		t.Errorf("got operations %q, want %q", got, want)
+	}
+	wantSummary := Summary{Added: []string{"/1"}, Removed: []string{"/0"}, Changed: []string{}}
+	if !reflect.DeepEqual(summary, wantSummary) {
+		t.Errorf("got summary %+v, want %+v", summary, wantSummary)
+	}
+}
+
+func formatOperations(operations []Operation) []string {
+	formatted := []string{}
+	for _, operation := range operations {
+		if operation.Value == nil {
+			formatted = append(formatted, fmt.Sprintf("%s %s", operation.Op, operation.Path))
+			continue
+		}
+		formatted = append(formatted, fmt.Sprintf("%s %s %s", operation.Op, operation.Path, operation.Value))
+	}
+	return formatted
+}
@@ -0,0 +1,26 @@
+package jsonpatch
+
+// MergePatch applies a JSON Merge Patch (RFC 7396) to a copy of target and returns the result.
+// Objects are merged recursively and null values in the patch remove the field from the target.
+// Any other patch value, including arrays, replaces the target.
+func MergePatch(target any, patch any) any {
+	patchMap, ok := patch.(map[string]any)
+	if !ok {
+		return DeepCopy(patch)
+	}
+
+	targetMap, ok := target.(map[string]any)
+	if ok {
+		targetMap = DeepCopy(targetMap).(map[string]any)
+	} else {
+		targetMap = map[string]any{}
+	}
+	for key, value := range patchMap {
+		if value == nil {
+			delete(targetMap, key)
+			continue
+		}
+		targetMap[key] = MergePatch(targetMap[key], value)
+	}
+	return targetMap
+}
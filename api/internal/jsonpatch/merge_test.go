@@ -0,0 +1,38 @@
+package jsonpatch
+
+import "testing"
+
+// The examples of RFC 7396 Appendix A
+func TestMergePatchRFC7396(t *testing.T) {
+	tests := []struct {
+		target string
+		patch  string
+		result string
+	}{
+		{`{"a": "b"}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "b"}`, `{"b": "c"}`, `{"a": "b", "b": "c"}`},
+		{`{"a": "b"}`, `{"a": null}`, `{}`},
+		{`{"a": "b", "b": "c"}`, `{"a": null}`, `{"b": "c"}`},
+		{`{"a": ["b"]}`, `{"a": "c"}`, `{"a": "c"}`},
+		{`{"a": "c"}`, `{"a": ["b"]}`, `{"a": ["b"]}`},
+		{`{"a": {"b": "c"}}`, `{"a": {"b": "d", "c": null}}`, `{"a": {"b": "d"}}`},
+		{`{"a": [{"b": "c"}]}`, `{"a": [1]}`, `{"a": [1]}`},
+		{`["a", "b"]`, `["c", "d"]`, `["c", "d"]`},
+		{`{"a": "b"}`, `["c"]`, `["c"]`},
+		{`{"a": "foo"}`, `null`, `null`},
+		{`{"a": "foo"}`, `"bar"`, `"bar"`},
+		{`{"e": null}`, `{"a": 1}`, `{"e": null, "a": 1}`},
+		{`[1, 2]`, `{"a": "b", "c": null}`, `{"a": "b"}`},
+		{`{}`, `{"a": {"bb": {"ccc": null}}}`, `{"a": {"bb": {}}}`},
+	}
+	for _, tt := range tests {
+		target := decode(t, tt.target)
+		result := MergePatch(target, decode(t, tt.patch))
+		if want := decode(t, tt.result); !Equal(result, want) {
+			t.Errorf("MergePatch(%s, %s) = %v, want %v", tt.target, tt.patch, result, want)
+		}
+		if !Equal(target, decode(t, tt.target)) {
+			t.Errorf("MergePatch(%s, %s) changed the target to %v", tt.target, tt.patch, target)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+// Package jsonpatch implements JSON Pointer (RFC 6901), JSON Patch (RFC 6902)
+// and JSON Merge Patch (RFC 7396) for documents decoded by encoding/json into any.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// ErrTestFailed is wrapped by the error returned from Apply when a test operation does not match
+var ErrTestFailed = errors.New("test failed")
+
+// Operation is a single JSON Patch operation
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Error describes which operation of a patch could not be applied
+type Error struct {
+	Index   int    `json:"index"`
+	Op      string `json:"op"`
+	Path    string `json:"path"`
+	Message string `json:"message"`
+	err     error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("operation %d (%s %s): %s", e.Index, e.Op, e.Path, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Apply applies the operations to a copy of doc and returns the patched copy.
+// Patches are atomic: if any operation fails, an *Error is returned and doc is left untouched.
+func Apply(doc any, operations []Operation) (any, error) {
+	result := DeepCopy(doc)
+	for index, operation := range operations {
+		var err error
+		result, err = applyOperation(result, operation)
+		if err != nil {
+			return nil, &Error{
+				Index:   index,
+				Op:      operation.Op,
+				Path:    operation.Path,
+				Message: err.Error(),
+				err:     err,
+			}
+		}
+	}
+	return result, nil
+}
+
+func applyOperation(doc any, operation Operation) (any, error) {
+	tokens, err := ParsePointer(operation.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation.Op {
+	case "add", "replace", "test":
+		if operation.Value == nil {
+			return nil, fmt.Errorf("%s operation requires a value", operation.Op)
+		}
+		var value any
+		if err := json.Unmarshal(operation.Value, &value); err != nil {
+			return nil, fmt.Errorf("invalid value: %w", err)
+		}
+		switch operation.Op {
+		case "add":
+			return Add(doc, tokens, value)
+		case "replace":
+			return Replace(doc, tokens, value)
+		default:
+			current, err := Get(doc, tokens)
+			if err != nil {
+				return nil, err
+			}
+			if !Equal(current, value) {
+				return nil, fmt.Errorf("%w: value at %s does not match", ErrTestFailed, operation.Path)
+			}
+			return doc, nil
+		}
+	case "remove":
+		doc, _, err := Remove(doc, tokens)
+		return doc, err
+	case "move", "copy":
+		fromTokens, err := ParsePointer(operation.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from: %w", err)
+		}
+		if operation.Op == "copy" {
+			value, err := Get(doc, fromTokens)
+			if err != nil {
+				return nil, err
+			}
+			return Add(doc, tokens, DeepCopy(value))
+		}
+		if operation.From == operation.Path {
+			return doc, nil
+		}
+		if strings.HasPrefix(operation.Path, operation.From+"/") {
+			return nil, fmt.Errorf("cannot move %s into one of its children", operation.From)
+		}
+		doc, value, err := Remove(doc, fromTokens)
+		if err != nil {
+			return nil, err
+		}
+		return Add(doc, tokens, value)
+	default:
+		return nil, fmt.Errorf("unknown operation %q", operation.Op)
+	}
+}
+
+// Add adds value at the location the tokens refer to, modifying doc in place.
+// It returns the updated document, which is a different value when the root is replaced.
+func Add(doc any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return updateParent(doc, tokens, func(parent any, key string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			p[key] = value
+			return p, nil
+		case []any:
+			if key == "-" {
+				return append(p, value), nil
+			}
+			// inserting at the end of the array is allowed
+			index, err := arrayIndex(key, len(p)+1)
+			if err != nil {
+				return nil, err
+			}
+			return slices.Insert(p, index, value), nil
+		default:
+			return nil, fmt.Errorf("cannot add %q to a value that is not an object or array", key)
+		}
+	})
+}
+
+// Replace replaces the existing value the tokens refer to, modifying doc in place
+func Replace(doc any, tokens []string, value any) (any, error) {
+	if len(tokens) == 0 {
+		return value, nil
+	}
+	return updateParent(doc, tokens, func(parent any, key string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			if _, ok := p[key]; !ok {
+				return nil, fmt.Errorf("%s does not exist", FormatPointer(tokens))
+			}
+			p[key] = value
+			return p, nil
+		case []any:
+			index, err := arrayIndex(key, len(p))
+			if err != nil {
+				return nil, err
+			}
+			p[index] = value
+			return p, nil
+		default:
+			return nil, fmt.Errorf("cannot replace %q in a value that is not an object or array", key)
+		}
+	})
+}
+
+// Remove deletes the value the tokens refer to, modifying doc in place.
+// It returns the updated document along with the removed value.
+func Remove(doc any, tokens []string) (any, any, error) {
+	if len(tokens) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+	var removed any
+	doc, err := updateParent(doc, tokens, func(parent any, key string) (any, error) {
+		switch p := parent.(type) {
+		case map[string]any:
+			value, ok := p[key]
+			if !ok {
+				return nil, fmt.Errorf("%s does not exist", FormatPointer(tokens))
+			}
+			removed = value
+			delete(p, key)
+			return p, nil
+		case []any:
+			index, err := arrayIndex(key, len(p))
+			if err != nil {
+				return nil, err
+			}
+			removed = p[index]
+			return slices.Delete(p, index, index+1), nil
+		default:
+			return nil, fmt.Errorf("cannot remove %q from a value that is not an object or array", key)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, removed, nil
+}
+
+// Equal reports whether two decoded json values are the same
+func Equal(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// DeepCopy copies a decoded json value, so that modifying the copy does not modify the original
+func DeepCopy(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(v))
+		for key, element := range v {
+			result[key] = DeepCopy(element)
+		}
+		return result
+	case []any:
+		result := make([]any, len(v))
+		for i, element := range v {
+			result[i] = DeepCopy(element)
+		}
+		return result
+	default:
+		return v
+	}
+}
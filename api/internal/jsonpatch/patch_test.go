@@ -0,0 +1,221 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func decode(t *testing.T, value string) any {
+	t.Helper()
+	var decoded any
+	if err := json.Unmarshal([]byte(value), &decoded); err != nil {
+		t.Fatalf("invalid json %q: %v", value, err)
+	}
+	return decoded
+}
+
+// The examples of RFC 6902 Appendix A, an empty result means the patch fails
+func TestApplyRFC6902(t *testing.T) {
+	tests := []struct {
+		name   string
+		doc    string
+		patch  string
+		result string
+	}{
+		{
+			name:   "A.1 adding an object member",
+			doc:    `{"foo": "bar"}`,
+			patch:  `[{"op": "add", "path": "/baz", "value": "qux"}]`,
+			result: `{"baz": "qux", "foo": "bar"}`,
+		},
+		{
+			name:   "A.2 adding an array element",
+			doc:    `{"foo": ["bar", "baz"]}`,
+			patch:  `[{"op": "add", "path": "/foo/1", "value": "qux"}]`,
+			result: `{"foo": ["bar", "qux", "baz"]}`,
+		},
+		{
+			name:   "A.3 removing an object member",
+			doc:    `{"baz": "qux", "foo": "bar"}`,
+			patch:  `[{"op": "remove", "path": "/baz"}]`,
+			result: `{"foo": "bar"}`,
+		},
+		{
+			name:   "A.4 removing an array element",
+			doc:    `{"foo": ["bar", "qux", "baz"]}`,
+			patch:  `[{"op": "remove", "path": "/foo/1"}]`,
+			result: `{"foo": ["bar", "baz"]}`,
+		},
+		{
+			name:   "A.5 replacing a value",
+			doc:    `{"baz": "qux", "foo": "bar"}`,
+			patch:  `[{"op": "replace", "path": "/baz", "value": "boo"}]`,
+			result: `{"baz": "boo", "foo": "bar"}`,
+		},
+		{
+			name:   "A.6 moving a value",
+			doc:    `{"foo": {"bar": "baz", "waldo": "fred"}, "qux": {"corge": "grault"}}`,
+			patch:  `[{"op": "move", "from": "/foo/waldo", "path": "/qux/thud"}]`,
+			result: `{"foo": {"bar": "baz"}, "qux": {"corge": "grault", "thud": "fred"}}`,
+		},
+		{
+			name:   "A.7 moving an array element",
+			doc:    `{"foo": ["all", "grass", "cows", "eat"]}`,
+			patch:  `[{"op": "move", "from": "/foo/1", "path": "/foo/3"}]`,
+			result: `{"foo": ["all", "cows", "eat", "grass"]}`,
+		},
+		{
+			name: "A.8 testing a value: success",
+			doc:  `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+			patch: `[
+				{"op": "test", "path": "/baz", "value": "qux"},
+				{"op": "test", "path": "/foo/1", "value": 2}
+			]`,
+			result: `{"baz": "qux", "foo": ["a", 2, "c"]}`,
+		},
+		{
+			name:  "A.9 testing a value: error",
+			doc:   `{"baz": "qux"}`,
+			patch: `[{"op": "test", "path": "/baz", "value": "bar"}]`,
+		},
+		{
+			name:   "A.10 adding a nested member object",
+			doc:    `{"foo": "bar"}`,
+			patch:  `[{"op": "add", "path": "/child", "value": {"grandchild": {}}}]`,
+			result: `{"foo": "bar", "child": {"grandchild": {}}}`,
+		},
+		{
+			name:   "A.11 ignoring unrecognized elements",
+			doc:    `{"foo": "bar"}`,
+			patch:  `[{"op": "add", "path": "/baz", "value": "qux", "xyz": 123}]`,
+			result: `{"foo": "bar", "baz": "qux"}`,
+		},
+		{
+			name:  "A.12 adding to a nonexistent target",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz/bat", "value": "qux"}]`,
+		},
+		{
+			// encoding/json keeps the last op, so this is a remove of a member that does not exist
+			name:  "A.13 invalid JSON Patch document",
+			doc:   `{"foo": "bar"}`,
+			patch: `[{"op": "add", "path": "/baz", "value": "qux", "op": "remove"}]`,
+		},
+		{
+			name:   "A.14 ~ escape ordering",
+			doc:    `{"/": 9, "~1": 10}`,
+			patch:  `[{"op": "test", "path": "/~01", "value": 10}]`,
+			result: `{"/": 9, "~1": 10}`,
+		},
+		{
+			name:  "A.15 comparing strings and numbers",
+			doc:   `{"/": 9, "~1": 10}`,
+			patch: `[{"op": "test", "path": "/~01", "value": "10"}]`,
+		},
+		{
+			name:   "A.16 adding an array value",
+			doc:    `{"foo": ["bar"]}`,
+			patch:  `[{"op": "add", "path": "/foo/-", "value": ["abc", "def"]}]`,
+			result: `{"foo": ["bar", ["abc", "def"]]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var operations []Operation
+			if err := json.Unmarshal([]byte(tt.patch), &operations); err != nil {
+				t.Fatalf("invalid patch: %v", err)
+			}
+			doc := decode(t, tt.doc)
+			result, err := Apply(doc, operations)
+			if tt.result == "" {
+				if err == nil {
+					t.Errorf("got result %v, want an error", result)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if want := decode(t, tt.result); !Equal(result, want) {
+				t.Errorf("got %v, want %v", result, want)
+			}
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		index int
+	}{
+		{name: "unknown op", patch: `[{"op": "merge", "path": "/a"}]`},
+		{name: "missing value", patch: `[{"op": "add", "path": "/b"}]`},
+		{name: "invalid pointer", patch: `[{"op": "add", "path": "a", "value": 1}]`},
+		{name: "replace missing member", patch: `[{"op": "replace", "path": "/b", "value": 1}]`},
+		{name: "index out of bounds", patch: `[{"op": "add", "path": "/list/3", "value": 1}]`},
+		{name: "index with leading zero", patch: `[{"op": "remove", "path": "/list/01"}]`},
+		{name: "remove the document", patch: `[{"op": "remove", "path": ""}]`},
+		{name: "move into a child", patch: `[{"op": "move", "from": "/list", "path": "/list/0"}]`},
+		{name: "copy missing value", patch: `[{"op": "copy", "from": "/b", "path": "/c"}]`},
+		{name: "failure after changes", patch: `[{"op": "remove", "path": "/a"}, {"op": "remove", "path": "/list/0"}, {"op": "test", "path": "/a", "value": 1}]`, index: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var operations []Operation
+			if err := json.Unmarshal([]byte(tt.patch), &operations); err != nil {
+				t.Fatalf("invalid patch: %v", err)
+			}
+			const original = `{"a": 1, "list": [1, 2]}`
+			doc := decode(t, original)
+			_, err := Apply(doc, operations)
+			var patchErr *Error
+			if !errors.As(err, &patchErr) {
+				t.Fatalf("got error %v, want an *Error", err)
+			}
+			if patchErr.Index != tt.index {
+				t.Errorf("got error for operation %d, want %d: %v", patchErr.Index, tt.index, err)
+			}
+			// patches are atomic, so the document is unchanged even when earlier operations succeeded
+			if !Equal(doc, decode(t, original)) {
+				t.Errorf("document changed to %v", doc)
+			}
+		})
+	}
+}
+
+func TestApplyTestFailure(t *testing.T) {
+	_, err := Apply(decode(t, `{"a": 1}`), []Operation{{Op: "test", Path: "/a", Value: json.RawMessage(`2`)}})
+	if !errors.Is(err, ErrTestFailed) {
+		t.Errorf("got error %v, want ErrTestFailed", err)
+	}
+}
+
+func TestApplyCopy(t *testing.T) {
+	doc := decode(t, `{"a": {"b": [1]}}`)
+	result, err := Apply(doc, []Operation{
+		{Op: "copy", From: "/a", Path: "/c"},
+		{Op: "add", Path: "/c/b/-", Value: json.RawMessage(`2`)},
+		{Op: "add", Path: "", Value: json.RawMessage(`null`)},
+		{Op: "add", Path: "", Value: json.RawMessage(`{"root": true}`)},
+	})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if want := decode(t, `{"root": true}`); !Equal(result, want) {
+		t.Errorf("got %v, want %v", result, want)
+	}
+
+	// copies do not share values with the original
+	result, err = Apply(doc, []Operation{
+		{Op: "copy", From: "/a", Path: "/c"},
+		{Op: "add", Path: "/c/b/-", Value: json.RawMessage(`2`)},
+	})
+	if err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	if want := decode(t, `{"a": {"b": [1]}, "c": {"b": [1, 2]}}`); !Equal(result, want) {
+		t.Errorf("got %v, want %v", result, want)
+	}
+}
@@ -0,0 +1,106 @@
+package jsonpatch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParsePointer splits an RFC 6901 JSON Pointer into its unescaped reference tokens.
+// The empty pointer "" refers to the whole document and has no tokens.
+func ParsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("ParsePointer: pointer %q must start with /", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// FormatPointer escapes and joins reference tokens into a JSON Pointer
+func FormatPointer(tokens []string) string {
+	var builder strings.Builder
+	for _, token := range tokens {
+		builder.WriteString("/")
+		builder.WriteString(strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1"))
+	}
+	return builder.String()
+}
+
+// Get returns the value the tokens refer to in doc
+func Get(doc any, tokens []string) (any, error) {
+	current := doc
+	for i, token := range tokens {
+		switch v := current.(type) {
+		case map[string]any:
+			next, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("Get: %s does not exist", FormatPointer(tokens[:i+1]))
+			}
+			current = next
+		case []any:
+			index, err := arrayIndex(token, len(v))
+			if err != nil {
+				return nil, fmt.Errorf("Get: %s: %w", FormatPointer(tokens[:i+1]), err)
+			}
+			current = v[index]
+		default:
+			return nil, fmt.Errorf("Get: %s is not an object or array", FormatPointer(tokens[:i]))
+		}
+	}
+	return current, nil
+}
+
+// arrayIndex parses an array index token, which must be smaller than length
+func arrayIndex(token string, length int) (int, error) {
+	if token == "" || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	index, err := strconv.Atoi(token)
+	if err != nil || index < 0 {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if index >= length {
+		return 0, fmt.Errorf("array index %d out of bounds", index)
+	}
+	return index, nil
+}
+
+// updateParent calls fn with the container holding the last token and returns doc with the updated container.
+// Arrays may be reallocated by fn, so every level is assigned back into its parent.
+func updateParent(doc any, tokens []string, fn func(parent any, key string) (any, error)) (any, error) {
+	if len(tokens) == 1 {
+		return fn(doc, tokens[0])
+	}
+	switch v := doc.(type) {
+	case map[string]any:
+		child, ok := v[tokens[0]]
+		if !ok {
+			return nil, fmt.Errorf("%s does not exist", tokens[0])
+		}
+		updatedChild, err := updateParent(child, tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		v[tokens[0]] = updatedChild
+		return v, nil
+	case []any:
+		index, err := arrayIndex(tokens[0], len(v))
+		if err != nil {
+			return nil, err
+		}
+		updatedChild, err := updateParent(v[index], tokens[1:], fn)
+		if err != nil {
+			return nil, err
+		}
+		v[index] = updatedChild
+		return v, nil
+	default:
+		return nil, fmt.Errorf("%s is not an object or array", tokens[0])
+	}
+}
@@ -0,0 +1,101 @@
+package jsonpatch
+
+import (
+	"slices"
+	"testing"
+)
+
+// The examples of RFC 6901 section 5
+func TestGetRFC6901(t *testing.T) {
+	doc := decode(t, `{
+		"foo": ["bar", "baz"],
+		"": 0,
+		"a/b": 1,
+		"c%d": 2,
+		"e^f": 3,
+		"g|h": 4,
+		"i\\j": 5,
+		"k\"l": 6,
+		" ": 7,
+		"m~n": 8
+	}`)
+	tests := []struct {
+		pointer string
+		value   string
+	}{
+		{"", ""},
+		{"/foo", `["bar", "baz"]`},
+		{"/foo/0", `"bar"`},
+		{"/", `0`},
+		{"/a~1b", `1`},
+		{"/c%d", `2`},
+		{"/e^f", `3`},
+		{"/g|h", `4`},
+		{"/i\\j", `5`},
+		{"/k\"l", `6`},
+		{"/ ", `7`},
+		{"/m~0n", `8`},
+	}
+	for _, tt := range tests {
+		tokens, err := ParsePointer(tt.pointer)
+		if err != nil {
+			t.Errorf("ParsePointer(%q): %v", tt.pointer, err)
+			continue
+		}
+		if formatted := FormatPointer(tokens); formatted != tt.pointer {
+			t.Errorf("FormatPointer(%q) = %q, want %q", tokens, formatted, tt.pointer)
+		}
+		value, err := Get(doc, tokens)
+		if err != nil {
+			t.Errorf("Get(%q): %v", tt.pointer, err)
+			continue
+		}
+		want := doc
+		if tt.value != "" {
+			want = decode(t, tt.value)
+		}
+		if !Equal(value, want) {
+			t.Errorf("Get(%q) = %v, want %v", tt.pointer, value, want)
+		}
+	}
+}
+
+func TestParsePointer(t *testing.T) {
+	tests := []struct {
+		pointer string
+		tokens  []string
+	}{
+		{"/a/b", []string{"a", "b"}},
+		{"/a//b", []string{"a", "", "b"}},
+		// ~01 is ~1 and not /, since ~1 is unescaped first
+		{"/~01", []string{"~1"}},
+		{"/~1~0", []string{"/~"}},
+	}
+	for _, tt := range tests {
+		tokens, err := ParsePointer(tt.pointer)
+		if err != nil {
+			t.Errorf("ParsePointer(%q): %v", tt.pointer, err)
+			continue
+		}
+		if !slices.Equal(tokens, tt.tokens) {
+			t.Errorf("ParsePointer(%q) = %q, want %q", tt.pointer, tokens, tt.tokens)
+		}
+	}
+
+	if _, err := ParsePointer("a/b"); err == nil {
+		t.Error("ParsePointer(\"a/b\") succeeded, want an error for a pointer without a leading /")
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	doc := decode(t, `{"a": [1, {"b": "c"}]}`)
+	for _, pointer := range []string{"/b", "/a/2", "/a/-", "/a/01", "/a/-1", "/a/x", "/a/0/b", "/a/1/b/c"} {
+		tokens, err := ParsePointer(pointer)
+		if err != nil {
+			t.Fatalf("ParsePointer(%q): %v", pointer, err)
+		}
+		if value, err := Get(doc, tokens); err == nil {
+			t.Errorf("Get(%q) = %v, want an error", pointer, value)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package jsonschema
+
+import (
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+
+// matchesFormat checks the formats most commonly used in api fixtures.
+// Formats that are not known are always valid, as the specification allows.
+func matchesFormat(format string, value string) bool {
+	switch format {
+	case "email":
+		address, err := mail.ParseAddress(value)
+		return err == nil && address.Address == value
+	case "date":
+		_, err := time.Parse(time.DateOnly, value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", value)
+		if err != nil {
+			_, err = time.Parse("15:04:05.999999999Z07:00", value)
+		}
+		return err == nil
+	case "uuid":
+		_, err := uuid.Parse(value)
+		return err == nil && len(value) == 36
+	case "uri":
+		parsed, err := url.Parse(value)
+		return err == nil && parsed.IsAbs()
+	case "uri-reference":
+		_, err := url.Parse(value)
+		return err == nil
+	case "ipv4":
+		ip := net.ParseIP(value)
+		return ip != nil && ip.To4() != nil && strings.Contains(value, ".")
+	case "ipv6":
+		ip := net.ParseIP(value)
+		return ip != nil && strings.Contains(value, ":")
+	case "hostname":
+		return len(value) <= 253 && hostnamePattern.MatchString(value)
+	case "regex":
+		_, err := regexp.Compile(value)
+		return err == nil
+	}
+	return true
+}
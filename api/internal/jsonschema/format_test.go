@@ -0,0 +1,43 @@
+package jsonschema
+
+import "testing"
+
+func TestMatchesFormat(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		want   bool
+	}{
+		{"email", "ada@example.com", true},
+		{"email", "Ada <ada@example.com>", false},
+		{"email", "ada", false},
+		{"date", "2024-02-29", true},
+		{"date", "2024-02-30", false},
+		{"date-time", "2024-01-02T03:04:05Z", true},
+		{"date-time", "2024-01-02T03:04:05.5+02:00", true},
+		{"date-time", "2024-01-02 03:04:05", false},
+		{"time", "03:04:05Z", true},
+		{"time", "03:04:05.123+02:00", true},
+		{"time", "25:00:00Z", false},
+		{"uuid", "0b8d2c4e-6a1f-4c8e-9d3b-5f7a2e1c9b0d", true},
+		{"uuid", "{0b8d2c4e-6a1f-4c8e-9d3b-5f7a2e1c9b0d}", false},
+		{"uri", "https://example.com/a?b=c", true},
+		{"uri", "/relative", false},
+		{"uri-reference", "/relative", true},
+		{"ipv4", "192.168.0.1", true},
+		{"ipv4", "::1", false},
+		{"ipv6", "::1", true},
+		{"ipv6", "192.168.0.1", false},
+		{"hostname", "api.example.com", true},
+		{"hostname", "-bad.example.com", false},
+		{"regex", "^[a-z]+$", true},
+		{"regex", "[a-", false},
+		// unknown formats are annotations only
+		{"color", "not a color", true},
+	}
+	for _, tt := range tests {
+		if got := matchesFormat(tt.format, tt.value); got != tt.want {
+			t.Errorf("matchesFormat(%q, %q) = %v, want %v", tt.format, tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package jsonschema
+
+import (
+	"slices"
+)
+
+// Draft is the $schema of the schemas returned by Infer
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+const (
+	// maxEnumValues is the most distinct strings a field can have to be inferred as an enum
+	maxEnumValues = 5
+	// maxTrackedStrings stops remembering distinct strings once a field clearly is not an enum
+	maxTrackedStrings = maxEnumValues + 1
+)
+
+// formats that strings are checked against when inferring, the first one every string matches is used
+var inferredFormats = []string{"uuid", "email", "date-time", "date", "uri"}
+
+// observation collects what was seen at one location of a document, across every item of the arrays above it
+type observation struct {
+	types map[string]bool
+
+	strings     []string
+	stringCount int
+	formats     map[string]bool
+
+	objectCount    int
+	properties     map[string]*observation
+	propertyCounts map[string]int
+
+	items *observation
+}
+
+func newObservation() *observation {
+	return &observation{
+		types:          map[string]bool{},
+		properties:     map[string]*observation{},
+		propertyCounts: map[string]int{},
+	}
+}
+
+// Infer returns a JSON Schema describing value. Items of arrays are merged into a single schema,
+// where properties are required only when every item has them, and values of different types allow every type seen.
+// Strings that all match a common format get that format, and strings with a few repeated values become an enum.
+func Infer(value any) map[string]any {
+	o := newObservation()
+	o.observe(value)
+	schema := o.schema()
+	schema["$schema"] = Draft
+	return schema
+}
+
+func (o *observation) observe(value any) {
+	valueType := typeOf(value)
+	o.types[valueType] = true
+
+	switch v := value.(type) {
+	case string:
+		o.observeString(v)
+	case map[string]any:
+		o.objectCount++
+		for _, key := range sortedKeys(v) {
+			property, ok := o.properties[key]
+			if !ok {
+				property = newObservation()
+				o.properties[key] = property
+			}
+			o.propertyCounts[key]++
+			property.observe(v[key])
+		}
+	case []any:
+		if o.items == nil {
+			o.items = newObservation()
+		}
+		for _, item := range v {
+			o.items.observe(item)
+		}
+	}
+}
+
+func (o *observation) observeString(value string) {
+	if o.stringCount == 0 {
+		o.formats = map[string]bool{}
+		for _, format := range inferredFormats {
+			o.formats[format] = true
+		}
+	}
+	o.stringCount++
+	for format := range o.formats {
+		if !matchesFormat(format, value) {
+			delete(o.formats, format)
+		}
+	}
+	if len(o.strings) < maxTrackedStrings && !slices.Contains(o.strings, value) {
+		o.strings = append(o.strings, value)
+	}
+}
+
+func (o *observation) schema() map[string]any {
+	schema := map[string]any{}
+
+	types := []string{}
+	for _, name := range []string{"object", "array", "string", "number", "integer", "boolean", "null"} {
+		// integers are numbers, so numbers with and without fractions are just numbers
+		if o.types[name] && !(name == "integer" && o.types["number"]) {
+			types = append(types, name)
+		}
+	}
+	switch len(types) {
+	case 0:
+		return schema
+	case 1:
+		schema["type"] = types[0]
+	default:
+		typeValues := []any{}
+		for _, name := range types {
+			typeValues = append(typeValues, name)
+		}
+		schema["type"] = typeValues
+	}
+
+	if o.types["object"] {
+		properties := map[string]any{}
+		required := []any{}
+		for _, key := range sortedKeys(o.properties) {
+			properties[key] = o.properties[key].schema()
+			if o.propertyCounts[key] == o.objectCount {
+				required = append(required, key)
+			}
+		}
+		schema["properties"] = properties
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+	}
+
+	if o.types["array"] {
+		if o.items != nil && len(o.items.types) > 0 {
+			schema["items"] = o.items.schema()
+		}
+	}
+
+	if o.types["string"] {
+		format := ""
+		for _, candidate := range inferredFormats {
+			if o.formats[candidate] {
+				format = candidate
+				break
+			}
+		}
+		switch {
+		case format != "":
+			schema["format"] = format
+		case len(o.strings) >= 2 && len(o.strings) <= maxEnumValues && o.stringCount >= 2*len(o.strings) && len(types) == 1:
+			// only a few repeated values look like an enum, a single value could be anything
+			enum := slices.Clone(o.strings)
+			slices.Sort(enum)
+			enumValues := []any{}
+			for _, value := range enum {
+				enumValues = append(enumValues, value)
+			}
+			schema["enum"] = enumValues
+		}
+	}
+	return schema
+}
@@ -0,0 +1,321 @@
+// Package jsonschema validates documents decoded by encoding/json against JSON Schema (draft 2020-12).
+// Only references within the schema itself are supported, remote references cannot be resolved.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/pl3lee/restjson/internal/jsonpatch"
+)
+
+var typeNames = map[string]bool{
+	"null":    true,
+	"boolean": true,
+	"object":  true,
+	"array":   true,
+	"number":  true,
+	"integer": true,
+	"string":  true,
+}
+
+// keywords whose value is a single subschema
+var subschemaKeywords = []string{
+	"additionalProperties", "propertyNames", "items", "contains", "not", "if", "then", "else",
+	"unevaluatedProperties", "unevaluatedItems",
+}
+
+// keywords whose value is an array of subschemas
+var subschemaArrayKeywords = []string{"allOf", "anyOf", "oneOf", "prefixItems"}
+
+// keywords whose value is an object of subschemas
+var subschemaMapKeywords = []string{"properties", "patternProperties", "dependentSchemas", "$defs", "definitions"}
+
+// Schema is a compiled JSON Schema
+type Schema struct {
+	root     any
+	patterns map[string]*regexp.Regexp
+	anchors  map[string]any
+}
+
+// Compile checks that schema, a decoded json value, is a valid JSON Schema and prepares it for validation
+func Compile(schema any) (*Schema, error) {
+	s := &Schema{
+		root:     schema,
+		patterns: map[string]*regexp.Regexp{},
+		anchors:  map[string]any{},
+	}
+	if err := s.compile(schema, []string{}); err != nil {
+		return nil, err
+	}
+	if err := s.checkRefs(schema, []string{}); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Parse decodes and compiles a JSON Schema
+func Parse(data []byte) (*Schema, error) {
+	var schema any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		return nil, fmt.Errorf("Parse: invalid json: %w", err)
+	}
+	return Compile(schema)
+}
+
+// MarshalJSON encodes the schema as it was given to Compile
+func (s *Schema) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.root)
+}
+
+func (s *Schema) compile(schema any, path []string) error {
+	if _, ok := schema.(bool); ok {
+		return nil
+	}
+	schemaMap, ok := schema.(map[string]any)
+	if !ok {
+		return schemaError(path, "schema must be an object or a boolean")
+	}
+
+	if anchor, ok := schemaMap["$anchor"].(string); ok {
+		s.anchors[anchor] = schema
+	}
+	if anchor, ok := schemaMap["$dynamicAnchor"].(string); ok {
+		if _, exists := s.anchors[anchor]; !exists {
+			s.anchors[anchor] = schema
+		}
+	}
+
+	switch types := schemaMap["type"].(type) {
+	case nil:
+	case string:
+		if !typeNames[types] {
+			return schemaError(append(path, "type"), fmt.Sprintf("unknown type %q", types))
+		}
+	case []any:
+		for i, t := range types {
+			name, ok := t.(string)
+			if !ok || !typeNames[name] {
+				return schemaError(append(path, "type", fmt.Sprint(i)), fmt.Sprintf("unknown type %v", t))
+			}
+		}
+	default:
+		return schemaError(append(path, "type"), "type must be a string or an array of strings")
+	}
+
+	if pattern, ok := schemaMap["pattern"]; ok {
+		if err := s.compilePattern(pattern, append(path, "pattern")); err != nil {
+			return err
+		}
+	}
+	if patternProperties, ok := schemaMap["patternProperties"].(map[string]any); ok {
+		for pattern := range patternProperties {
+			if err := s.compilePattern(pattern, append(path, "patternProperties", pattern)); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, keyword := range []string{"required"} {
+		if value, ok := schemaMap[keyword]; ok {
+			if _, ok := stringArray(value); !ok {
+				return schemaError(append(path, keyword), keyword+" must be an array of strings")
+			}
+		}
+	}
+	for _, keyword := range []string{"enum"} {
+		if value, ok := schemaMap[keyword]; ok {
+			if _, ok := value.([]any); !ok {
+				return schemaError(append(path, keyword), keyword+" must be an array")
+			}
+		}
+	}
+	for _, keyword := range []string{
+		"multipleOf", "maximum", "exclusiveMaximum", "minimum", "exclusiveMinimum",
+		"maxLength", "minLength", "maxItems", "minItems", "maxContains", "minContains", "maxProperties", "minProperties",
+	} {
+		if value, ok := schemaMap[keyword]; ok {
+			number, ok := toFloat(value)
+			if !ok {
+				return schemaError(append(path, keyword), keyword+" must be a number")
+			}
+			if keyword == "multipleOf" && number <= 0 {
+				return schemaError(append(path, keyword), "multipleOf must be greater than 0")
+			}
+		}
+	}
+
+	for _, keyword := range subschemaKeywords {
+		if subschema, ok := schemaMap[keyword]; ok {
+			if err := s.compile(subschema, append(path, keyword)); err != nil {
+				return err
+			}
+		}
+	}
+	for _, keyword := range subschemaArrayKeywords {
+		value, ok := schemaMap[keyword]
+		if !ok {
+			continue
+		}
+		subschemas, ok := value.([]any)
+		if !ok || len(subschemas) == 0 {
+			return schemaError(append(path, keyword), keyword+" must be a non-empty array of schemas")
+		}
+		for i, subschema := range subschemas {
+			if err := s.compile(subschema, append(path, keyword, fmt.Sprint(i))); err != nil {
+				return err
+			}
+		}
+	}
+	for _, keyword := range subschemaMapKeywords {
+		value, ok := schemaMap[keyword]
+		if !ok {
+			continue
+		}
+		subschemas, ok := value.(map[string]any)
+		if !ok {
+			return schemaError(append(path, keyword), keyword+" must be an object of schemas")
+		}
+		for name, subschema := range subschemas {
+			if err := s.compile(subschema, append(path, keyword, name)); err != nil {
+				return err
+			}
+		}
+	}
+	if dependentRequired, ok := schemaMap["dependentRequired"]; ok {
+		dependencies, ok := dependentRequired.(map[string]any)
+		if !ok {
+			return schemaError(append(path, "dependentRequired"), "dependentRequired must be an object")
+		}
+		for name, required := range dependencies {
+			if _, ok := stringArray(required); !ok {
+				return schemaError(append(path, "dependentRequired", name), "dependentRequired must contain arrays of strings")
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) compilePattern(pattern any, path []string) error {
+	expr, ok := pattern.(string)
+	if !ok {
+		return schemaError(path, "pattern must be a string")
+	}
+	compiled, err := regexp.Compile(expr)
+	if err != nil {
+		return schemaError(path, fmt.Sprintf("invalid pattern: %v", err))
+	}
+	s.patterns[expr] = compiled
+	return nil
+}
+
+// checkRefs makes sure every $ref of the schema can be resolved
+func (s *Schema) checkRefs(schema any, path []string) error {
+	switch v := schema.(type) {
+	case map[string]any:
+		for _, keyword := range []string{"$ref", "$dynamicRef"} {
+			if ref, ok := v[keyword]; ok {
+				refString, ok := ref.(string)
+				if !ok {
+					return schemaError(append(path, keyword), keyword+" must be a string")
+				}
+				if _, err := s.resolveRef(refString); err != nil {
+					return schemaError(append(path, keyword), err.Error())
+				}
+			}
+		}
+		for key, value := range v {
+			// enum and const hold plain values, not schemas
+			if key == "enum" || key == "const" || key == "default" || key == "examples" {
+				continue
+			}
+			if err := s.checkRefs(value, append(path, key)); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, value := range v {
+			if err := s.checkRefs(value, append(path, fmt.Sprint(i))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolveRef finds the subschema a reference refers to, either a JSON Pointer fragment like #/$defs/user or an anchor like #user
+func (s *Schema) resolveRef(ref string) (any, error) {
+	base, fragment, _ := strings.Cut(ref, "#")
+	if base != "" {
+		rootId, _ := s.rootMap()["$id"].(string)
+		if base != rootId {
+			return nil, fmt.Errorf("cannot resolve %q, only references within the schema are supported", ref)
+		}
+	}
+	if fragment == "" {
+		return s.root, nil
+	}
+	if !strings.HasPrefix(fragment, "/") {
+		subschema, ok := s.anchors[fragment]
+		if !ok {
+			return nil, fmt.Errorf("cannot resolve %q, anchor does not exist", ref)
+		}
+		return subschema, nil
+	}
+	tokens, err := jsonpatch.ParsePointer(fragment)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %q: %w", ref, err)
+	}
+	for i, token := range tokens {
+		// fragments are url encoded
+		tokens[i] = strings.ReplaceAll(token, "%25", "%")
+	}
+	subschema, err := jsonpatch.Get(s.root, tokens)
+	if err != nil {
+		return nil, fmt.Errorf("cannot resolve %q, %s does not exist", ref, fragment)
+	}
+	return subschema, nil
+}
+
+func (s *Schema) rootMap() map[string]any {
+	rootMap, _ := s.root.(map[string]any)
+	return rootMap
+}
+
+// SchemaError describes why a schema cannot be compiled
+type SchemaError struct {
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+func (e *SchemaError) Error() string {
+	if e.Location == "" {
+		return "invalid schema: " + e.Message
+	}
+	return fmt.Sprintf("invalid schema at %s: %s", e.Location, e.Message)
+}
+
+func schemaError(path []string, message string) error {
+	return &SchemaError{
+		Location: jsonpatch.FormatPointer(path),
+		Message:  message,
+	}
+}
+
+func stringArray(value any) ([]string, bool) {
+	values, ok := value.([]any)
+	if !ok {
+		return nil, false
+	}
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		s, ok := v.(string)
+		if !ok {
+			return nil, false
+		}
+		result = append(result, s)
+	}
+	return result, true
+}
@@ -0,0 +1,68 @@
+package jsonschema
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		schema   string
+		location string
+	}{
+		{`[]`, ""},
+		{`{"type": "strin"}`, "/type"},
+		{`{"type": ["string", 1]}`, "/type/1"},
+		{`{"minimum": "1"}`, "/minimum"},
+		{`{"multipleOf": 0}`, "/multipleOf"},
+		{`{"pattern": "("}`, "/pattern"},
+		{`{"patternProperties": {"(": {}}}`, "/patternProperties/("},
+		{`{"required": [1]}`, "/required"},
+		{`{"enum": {}}`, "/enum"},
+		{`{"allOf": []}`, "/allOf"},
+		{`{"properties": {"a": 5}}`, "/properties/a"},
+		{`{"items": {"not": "string"}}`, "/items/not"},
+		{`{"dependentRequired": {"a": [1]}}`, "/dependentRequired/a"},
+		{`{"$ref": "#/$defs/missing"}`, "/$ref"},
+		{`{"$ref": "#missing"}`, "/$ref"},
+		{`{"$ref": "https://example.com/user.json"}`, "/$ref"},
+		{`{"properties": {"a": {"$dynamicRef": 1}}}`, "/properties/a/$dynamicRef"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.schema, func(t *testing.T) {
+			_, err := Parse([]byte(tt.schema))
+			var schemaErr *SchemaError
+			if !errors.As(err, &schemaErr) {
+				t.Fatalf("got error %v, want a SchemaError", err)
+			}
+			if schemaErr.Location != tt.location {
+				t.Errorf("got error at %q, want %q: %v", schemaErr.Location, tt.location, err)
+			}
+		})
+	}
+}
+
+func TestParseValues(t *testing.T) {
+	// enum, const, default and examples hold values, which are not checked as schemas
+	schema := `{"enum": [{"$ref": "#/missing"}], "const": {"type": "unknown"}, "default": {"$ref": 1}, "examples": [{"minimum": "a"}]}`
+	if _, err := Parse([]byte(schema)); err != nil {
+		t.Errorf("Parse: %v", err)
+	}
+}
+
+func TestInferValidates(t *testing.T) {
+	var instance any = map[string]any{
+		"users": []any{
+			map[string]any{"id": float64(1), "name": "ada", "tags": []any{"admin"}},
+			map[string]any{"id": float64(2), "name": "grace", "email": nil},
+		},
+		"settings": map[string]any{"theme": "dark"},
+	}
+	schema, err := Compile(Infer(instance))
+	if err != nil {
+		t.Fatalf("Compile: %v", err)
+	}
+	if errs := schema.Validate(instance); len(errs) != 0 {
+		t.Errorf("got errors %v validating the instance the schema was inferred from", errs)
+	}
+}
@@ -0,0 +1,575 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pl3lee/restjson/internal/jsonpatch"
+)
+
+// maxDepth stops references that loop without descending into the instance
+const maxDepth = 200
+
+// ValidationError describes where an instance does not match its schema.
+// Both locations are JSON Pointers, into the instance and into the schema.
+type ValidationError struct {
+	InstanceLocation string `json:"instanceLocation"`
+	KeywordLocation  string `json:"keywordLocation"`
+	Message          string `json:"message"`
+}
+
+func (e ValidationError) Error() string {
+	location := e.InstanceLocation
+	if location == "" {
+		location = "/"
+	}
+	return fmt.Sprintf("%s: %s", location, e.Message)
+}
+
+// Validate validates instance against the schema, returning every error found.
+// An empty result means the instance is valid.
+func (s *Schema) Validate(instance any) []ValidationError {
+	return s.ValidateAt(instance, []string{})
+}
+
+// ValidateAt validates instance like Validate, reporting instance locations relative to
+// the tokens of a JSON Pointer, for instances that are part of a larger document
+func (s *Schema) ValidateAt(instance any, tokens []string) []ValidationError {
+	v := &validator{schema: s}
+	errs, _ := v.validate(s.root, instance, slices.Clone(tokens), []string{}, 0)
+	return errs
+}
+
+type validator struct {
+	schema *Schema
+}
+
+// evaluated holds the properties and items that were evaluated by a schema,
+// which unevaluatedProperties and unevaluatedItems do not apply to
+type evaluated struct {
+	properties    map[string]bool
+	allProperties bool
+	items         map[int]bool
+	allItems      bool
+}
+
+func newEvaluated() *evaluated {
+	return &evaluated{
+		properties: map[string]bool{},
+		items:      map[int]bool{},
+	}
+}
+
+func (e *evaluated) merge(other *evaluated) {
+	if other == nil {
+		return
+	}
+	for property := range other.properties {
+		e.properties[property] = true
+	}
+	for item := range other.items {
+		e.items[item] = true
+	}
+	e.allProperties = e.allProperties || other.allProperties
+	e.allItems = e.allItems || other.allItems
+}
+
+func (v *validator) validate(schema any, instance any, instancePath []string, keywordPath []string, depth int) ([]ValidationError, *evaluated) {
+	result := newEvaluated()
+	fail := func(keyword string, format string, args ...any) ValidationError {
+		return ValidationError{
+			InstanceLocation: jsonpatch.FormatPointer(instancePath),
+			KeywordLocation:  jsonpatch.FormatPointer(append(slices.Clone(keywordPath), keyword)),
+			Message:          fmt.Sprintf(format, args...),
+		}
+	}
+
+	if depth > maxDepth {
+		return []ValidationError{fail("$ref", "schema references are nested too deeply")}, result
+	}
+	if b, ok := schema.(bool); ok {
+		if b {
+			return nil, result
+		}
+		return []ValidationError{{
+			InstanceLocation: jsonpatch.FormatPointer(instancePath),
+			KeywordLocation:  jsonpatch.FormatPointer(keywordPath),
+			Message:          "no value is allowed here",
+		}}, result
+	}
+	schemaMap, ok := schema.(map[string]any)
+	if !ok {
+		return nil, result
+	}
+
+	errs := []ValidationError{}
+	// sub validates a subschema of the current schema, at a location relative to the current instance
+	sub := func(subschema any, subInstance any, instanceTokens []string, keywordTokens ...string) ([]ValidationError, *evaluated) {
+		return v.validate(
+			subschema,
+			subInstance,
+			append(slices.Clone(instancePath), instanceTokens...),
+			append(slices.Clone(keywordPath), keywordTokens...),
+			depth+1,
+		)
+	}
+
+	for _, keyword := range []string{"$ref", "$dynamicRef"} {
+		if ref, ok := schemaMap[keyword].(string); ok {
+			target, err := v.schema.resolveRef(ref)
+			if err != nil {
+				errs = append(errs, fail(keyword, "%v", err))
+				continue
+			}
+			refErrs, refEvaluated := sub(target, instance, nil, keyword)
+			errs = append(errs, refErrs...)
+			if len(refErrs) == 0 {
+				result.merge(refEvaluated)
+			}
+		}
+	}
+
+	// type and value keywords
+	if types, ok := schemaMap["type"]; ok && !matchesType(types, instance) {
+		errs = append(errs, fail("type", "expected %s, got %s", describeTypes(types), typeOf(instance)))
+	}
+	if enum, ok := schemaMap["enum"].([]any); ok && !slices.ContainsFunc(enum, func(value any) bool { return equal(value, instance) }) {
+		errs = append(errs, fail("enum", "must be one of %s", formatValues(enum)))
+	}
+	if constValue, ok := schemaMap["const"]; ok && !equal(constValue, instance) {
+		errs = append(errs, fail("const", "must be %s", formatValue(constValue)))
+	}
+
+	if number, ok := toFloat(instance); ok {
+		if multipleOf, ok := toFloat(schemaMap["multipleOf"]); ok {
+			quotient := number / multipleOf
+			if math.Abs(quotient-math.Round(quotient)) > 1e-9 {
+				errs = append(errs, fail("multipleOf", "must be a multiple of %v", multipleOf))
+			}
+		}
+		if maximum, ok := toFloat(schemaMap["maximum"]); ok && number > maximum {
+			errs = append(errs, fail("maximum", "must be less than or equal to %v", maximum))
+		}
+		if maximum, ok := toFloat(schemaMap["exclusiveMaximum"]); ok && number >= maximum {
+			errs = append(errs, fail("exclusiveMaximum", "must be less than %v", maximum))
+		}
+		if minimum, ok := toFloat(schemaMap["minimum"]); ok && number < minimum {
+			errs = append(errs, fail("minimum", "must be greater than or equal to %v", minimum))
+		}
+		if minimum, ok := toFloat(schemaMap["exclusiveMinimum"]); ok && number <= minimum {
+			errs = append(errs, fail("exclusiveMinimum", "must be greater than %v", minimum))
+		}
+	}
+
+	if str, ok := instance.(string); ok {
+		length := utf8.RuneCountInString(str)
+		if maxLength, ok := toFloat(schemaMap["maxLength"]); ok && float64(length) > maxLength {
+			errs = append(errs, fail("maxLength", "must be at most %v characters long", maxLength))
+		}
+		if minLength, ok := toFloat(schemaMap["minLength"]); ok && float64(length) < minLength {
+			errs = append(errs, fail("minLength", "must be at least %v characters long", minLength))
+		}
+		if pattern, ok := schemaMap["pattern"].(string); ok && !v.schema.patterns[pattern].MatchString(str) {
+			errs = append(errs, fail("pattern", "must match the pattern %q", pattern))
+		}
+		if format, ok := schemaMap["format"].(string); ok && !matchesFormat(format, str) {
+			errs = append(errs, fail("format", "must be a valid %s", format))
+		}
+	}
+
+	if object, ok := instance.(map[string]any); ok {
+		errs = append(errs, v.validateObject(schemaMap, object, instancePath, result, fail, sub)...)
+	}
+	if array, ok := instance.([]any); ok {
+		errs = append(errs, v.validateArray(schemaMap, array, result, fail, sub)...)
+	}
+
+	// combinators
+	if allOf, ok := schemaMap["allOf"].([]any); ok {
+		for i, subschema := range allOf {
+			subErrs, subEvaluated := sub(subschema, instance, nil, "allOf", strconv.Itoa(i))
+			errs = append(errs, subErrs...)
+			if len(subErrs) == 0 {
+				result.merge(subEvaluated)
+			}
+		}
+	}
+	if anyOf, ok := schemaMap["anyOf"].([]any); ok {
+		matched := false
+		branchErrs := []ValidationError{}
+		for i, subschema := range anyOf {
+			subErrs, subEvaluated := sub(subschema, instance, nil, "anyOf", strconv.Itoa(i))
+			if len(subErrs) == 0 {
+				matched = true
+				result.merge(subEvaluated)
+			}
+			branchErrs = append(branchErrs, subErrs...)
+		}
+		if !matched {
+			errs = append(errs, fail("anyOf", "must match at least one of the schemas in anyOf"))
+			errs = append(errs, branchErrs...)
+		}
+	}
+	if oneOf, ok := schemaMap["oneOf"].([]any); ok {
+		matches := []int{}
+		branchErrs := []ValidationError{}
+		for i, subschema := range oneOf {
+			subErrs, subEvaluated := sub(subschema, instance, nil, "oneOf", strconv.Itoa(i))
+			if len(subErrs) == 0 {
+				matches = append(matches, i)
+				result.merge(subEvaluated)
+			}
+			branchErrs = append(branchErrs, subErrs...)
+		}
+		switch {
+		case len(matches) == 0:
+			errs = append(errs, fail("oneOf", "must match exactly one of the schemas in oneOf"))
+			errs = append(errs, branchErrs...)
+		case len(matches) > 1:
+			errs = append(errs, fail("oneOf", "must match exactly one of the schemas in oneOf, but matches %d", len(matches)))
+		}
+	}
+	if not, ok := schemaMap["not"]; ok {
+		if subErrs, _ := sub(not, instance, nil, "not"); len(subErrs) == 0 {
+			errs = append(errs, fail("not", "must not match the schema in not"))
+		}
+	}
+	if ifSchema, ok := schemaMap["if"]; ok {
+		ifErrs, ifEvaluated := sub(ifSchema, instance, nil, "if")
+		if len(ifErrs) == 0 {
+			result.merge(ifEvaluated)
+			if thenSchema, ok := schemaMap["then"]; ok {
+				thenErrs, thenEvaluated := sub(thenSchema, instance, nil, "then")
+				errs = append(errs, thenErrs...)
+				if len(thenErrs) == 0 {
+					result.merge(thenEvaluated)
+				}
+			}
+		} else if elseSchema, ok := schemaMap["else"]; ok {
+			elseErrs, elseEvaluated := sub(elseSchema, instance, nil, "else")
+			errs = append(errs, elseErrs...)
+			if len(elseErrs) == 0 {
+				result.merge(elseEvaluated)
+			}
+		}
+	}
+
+	// unevaluated keywords apply last, after every other keyword had a chance to evaluate properties and items
+	if unevaluatedProperties, ok := schemaMap["unevaluatedProperties"]; ok {
+		if object, ok := instance.(map[string]any); ok && !result.allProperties {
+			for _, property := range sortedKeys(object) {
+				if result.properties[property] {
+					continue
+				}
+				subErrs, _ := sub(unevaluatedProperties, object[property], []string{property}, "unevaluatedProperties")
+				errs = append(errs, subErrs...)
+			}
+			result.allProperties = true
+		}
+	}
+	if unevaluatedItems, ok := schemaMap["unevaluatedItems"]; ok {
+		if array, ok := instance.([]any); ok && !result.allItems {
+			for i, item := range array {
+				if result.items[i] {
+					continue
+				}
+				subErrs, _ := sub(unevaluatedItems, item, []string{strconv.Itoa(i)}, "unevaluatedItems")
+				errs = append(errs, subErrs...)
+			}
+			result.allItems = true
+		}
+	}
+
+	return errs, result
+}
+
+type failFunc func(keyword string, format string, args ...any) ValidationError
+
+type subFunc func(subschema any, subInstance any, instanceTokens []string, keywordTokens ...string) ([]ValidationError, *evaluated)
+
+func (v *validator) validateObject(schemaMap map[string]any, object map[string]any, instancePath []string, result *evaluated, fail failFunc, sub subFunc) []ValidationError {
+	errs := []ValidationError{}
+
+	if maxProperties, ok := toFloat(schemaMap["maxProperties"]); ok && float64(len(object)) > maxProperties {
+		errs = append(errs, fail("maxProperties", "must have at most %v properties", maxProperties))
+	}
+	if minProperties, ok := toFloat(schemaMap["minProperties"]); ok && float64(len(object)) < minProperties {
+		errs = append(errs, fail("minProperties", "must have at least %v properties", minProperties))
+	}
+	if required, ok := stringArray(schemaMap["required"]); ok {
+		for _, property := range required {
+			if _, ok := object[property]; !ok {
+				// point at the missing property, which is where a form would show the error
+				err := fail("required", "%s is required", property)
+				err.InstanceLocation = jsonpatch.FormatPointer(append(slices.Clone(instancePath), property))
+				errs = append(errs, err)
+			}
+		}
+	}
+	if dependentRequired, ok := schemaMap["dependentRequired"].(map[string]any); ok {
+		for _, property := range sortedKeys(dependentRequired) {
+			if _, ok := object[property]; !ok {
+				continue
+			}
+			required, _ := stringArray(dependentRequired[property])
+			for _, dependency := range required {
+				if _, ok := object[dependency]; !ok {
+					errs = append(errs, fail("dependentRequired", "%s is required when %s is present", dependency, property))
+				}
+			}
+		}
+	}
+
+	properties, _ := schemaMap["properties"].(map[string]any)
+	patternProperties, _ := schemaMap["patternProperties"].(map[string]any)
+	for _, property := range sortedKeys(object) {
+		value := object[property]
+		matched := false
+		if propertySchema, ok := properties[property]; ok {
+			matched = true
+			result.properties[property] = true
+			subErrs, _ := sub(propertySchema, value, []string{property}, "properties", property)
+			errs = append(errs, subErrs...)
+		}
+		for _, pattern := range sortedKeys(patternProperties) {
+			if !v.schema.patterns[pattern].MatchString(property) {
+				continue
+			}
+			matched = true
+			result.properties[property] = true
+			subErrs, _ := sub(patternProperties[pattern], value, []string{property}, "patternProperties", pattern)
+			errs = append(errs, subErrs...)
+		}
+		if additionalProperties, ok := schemaMap["additionalProperties"]; ok && !matched {
+			result.properties[property] = true
+			if allowed, ok := additionalProperties.(bool); ok && !allowed {
+				err := fail("additionalProperties", "%s is not allowed", property)
+				err.InstanceLocation = jsonpatch.FormatPointer(append(slices.Clone(instancePath), property))
+				errs = append(errs, err)
+				continue
+			}
+			subErrs, _ := sub(additionalProperties, value, []string{property}, "additionalProperties")
+			errs = append(errs, subErrs...)
+		}
+		if propertyNames, ok := schemaMap["propertyNames"]; ok {
+			subErrs, _ := sub(propertyNames, property, []string{property}, "propertyNames")
+			errs = append(errs, subErrs...)
+		}
+	}
+
+	if dependentSchemas, ok := schemaMap["dependentSchemas"].(map[string]any); ok {
+		for _, property := range sortedKeys(dependentSchemas) {
+			if _, ok := object[property]; !ok {
+				continue
+			}
+			subErrs, subEvaluated := sub(dependentSchemas[property], object, nil, "dependentSchemas", property)
+			errs = append(errs, subErrs...)
+			if len(subErrs) == 0 {
+				result.merge(subEvaluated)
+			}
+		}
+	}
+	return errs
+}
+
+func (v *validator) validateArray(schemaMap map[string]any, array []any, result *evaluated, fail failFunc, sub subFunc) []ValidationError {
+	errs := []ValidationError{}
+
+	if maxItems, ok := toFloat(schemaMap["maxItems"]); ok && float64(len(array)) > maxItems {
+		errs = append(errs, fail("maxItems", "must have at most %v items", maxItems))
+	}
+	if minItems, ok := toFloat(schemaMap["minItems"]); ok && float64(len(array)) < minItems {
+		errs = append(errs, fail("minItems", "must have at least %v items", minItems))
+	}
+	if uniqueItems, ok := schemaMap["uniqueItems"].(bool); ok && uniqueItems {
+	unique:
+		for i := range array {
+			for j := i + 1; j < len(array); j++ {
+				if equal(array[i], array[j]) {
+					errs = append(errs, fail("uniqueItems", "items %d and %d are equal", i, j))
+					break unique
+				}
+			}
+		}
+	}
+
+	prefixItems, _ := schemaMap["prefixItems"].([]any)
+	for i, item := range array {
+		if i >= len(prefixItems) {
+			break
+		}
+		result.items[i] = true
+		subErrs, _ := sub(prefixItems[i], item, []string{strconv.Itoa(i)}, "prefixItems", strconv.Itoa(i))
+		errs = append(errs, subErrs...)
+	}
+	if items, ok := schemaMap["items"]; ok {
+		for i := len(prefixItems); i < len(array); i++ {
+			subErrs, _ := sub(items, array[i], []string{strconv.Itoa(i)}, "items")
+			errs = append(errs, subErrs...)
+		}
+		result.allItems = true
+	}
+
+	if contains, ok := schemaMap["contains"]; ok {
+		matches := 0
+		for i, item := range array {
+			if subErrs, _ := sub(contains, item, []string{strconv.Itoa(i)}, "contains"); len(subErrs) == 0 {
+				matches++
+				result.items[i] = true
+			}
+		}
+		minContains := 1.0
+		if value, ok := toFloat(schemaMap["minContains"]); ok {
+			minContains = value
+		}
+		if float64(matches) < minContains {
+			errs = append(errs, fail("contains", "must contain at least %v items matching the schema in contains", minContains))
+		}
+		if maxContains, ok := toFloat(schemaMap["maxContains"]); ok && float64(matches) > maxContains {
+			errs = append(errs, fail("maxContains", "must contain at most %v items matching the schema in contains", maxContains))
+		}
+	}
+	return errs
+}
+
+func matchesType(types any, instance any) bool {
+	switch t := types.(type) {
+	case string:
+		return matchesTypeName(t, instance)
+	case []any:
+		for _, name := range t {
+			if name, ok := name.(string); ok && matchesTypeName(name, instance) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func matchesTypeName(name string, instance any) bool {
+	actual := typeOf(instance)
+	return actual == name || (name == "number" && actual == "integer")
+}
+
+// typeOf returns the JSON Schema type of a decoded json value, integer for numbers without a fractional part
+func typeOf(instance any) string {
+	switch v := instance.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	default:
+		if number, ok := toFloat(v); ok {
+			if number == math.Trunc(number) && !math.IsInf(number, 0) {
+				return "integer"
+			}
+			return "number"
+		}
+	}
+	return fmt.Sprintf("%T", instance)
+}
+
+func describeTypes(types any) string {
+	switch t := types.(type) {
+	case string:
+		return t
+	case []any:
+		names := []string{}
+		for _, name := range t {
+			names = append(names, fmt.Sprint(name))
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(types)
+}
+
+// toFloat converts the number types that can appear in decoded json, or in values set by handlers, to float64
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case json.Number:
+		number, err := v.Float64()
+		return number, err == nil
+	}
+	return 0, false
+}
+
+// equal compares decoded json values, treating numbers of different go types as equal when their values are
+func equal(a, b any) bool {
+	if x, ok := toFloat(a); ok {
+		y, ok := toFloat(b)
+		return ok && x == y
+	}
+	switch x := a.(type) {
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for key, value := range x {
+			other, ok := y[key]
+			if !ok || !equal(value, other) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	return jsonpatch.Equal(a, b)
+}
+
+func formatValue(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
+
+func formatValues(values []any) string {
+	formatted := []string{}
+	for _, value := range values {
+		formatted = append(formatted, formatValue(value))
+	}
+	return strings.Join(formatted, ", ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,250 @@
+package jsonschema
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+type validateTest struct {
+	name     string
+	schema   string
+	instance string
+	// keyword locations of the errors, none when the instance is valid
+	errors []string
+}
+
+func runValidateTests(t *testing.T, tests []validateTest) {
+	t.Helper()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schema, err := Parse([]byte(tt.schema))
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			var instance any
+			if err := json.Unmarshal([]byte(tt.instance), &instance); err != nil {
+				t.Fatalf("invalid instance: %v", err)
+			}
+			got := []string{}
+			for _, validationErr := range schema.Validate(instance) {
+				got = append(got, validationErr.KeywordLocation)
+			}
+			want := slices.Clone(tt.errors)
+			if want == nil {
+				want = []string{}
+			}
+			slices.Sort(got)
+			slices.Sort(want)
+			if !slices.Equal(got, want) {
+				t.Errorf("got errors at %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestValidateTypes(t *testing.T) {
+	runValidateTests(t, []validateTest{
+		{name: "integer", schema: `{"type": "integer"}`, instance: `1`},
+		{name: "integer with fraction", schema: `{"type": "integer"}`, instance: `1.5`, errors: []string{"/type"}},
+		{name: "integer is a number", schema: `{"type": "number"}`, instance: `1`},
+		{name: "one of several types", schema: `{"type": ["string", "null"]}`, instance: `null`},
+		{name: "none of several types", schema: `{"type": ["string", "null"]}`, instance: `1`, errors: []string{"/type"}},
+		{name: "array is not an object", schema: `{"type": "object"}`, instance: `[]`, errors: []string{"/type"}},
+		{name: "enum", schema: `{"enum": ["a", 1, null]}`, instance: `1`},
+		{name: "not in enum", schema: `{"enum": ["a", 1, null]}`, instance: `"b"`, errors: []string{"/enum"}},
+		{name: "const", schema: `{"const": {"a": [1, 2]}}`, instance: `{"a": [1, 2]}`},
+		{name: "different const", schema: `{"const": {"a": [1, 2]}}`, instance: `{"a": [2, 1]}`, errors: []string{"/const"}},
+		{name: "true schema", schema: `true`, instance: `{"anything": 1}`},
+		{name: "false schema", schema: `false`, instance: `1`, errors: []string{""}},
+	})
+}
+
+func TestValidateNumbers(t *testing.T) {
+	runValidateTests(t, []validateTest{
+		{name: "multipleOf a fraction", schema: `{"multipleOf": 0.1}`, instance: `0.3`},
+		{name: "not a multiple", schema: `{"multipleOf": 0.1}`, instance: `0.35`, errors: []string{"/multipleOf"}},
+		{name: "maximum is inclusive", schema: `{"maximum": 10}`, instance: `10`},
+		{name: "above maximum", schema: `{"maximum": 10}`, instance: `11`, errors: []string{"/maximum"}},
+		{name: "exclusiveMaximum", schema: `{"exclusiveMaximum": 10}`, instance: `10`, errors: []string{"/exclusiveMaximum"}},
+		{name: "below minimum", schema: `{"minimum": 1}`, instance: `0`, errors: []string{"/minimum"}},
+		{name: "exclusiveMinimum", schema: `{"exclusiveMinimum": 0}`, instance: `0`, errors: []string{"/exclusiveMinimum"}},
+		{name: "number keywords ignore strings", schema: `{"maximum": 1}`, instance: `"10"`},
+	})
+}
+
+func TestValidateStrings(t *testing.T) {
+	runValidateTests(t, []validateTest{
+		{name: "length counts characters", schema: `{"maxLength": 3}`, instance: `"héé"`},
+		{name: "too long", schema: `{"maxLength": 3}`, instance: `"abcd"`, errors: []string{"/maxLength"}},
+		{name: "too short", schema: `{"minLength": 2}`, instance: `"a"`, errors: []string{"/minLength"}},
+		{name: "pattern", schema: `{"pattern": "^[a-z]+$"}`, instance: `"abc"`},
+		{name: "pattern mismatch", schema: `{"pattern": "^[a-z]+$"}`, instance: `"ab1"`, errors: []string{"/pattern"}},
+		{name: "pattern is not anchored", schema: `{"pattern": "b"}`, instance: `"abc"`},
+		{name: "string keywords ignore numbers", schema: `{"pattern": "^a$", "maxLength": 1}`, instance: `12345`},
+		{name: "format", schema: `{"format": "email"}`, instance: `"ada@example.com"`},
+		{name: "invalid format", schema: `{"format": "email"}`, instance: `"ada"`, errors: []string{"/format"}},
+	})
+}
+
+func TestValidateObjects(t *testing.T) {
+	runValidateTests(t, []validateTest{
+		{name: "required", schema: `{"required": ["name"]}`, instance: `{"name": "ada"}`},
+		{name: "missing required", schema: `{"required": ["name", "age"]}`, instance: `{"name": "ada"}`, errors: []string{"/required"}},
+		{name: "properties", schema: `{"properties": {"age": {"type": "integer"}}}`, instance: `{"age": "old"}`, errors: []string{"/properties/age/type"}},
+		{name: "additionalProperties false", schema: `{"properties": {"a": {}}, "additionalProperties": false}`, instance: `{"a": 1, "b": 2}`, errors: []string{"/additionalProperties"}},
+		{name: "additionalProperties schema", schema: `{"additionalProperties": {"type": "string"}}`, instance: `{"a": "x", "b": 2}`, errors: []string{"/additionalProperties/type"}},
+		{name: "patternProperties", schema: `{"patternProperties": {"^x-": {"type": "string"}}}`, instance: `{"x-a": 1, "y": 1}`, errors: []string{"/patternProperties/^x-/type"}},
+		{name: "patternProperties are not additional", schema: `{"patternProperties": {"^x-": {}}, "additionalProperties": false}`, instance: `{"x-a": 1}`},
+		{name: "propertyNames", schema: `{"propertyNames": {"maxLength": 3}}`, instance: `{"abc": 1, "abcd": 2}`, errors: []string{"/propertyNames/maxLength"}},
+		{name: "maxProperties", schema: `{"maxProperties": 1}`, instance: `{"a": 1, "b": 2}`, errors: []string{"/maxProperties"}},
+		{name: "minProperties", schema: `{"minProperties": 1}`, instance: `{}`, errors: []string{"/minProperties"}},
+		{name: "dependentRequired", schema: `{"dependentRequired": {"credit": ["billing"]}}`, instance: `{"credit": 1}`, errors: []string{"/dependentRequired"}},
+		{name: "dependentRequired without the property", schema: `{"dependentRequired": {"credit": ["billing"]}}`, instance: `{"billing": 1}`},
+		{name: "dependentSchemas", schema: `{"dependentSchemas": {"credit": {"required": ["billing"]}}}`, instance: `{"credit": 1}`, errors: []string{"/dependentSchemas/credit/required"}},
+		{name: "dependentSchemas without the property", schema: `{"dependentSchemas": {"credit": {"required": ["billing"]}}}`, instance: `{}`},
+	})
+}
+
+func TestValidateArrays(t *testing.T) {
+	runValidateTests(t, []validateTest{
+		{name: "items", schema: `{"items": {"type": "integer"}}`, instance: `[1, "a", 2]`, errors: []string{"/items/type"}},
+		{name: "prefixItems", schema: `{"prefixItems": [{"type": "string"}, {"type": "integer"}]}`, instance: `["a", 1, null]`},
+		{name: "prefixItems mismatch", schema: `{"prefixItems": [{"type": "string"}]}`, instance: `[1]`, errors: []string{"/prefixItems/0/type"}},
+		{name: "items after prefixItems", schema: `{"prefixItems": [{"type": "string"}], "items": false}`, instance: `["a", 1]`, errors: []string{"/items"}},
+		{name: "maxItems", schema: `{"maxItems": 1}`, instance: `[1, 2]`, errors: []string{"/maxItems"}},
+		{name: "minItems", schema: `{"minItems": 1}`, instance: `[]`, errors: []string{"/minItems"}},
+		{name: "uniqueItems", schema: `{"uniqueItems": true}`, instance: `[{"a": 1}, {"a": 2}]`},
+		{name: "duplicate items", schema: `{"uniqueItems": true}`, instance: `[{"a": 1}, {"a": 1}]`, errors: []string{"/uniqueItems"}},
+		{name: "contains", schema: `{"contains": {"type": "string"}}`, instance: `[1, "a"]`},
+		{name: "does not contain", schema: `{"contains": {"type": "string"}}`, instance: `[1, 2]`, errors: []string{"/contains"}},
+		{name: "minContains", schema: `{"contains": {"type": "string"}, "minContains": 2}`, instance: `["a", 1]`, errors: []string{"/contains"}},
+		{name: "maxContains", schema: `{"contains": {"type": "string"}, "maxContains": 1}`, instance: `["a", "b"]`, errors: []string{"/maxContains"}},
+		{name: "minContains 0", schema: `{"contains": {"type": "string"}, "minContains": 0}`, instance: `[]`},
+	})
+}
+
+func TestValidateCombinators(t *testing.T) {
+	runValidateTests(t, []validateTest{
+		{name: "allOf", schema: `{"allOf": [{"type": "integer"}, {"minimum": 2}]}`, instance: `1`, errors: []string{"/allOf/1/minimum"}},
+		{name: "anyOf", schema: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, instance: `1`},
+		{name: "anyOf without match", schema: `{"anyOf": [{"type": "string"}, {"type": "integer"}]}`, instance: `null`, errors: []string{"/anyOf", "/anyOf/0/type", "/anyOf/1/type"}},
+		{name: "oneOf", schema: `{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`, instance: `1`},
+		{name: "oneOf matching twice", schema: `{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`, instance: `3`, errors: []string{"/oneOf"}},
+		{name: "oneOf without match", schema: `{"oneOf": [{"type": "integer"}, {"minimum": 2}]}`, instance: `1.5`, errors: []string{"/oneOf", "/oneOf/0/type", "/oneOf/1/minimum"}},
+		{name: "not", schema: `{"not": {"type": "string"}}`, instance: `"a"`, errors: []string{"/not"}},
+	})
+}
+
+func TestValidateConditionals(t *testing.T) {
+	const address = `{
+		"if": {"properties": {"country": {"const": "CA"}}},
+		"then": {"required": ["province"]},
+		"else": {"required": ["state"]}
+	}`
+	runValidateTests(t, []validateTest{
+		{name: "then", schema: address, instance: `{"country": "CA", "province": "ON"}`},
+		{name: "then fails", schema: address, instance: `{"country": "CA"}`, errors: []string{"/then/required"}},
+		{name: "else", schema: address, instance: `{"country": "US", "state": "NY"}`},
+		{name: "else fails", schema: address, instance: `{"country": "US"}`, errors: []string{"/else/required"}},
+		{name: "if alone", schema: `{"if": {"type": "string"}}`, instance: `1`},
+		{name: "if without else", schema: `{"if": {"type": "string"}, "then": {"minLength": 2}}`, instance: `1`},
+	})
+}
+
+func TestValidateRefs(t *testing.T) {
+	const tree = `{
+		"type": "object",
+		"required": ["name"],
+		"properties": {"children": {"type": "array", "items": {"$ref": "#"}}}
+	}`
+	const linkedList = `{
+		"$dynamicAnchor": "node",
+		"type": "object",
+		"properties": {"next": {"$dynamicRef": "#node"}}
+	}`
+	runValidateTests(t, []validateTest{
+		{name: "$defs", schema: `{"$defs": {"name": {"type": "string"}}, "properties": {"name": {"$ref": "#/$defs/name"}}}`, instance: `{"name": 1}`, errors: []string{"/properties/name/$ref/type"}},
+		{name: "escaped pointer", schema: `{"$defs": {"a/b": {"type": "string"}}, "$ref": "#/$defs/a~1b"}`, instance: `1`, errors: []string{"/$ref/type"}},
+		{name: "anchor", schema: `{"$defs": {"name": {"$anchor": "name", "type": "string"}}, "$ref": "#name"}`, instance: `1`, errors: []string{"/$ref/type"}},
+		{name: "$id of the root", schema: `{"$id": "https://example.com/user", "$defs": {"id": {"type": "integer"}}, "$ref": "https://example.com/user#/$defs/id"}`, instance: `"1"`, errors: []string{"/$ref/type"}},
+		{name: "keywords next to $ref", schema: `{"$defs": {"s": {"type": "string"}}, "$ref": "#/$defs/s", "maxLength": 2}`, instance: `"abc"`, errors: []string{"/maxLength"}},
+		{name: "recursive", schema: tree, instance: `{"name": "root", "children": [{"name": "leaf", "children": []}]}`},
+		{name: "recursive mismatch", schema: tree, instance: `{"name": "root", "children": [{"children": []}]}`, errors: []string{"/properties/children/items/$ref/required"}},
+		{name: "$dynamicRef", schema: linkedList, instance: `{"next": {"next": {}}}`},
+		{name: "$dynamicRef mismatch", schema: linkedList, instance: `{"next": {"next": 1}}`, errors: []string{"/properties/next/$dynamicRef/properties/next/$dynamicRef/type"}},
+	})
+
+	// a reference to itself never reaches the instance, so it stops at the maximum depth
+	schema, err := Parse([]byte(`{"$defs": {"loop": {"$ref": "#/$defs/loop"}}, "$ref": "#/$defs/loop"}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if errs := schema.Validate(1); len(errs) != 1 || errs[0].Message != "schema references are nested too deeply" {
+		t.Errorf("got errors %v, want one error about nesting", errs)
+	}
+}
+
+// unevaluatedProperties and unevaluatedItems see the properties and items evaluated by
+// adjacent keywords, including successful subschemas of allOf, anyOf, oneOf, if/then/else, dependentSchemas and $ref
+func TestValidateUnevaluated(t *testing.T) {
+	const anyOf = `{
+		"anyOf": [
+			{"properties": {"a": {"type": "string"}}, "required": ["a"]},
+			{"properties": {"b": {"type": "number"}}, "required": ["b"]}
+		],
+		"unevaluatedProperties": false
+	}`
+	const ifThen = `{
+		"if": {"properties": {"kind": {"const": "x"}}},
+		"then": {"properties": {"x": {}}},
+		"unevaluatedProperties": false
+	}`
+	runValidateTests(t, []validateTest{
+		{name: "properties", schema: `{"properties": {"a": {}}, "unevaluatedProperties": false}`, instance: `{"a": 1, "b": 2}`, errors: []string{"/unevaluatedProperties"}},
+		{name: "unevaluatedProperties schema", schema: `{"properties": {"a": {}}, "unevaluatedProperties": {"type": "string"}}`, instance: `{"a": 1, "b": 2}`, errors: []string{"/unevaluatedProperties/type"}},
+		{name: "allOf", schema: `{"allOf": [{"properties": {"a": {}}}], "unevaluatedProperties": false}`, instance: `{"a": 1}`},
+		{name: "allOf with another property", schema: `{"allOf": [{"properties": {"a": {}}}], "unevaluatedProperties": false}`, instance: `{"a": 1, "b": 1}`, errors: []string{"/unevaluatedProperties"}},
+		{name: "failed allOf evaluates nothing", schema: `{"allOf": [{"properties": {"a": {"type": "string"}}}], "unevaluatedProperties": false}`, instance: `{"a": 1}`, errors: []string{"/allOf/0/properties/a/type", "/unevaluatedProperties"}},
+		{name: "every matching anyOf", schema: anyOf, instance: `{"a": "x", "b": 1}`},
+		{name: "only matching anyOf", schema: anyOf, instance: `{"a": "x", "b": "y"}`, errors: []string{"/unevaluatedProperties"}},
+		{name: "oneOf", schema: `{"oneOf": [{"properties": {"a": {}}, "required": ["a"]}, {"required": ["b"]}], "unevaluatedProperties": false}`, instance: `{"a": 1}`},
+		{name: "$ref", schema: `{"$defs": {"base": {"properties": {"a": {}}}}, "$ref": "#/$defs/base", "properties": {"b": {}}, "unevaluatedProperties": false}`, instance: `{"a": 1, "b": 1}`},
+		{name: "$ref with another property", schema: `{"$defs": {"base": {"properties": {"a": {}}}}, "$ref": "#/$defs/base", "unevaluatedProperties": false}`, instance: `{"a": 1, "c": 1}`, errors: []string{"/unevaluatedProperties"}},
+		{name: "if and then", schema: ifThen, instance: `{"kind": "x", "x": 1}`},
+		{name: "failed if", schema: ifThen, instance: `{"kind": "y", "x": 1}`, errors: []string{"/unevaluatedProperties", "/unevaluatedProperties"}},
+		{name: "dependentSchemas", schema: `{"properties": {"a": {}}, "dependentSchemas": {"a": {"properties": {"b": {}}}}, "unevaluatedProperties": false}`, instance: `{"a": 1, "b": 1}`},
+		{name: "additionalProperties evaluates everything", schema: `{"additionalProperties": true, "unevaluatedProperties": false}`, instance: `{"a": 1}`},
+		{name: "nested unevaluatedProperties do not see the parent", schema: `{"properties": {"a": {}}, "allOf": [{"unevaluatedProperties": false}]}`, instance: `{"a": 1}`, errors: []string{"/allOf/0/unevaluatedProperties"}},
+		{name: "prefixItems", schema: `{"prefixItems": [{}], "unevaluatedItems": false}`, instance: `[1, 2]`, errors: []string{"/unevaluatedItems"}},
+		{name: "prefixItems in allOf", schema: `{"allOf": [{"prefixItems": [{}]}], "unevaluatedItems": false}`, instance: `[1]`},
+		{name: "contains", schema: `{"contains": {"type": "string"}, "unevaluatedItems": false}`, instance: `["a"]`},
+		{name: "items not contained", schema: `{"contains": {"type": "string"}, "unevaluatedItems": false}`, instance: `["a", 1]`, errors: []string{"/unevaluatedItems"}},
+		{name: "items evaluates everything", schema: `{"items": true, "unevaluatedItems": false}`, instance: `[1, 2]`},
+	})
+}
+
+func TestValidateLocations(t *testing.T) {
+	schema, err := Parse([]byte(`{
+		"properties": {"users": {"items": {"required": ["name"], "properties": {"age": {"minimum": 0}}}}}
+	}`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	var instance any
+	json.Unmarshal([]byte(`{"users": [{"name": "ada", "age": -1}, {}]}`), &instance)
+
+	want := []ValidationError{
+		{InstanceLocation: "/users/0/age", KeywordLocation: "/properties/users/items/properties/age/minimum", Message: "must be greater than or equal to 0"},
+		{InstanceLocation: "/users/1/name", KeywordLocation: "/properties/users/items/required", Message: "name is required"},
+	}
+	if got := schema.Validate(instance); !slices.Equal(got, want) {
+		t.Errorf("got errors %+v, want %+v", got, want)
+	}
+
+	// locations of part of a document are relative to where the part is
+	errs := schema.ValidateAt(instance, []string{"files", "0"})
+	if len(errs) != 2 || errs[0].InstanceLocation != "/files/0/users/0/age" {
+		t.Errorf("got errors %+v, want them under /files/0", errs)
+	}
+}
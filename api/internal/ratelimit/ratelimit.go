@@ -23,7 +23,7 @@ import (
 // 3. It refills the tokens based on the elapsed time since the last access.
 // 4. If the client has enough tokens, it allows the request and consumes one token.
 // 5. If the client does not have enough tokens, it responds with a 429 Too Many Requests status.
-func TokenBucketRateLimiter(rdb *redis.Client, capacity int, refillRate float64, expiration int) func(http.Handler) http.Handler {
+func TokenBucketRateLimiter(rdb redis.Cmdable, capacity int, refillRate float64, expiration int) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			ctx := r.Context()
@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// The benchmarks compare the ways a json document can be uploaded, for documents from 1KB to 50MB:
+//
+//   - tempfile marshals the document, writes it to a temp file, seeks back and uploads the file,
+//     which is how uploads to S3 used to work
+//   - memory marshals the document and uploads it straight from memory, which is how the api saves files
+//   - pipe encodes the document into a pipe while it is being uploaded
+//
+// Run them with
+//
+//	go test -run '^$' -bench Put ./internal/storage
+//
+// BenchmarkPutS3 only runs when S3_BUCKET and S3_REGION are set, it reads the same environment variables as the api.
+
+var benchmarkSizes = []int{1 << 10, 100 << 10, 1 << 20, 10 << 20, 50 << 20}
+
+var benchmarkUploads = []struct {
+	name string
+	put  func(ctx context.Context, store Store, key string, document any) (PutResult, error)
+}{
+	{name: "tempfile", put: putTempFile},
+	{name: "memory", put: putMemory},
+	{name: "pipe", put: putPipe},
+}
+
+func BenchmarkPutMemoryStore(b *testing.B) {
+	benchmarkPut(b, NewMemoryStore())
+}
+
+func BenchmarkPutFileStore(b *testing.B) {
+	store, err := NewFileStore(b.TempDir())
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkPut(b, store)
+}
+
+func BenchmarkPutS3(b *testing.B) {
+	if os.Getenv("S3_BUCKET") == "" || os.Getenv("S3_REGION") == "" {
+		b.Skip("S3_BUCKET and S3_REGION are not set")
+	}
+	storageCfg, err := ConfigFromEnv("s3", nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	store, err := New(context.Background(), storageCfg)
+	if err != nil {
+		b.Fatal(err)
+	}
+	benchmarkPut(b, store)
+}
+
+func benchmarkPut(b *testing.B, store Store) {
+	ctx := context.Background()
+	for _, size := range benchmarkSizes {
+		document := generateDocument(size)
+		data, err := json.Marshal(document)
+		if err != nil {
+			b.Fatal(err)
+		}
+		// the encoder used by pipe ends the document with a newline
+		checksums := map[string]bool{Checksum(data): true, Checksum(append(data, '\n')): true}
+
+		for _, upload := range benchmarkUploads {
+			key := fmt.Sprintf("bench/%s-%d.json", upload.name, size)
+			b.Run(upload.name+"/"+formatSize(size), func(b *testing.B) {
+				b.SetBytes(int64(len(data)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					result, err := upload.put(ctx, store, key, document)
+					if err != nil {
+						b.Fatal(err)
+					}
+					if !checksums[result.SHA256] {
+						b.Fatalf("got checksum %s, want %s", result.SHA256, Checksum(data))
+					}
+				}
+			})
+			if err := store.Delete(ctx, key); err != nil {
+				b.Errorf("error deleting %s: %v", key, err)
+			}
+		}
+	}
+}
+
+func putTempFile(ctx context.Context, store Store, key string, document any) (PutResult, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return PutResult{}, err
+	}
+	tempFile, err := os.CreateTemp("", "bench-storage-*.json")
+	if err != nil {
+		return PutResult{}, err
+	}
+	defer os.Remove(tempFile.Name())
+	defer tempFile.Close()
+
+	if _, err := tempFile.Write(data); err != nil {
+		return PutResult{}, err
+	}
+	if _, err := tempFile.Seek(0, io.SeekStart); err != nil {
+		return PutResult{}, err
+	}
+	return store.Put(ctx, key, tempFile)
+}
+
+func putMemory(ctx context.Context, store Store, key string, document any) (PutResult, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return PutResult{}, err
+	}
+	return store.Put(ctx, key, bytes.NewReader(data))
+}
+
+func putPipe(ctx context.Context, store Store, key string, document any) (PutResult, error) {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(json.NewEncoder(writer).Encode(document))
+	}()
+	result, err := store.Put(ctx, key, reader)
+	// unblocks the encoder if Put stopped reading early
+	reader.Close()
+	return result, err
+}
+
+// generateDocument builds a json document shaped like a typical api resource list, of about size bytes
+func generateDocument(size int) map[string]any {
+	item := func(i int) map[string]any {
+		return map[string]any{
+			"id":     i,
+			"name":   "user " + strconv.Itoa(i),
+			"email":  "user" + strconv.Itoa(i) + "@example.com",
+			"active": i%2 == 0,
+			"tags":   []any{"tag" + strconv.Itoa(i%7), "tag" + strconv.Itoa(i%11)},
+			"bio":    strings.Repeat("lorem ipsum ", 4),
+		}
+	}
+	itemData, _ := json.Marshal(item(0))
+	users := []any{}
+	for i := 0; i < max(1, size/(len(itemData)+8)); i++ {
+		users = append(users, item(i))
+	}
+	return map[string]any{"users": users}
+}
+
+func formatSize(size int) string {
+	if size >= 1<<20 {
+		return fmt.Sprintf("%dMB", size>>20)
+	}
+	return fmt.Sprintf("%dKB", size>>10)
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// FileStore keeps blobs as files under a directory, a key "a/b.json" is stored in "{Dir}/a/b.json"
+type FileStore struct {
+	Dir string
+}
+
+func NewFileStore(dir string) (*FileStore, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("NewFileStore: invalid directory: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("NewFileStore: error creating directory: %w", err)
+	}
+	return &FileStore{Dir: dir}, nil
+}
+
+// filePath returns where key is stored, keys cannot refer to files outside of the directory
+func (s *FileStore) filePath(key string) (string, error) {
+	if key == "" || !fs.ValidPath(key) {
+		return "", fmt.Errorf("invalid key %q", key)
+	}
+	return filepath.Join(s.Dir, filepath.FromSlash(key)), nil
+}
+
+func (s *FileStore) Get(ctx context.Context, key string) ([]byte, error) {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return nil, fmt.Errorf("FileStore.Get: %w", err)
+	}
+	data, err := os.ReadFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("FileStore.Get: error reading file: %w", err)
+	}
+	return data, nil
+}
+
+// Put copies the body to a temporary file next to the destination and renames it,
+// so that readers never see a partially written file
+func (s *FileStore) Put(ctx context.Context, key string, body io.Reader) (PutResult, error) {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return PutResult{}, fmt.Errorf("FileStore.Put: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(filePath), 0o755); err != nil {
+		return PutResult{}, fmt.Errorf("FileStore.Put: error creating directory: %w", err)
+	}
+
+	tempFile, err := os.CreateTemp(filepath.Dir(filePath), ".tmp-*")
+	if err != nil {
+		return PutResult{}, fmt.Errorf("FileStore.Put: error creating temp file: %w", err)
+	}
+	defer os.Remove(tempFile.Name())
+
+	reader := newChecksumReader(body)
+	if _, err := io.Copy(tempFile, reader); err != nil {
+		tempFile.Close()
+		return PutResult{}, fmt.Errorf("FileStore.Put: error writing temp file: %w", err)
+	}
+	if err := tempFile.Close(); err != nil {
+		return PutResult{}, fmt.Errorf("FileStore.Put: error closing temp file: %w", err)
+	}
+	if err := os.Rename(tempFile.Name(), filePath); err != nil {
+		return PutResult{}, fmt.Errorf("FileStore.Put: error renaming temp file: %w", err)
+	}
+	return reader.result(), nil
+}
+
+func (s *FileStore) Delete(ctx context.Context, key string) error {
+	filePath, err := s.filePath(key)
+	if err != nil {
+		return fmt.Errorf("FileStore.Delete: %w", err)
+	}
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("FileStore.Delete: error removing file: %w", err)
+	}
+	return nil
+}
+
+func (s *FileStore) List(ctx context.Context, prefix string) ([]string, error) {
+	// only walk the directory the prefix is in
+	root := s.Dir
+	if dir := path.Dir(prefix); strings.Contains(prefix, "/") && fs.ValidPath(dir) {
+		root = filepath.Join(s.Dir, filepath.FromSlash(dir))
+	}
+
+	keys := []string{}
+	err := filepath.WalkDir(root, func(filePath string, entry fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".tmp-") {
+			return nil
+		}
+		relativePath, err := filepath.Rel(s.Dir, filePath)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(relativePath); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("FileStore.List: error listing files: %w", err)
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+func (s *FileStore) URL(key string) string {
+	return "file://" + filepath.ToSlash(filepath.Join(s.Dir, filepath.FromSlash(key)))
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// FileKey is the key of the current contents of a json file
+func FileKey(userId uuid.UUID, fileId uuid.UUID) string {
+	return fmt.Sprintf("%s/%s.json", userId.String(), fileId.String())
+}
+
+// ParseFileKey returns the user and json file of a key made by FileKey, ok is false for any other key such as a revision
+func ParseFileKey(key string) (userId uuid.UUID, fileId uuid.UUID, ok bool) {
+	user, file, found := strings.Cut(key, "/")
+	file, isJson := strings.CutSuffix(file, ".json")
+	if !found || !isJson {
+		return uuid.Nil, uuid.Nil, false
+	}
+	userId, err := uuid.Parse(user)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	fileId, err = uuid.Parse(file)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, false
+	}
+	// uuids can be written in other forms, which FileKey never makes
+	return userId, fileId, FileKey(userId, fileId) == key
+}
+
+// revisionKey is the key of the contents of a json file at a revision, revisions are never overwritten
+func revisionKey(userId uuid.UUID, fileId uuid.UUID, revision int32) string {
+	return fmt.Sprintf("%s/%s/revisions/%d.json", userId.String(), fileId.String(), revision)
+}
+
+func cacheKey(userId uuid.UUID, fileId uuid.UUID) string {
+	return fmt.Sprintf("json:%s:%s", userId.String(), fileId.String())
+}
+
+func GetJson(ctx context.Context, store Store, rdb redis.Cmdable, userId uuid.UUID, fileId uuid.UUID) (any, error) {
+	// get from cache first
+	cachedData, err := rdb.Get(ctx, cacheKey(userId, fileId)).Bytes()
+	if err == nil {
+		// cache hit
+		var result any
+		if err := json.Unmarshal(cachedData, &result); err != nil {
+			fmt.Printf("GetJson: failed to unmarshal cached json: %v\n", err)
+		} else {
+			return result, nil
+		}
+	} else if err != redis.Nil {
+		// redis error, not just cache miss
+		fmt.Printf("GetJson: redis error: %v\n", err)
+	}
+
+	// cache miss or error
+	data, err := store.Get(ctx, FileKey(userId, fileId))
+	if err != nil {
+		return nil, fmt.Errorf("GetJson: error getting file from store: %w", err)
+	}
+
+	// cache it
+	rdb.Set(ctx, cacheKey(userId, fileId), data, 24*time.Hour)
+
+	var result any
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("GetJson: error unmarshalling json: %w", err)
+	}
+
+	return result, nil
+}
+
+func PutJson(ctx context.Context, store Store, rdb redis.Cmdable, userId uuid.UUID, fileId uuid.UUID, payload any) (PutResult, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return PutResult{}, fmt.Errorf("PutJson: error marshalling data: %w", err)
+	}
+	return PutJsonData(ctx, store, rdb, userId, fileId, data)
+}
+
+// PutJsonData is PutJson for contents that are already marshalled, they are uploaded straight from memory
+func PutJsonData(ctx context.Context, store Store, rdb redis.Cmdable, userId uuid.UUID, fileId uuid.UUID, data []byte) (PutResult, error) {
+	result, err := store.Put(ctx, FileKey(userId, fileId), bytes.NewReader(data))
+	if err != nil {
+		return PutResult{}, fmt.Errorf("PutJsonData: error saving file to store: %w", err)
+	}
+
+	// cache json file
+	err = rdb.Set(ctx, cacheKey(userId, fileId), data, 24*time.Hour).Err()
+	if err != nil {
+		fmt.Printf("PutJsonData: failed to cache JSON: %v\n", err)
+		// a stale cache entry would make the next write start from old contents
+		rdb.Del(ctx, cacheKey(userId, fileId))
+	}
+	return result, nil
+}
+
+// ClearJsonCache drops the cached contents of a json file, so that the next read goes to the store
+func ClearJsonCache(ctx context.Context, rdb redis.Cmdable, userId uuid.UUID, fileId uuid.UUID) {
+	rdb.Del(ctx, cacheKey(userId, fileId))
+}
+
+// DeleteJson deletes a json file along with all of its revisions
+func DeleteJson(ctx context.Context, store Store, rdb redis.Cmdable, userId uuid.UUID, fileId uuid.UUID) error {
+	if err := store.Delete(ctx, FileKey(userId, fileId)); err != nil {
+		return fmt.Errorf("DeleteJson: error deleting file from store: %w", err)
+	}
+
+	// delete from cache
+	ClearJsonCache(ctx, rdb, userId, fileId)
+
+	revisionKeys, err := store.List(ctx, fmt.Sprintf("%s/%s/revisions/", userId.String(), fileId.String()))
+	if err != nil {
+		return fmt.Errorf("DeleteJson: error listing revisions: %w", err)
+	}
+	for _, key := range revisionKeys {
+		if err := store.Delete(ctx, key); err != nil {
+			return fmt.Errorf("DeleteJson: error deleting revision: %w", err)
+		}
+	}
+	return nil
+}
+
+func PutJsonRevision(ctx context.Context, store Store, userId uuid.UUID, fileId uuid.UUID, revision int32, data []byte) (PutResult, error) {
+	result, err := store.Put(ctx, revisionKey(userId, fileId, revision), bytes.NewReader(data))
+	if err != nil {
+		return PutResult{}, fmt.Errorf("PutJsonRevision: error saving revision to store: %w", err)
+	}
+	return result, nil
+}
+
+func GetJsonRevision(ctx context.Context, store Store, userId uuid.UUID, fileId uuid.UUID, revision int32) (any, error) {
+	data, err := store.Get(ctx, revisionKey(userId, fileId, revision))
+	if err != nil {
+		return nil, fmt.Errorf("GetJsonRevision: error getting revision from store: %w", err)
+	}
+
+	var contents any
+	if err := json.Unmarshal(data, &contents); err != nil {
+		return nil, fmt.Errorf("GetJsonRevision: error unmarshalling json: %w", err)
+	}
+	return contents, nil
+}
+
+func DeleteJsonRevision(ctx context.Context, store Store, userId uuid.UUID, fileId uuid.UUID, revision int32) error {
+	if err := store.Delete(ctx, revisionKey(userId, fileId, revision)); err != nil {
+		return fmt.Errorf("DeleteJsonRevision: error deleting revision from store: %w", err)
+	}
+	return nil
+}
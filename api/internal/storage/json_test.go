@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestParseFileKey(t *testing.T) {
+	userId, fileId := uuid.New(), uuid.New()
+	gotUser, gotFile, ok := ParseFileKey(FileKey(userId, fileId))
+	if !ok || gotUser != userId || gotFile != fileId {
+		t.Errorf("got %s, %s, %v, want %s, %s, true", gotUser, gotFile, ok, userId, fileId)
+	}
+
+	for _, key := range []string{
+		revisionKey(userId, fileId, 1),
+		userId.String() + "/" + fileId.String(),
+		userId.String() + "/not-a-uuid.json",
+		"not-a-uuid/" + fileId.String() + ".json",
+		userId.String() + "/{" + fileId.String() + "}.json",
+		fileId.String() + ".json",
+		"",
+	} {
+		if _, _, ok := ParseFileKey(key); ok {
+			t.Errorf("ParseFileKey(%q): got ok, want a key that is not a file key", key)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/pl3lee/restjson/internal/database"
+)
+
+// PostgresStore keeps blobs in the json_contents table, parsed in the jsonb contents column
+// and exactly as they were written in the raw column, so that Get returns the same bytes as Put was given.
+// Blobs must be json. Like every Store it only reads and writes whole blobs, jsonb is not used yet for
+// partial updates or queries.
+type PostgresStore struct {
+	Db *database.Queries
+}
+
+func (s *PostgresStore) Get(ctx context.Context, key string) ([]byte, error) {
+	contents, err := s.Db.GetJsonContents(ctx, key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("PostgresStore.Get: error getting contents: %w", err)
+	}
+	return []byte(contents), nil
+}
+
+// Put reads the whole body before saving it, since postgres needs the complete document to parse it
+func (s *PostgresStore) Put(ctx context.Context, key string, body io.Reader) (PutResult, error) {
+	reader := newChecksumReader(body)
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return PutResult{}, fmt.Errorf("PostgresStore.Put: error reading body: %w", err)
+	}
+	err = s.Db.UpsertJsonContents(ctx, database.UpsertJsonContentsParams{
+		Key: key,
+		Raw: string(data),
+	})
+	if err != nil {
+		return PutResult{}, fmt.Errorf("PostgresStore.Put: error saving contents: %w", err)
+	}
+	return reader.result(), nil
+}
+
+func (s *PostgresStore) Delete(ctx context.Context, key string) error {
+	if err := s.Db.DeleteJsonContents(ctx, key); err != nil {
+		return fmt.Errorf("PostgresStore.Delete: error deleting contents: %w", err)
+	}
+	return nil
+}
+
+func (s *PostgresStore) List(ctx context.Context, prefix string) ([]string, error) {
+	keys, err := s.Db.ListJsonContentsKeys(ctx, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("PostgresStore.List: error listing keys: %w", err)
+	}
+	if keys == nil {
+		keys = []string{}
+	}
+	return keys, nil
+}
+
+func (s *PostgresStore) URL(key string) string {
+	return "postgres:///json_contents/" + key
+}
@@ -0,0 +1,256 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// s3PartSize is the size of the parts of multipart uploads, and the largest blob uploaded in a single request.
+// S3 needs parts of at least 5 MB, except for the last one.
+const s3PartSize = 8 << 20
+
+// S3Store keeps blobs as objects in an S3 bucket, on AWS or any S3 compatible service
+type S3Store struct {
+	Client *s3.Client
+	Bucket string
+	Region string
+	// Endpoint is the url of an S3 compatible service, empty for AWS
+	Endpoint     string
+	UsePathStyle bool
+}
+
+func newS3Store(ctx context.Context, cfg Config) (*S3Store, error) {
+	options := []func(*config.LoadOptions) error{config.WithRegion(cfg.S3Region)}
+	if cfg.S3AccessKeyID != "" || cfg.S3SecretAccessKey != "" {
+		if cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			return nil, errors.New("newS3Store: both an access key id and a secret access key are needed")
+		}
+		options = append(options, config.WithCredentialsProvider(
+			credentials.NewStaticCredentialsProvider(cfg.S3AccessKeyID, cfg.S3SecretAccessKey, ""),
+		))
+	}
+	awsCfg, err := config.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return nil, fmt.Errorf("newS3Store: cannot load aws config: %w", err)
+	}
+
+	endpoint := strings.TrimSuffix(cfg.S3Endpoint, "/")
+	if endpoint != "" {
+		parsedEndpoint, err := url.Parse(endpoint)
+		if err != nil || parsedEndpoint.Scheme == "" || parsedEndpoint.Host == "" {
+			return nil, fmt.Errorf("newS3Store: invalid endpoint %q, expected a url such as http://localhost:9000", cfg.S3Endpoint)
+		}
+	}
+	client := s3.NewFromConfig(awsCfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+		o.UsePathStyle = cfg.S3UsePathStyle
+	})
+	return &S3Store{
+		Client:       client,
+		Bucket:       cfg.S3Bucket,
+		Region:       cfg.S3Region,
+		Endpoint:     endpoint,
+		UsePathStyle: cfg.S3UsePathStyle,
+	}, nil
+}
+
+func (s *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	result, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("S3Store.Get: error getting object from S3: %w", err)
+	}
+	defer result.Body.Close()
+
+	data, err := io.ReadAll(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("S3Store.Get: error reading object body: %w", err)
+	}
+	return data, nil
+}
+
+// Put uploads blobs that fit in a single part with one request, and larger blobs with a multipart upload.
+// At most one part of the body is held in memory, and S3 verifies the sha256 checksum of every part.
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader) (PutResult, error) {
+	reader := newChecksumReader(body)
+	buf := make([]byte, s3PartSize)
+	part, err := readPart(reader, buf)
+	if err != nil {
+		return PutResult{}, fmt.Errorf("S3Store.Put: error reading body: %w", err)
+	}
+
+	if len(part) < s3PartSize {
+		_, err = s.Client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:         aws.String(s.Bucket),
+			Key:            aws.String(key),
+			Body:           bytes.NewReader(part),
+			ContentLength:  aws.Int64(int64(len(part))),
+			ContentType:    aws.String("application/json"),
+			ChecksumSHA256: aws.String(base64Checksum(part)),
+		})
+		if err != nil {
+			return PutResult{}, fmt.Errorf("S3Store.Put: error uploading to S3: %w", err)
+		}
+		return reader.result(), nil
+	}
+
+	if err := s.putMultipart(ctx, key, reader, buf); err != nil {
+		return PutResult{}, fmt.Errorf("S3Store.Put: %w", err)
+	}
+	return reader.result(), nil
+}
+
+// putMultipart uploads the part already read into buf, followed by the rest of body
+func (s *S3Store) putMultipart(ctx context.Context, key string, body io.Reader, buf []byte) error {
+	upload, err := s.Client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket:            aws.String(s.Bucket),
+		Key:               aws.String(key),
+		ContentType:       aws.String("application/json"),
+		ChecksumAlgorithm: types.ChecksumAlgorithmSha256,
+	})
+	if err != nil {
+		return fmt.Errorf("putMultipart: error creating multipart upload: %w", err)
+	}
+	abort := func() {
+		// parts of an upload that is never completed or aborted are kept, and billed, by S3
+		_, err := s.Client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.Bucket),
+			Key:      aws.String(key),
+			UploadId: upload.UploadId,
+		})
+		if err != nil {
+			log.Printf("putMultipart: error aborting multipart upload of %s: %v", key, err)
+		}
+	}
+
+	parts := []types.CompletedPart{}
+	part := buf
+	for partNumber := int32(1); len(part) > 0; partNumber++ {
+		checksum := base64Checksum(part)
+		uploaded, err := s.Client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:         aws.String(s.Bucket),
+			Key:            aws.String(key),
+			UploadId:       upload.UploadId,
+			PartNumber:     aws.Int32(partNumber),
+			Body:           bytes.NewReader(part),
+			ContentLength:  aws.Int64(int64(len(part))),
+			ChecksumSHA256: aws.String(checksum),
+		})
+		if err != nil {
+			abort()
+			return fmt.Errorf("putMultipart: error uploading part %d: %w", partNumber, err)
+		}
+		parts = append(parts, types.CompletedPart{
+			ETag:           uploaded.ETag,
+			PartNumber:     aws.Int32(partNumber),
+			ChecksumSHA256: aws.String(checksum),
+		})
+
+		// the part has been uploaded, so buf can be reused for the next one
+		part, err = readPart(body, buf)
+		if err != nil {
+			abort()
+			return fmt.Errorf("putMultipart: error reading body: %w", err)
+		}
+	}
+
+	_, err = s.Client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(s.Bucket),
+		Key:             aws.String(key),
+		UploadId:        upload.UploadId,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		abort()
+		return fmt.Errorf("putMultipart: error completing multipart upload: %w", err)
+	}
+	return nil
+}
+
+// readPart fills buf from body, and returns less than a full buf only at the end of body
+func readPart(body io.Reader, buf []byte) ([]byte, error) {
+	n, err := io.ReadFull(body, buf)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return buf[:n], nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// base64Checksum is the sha256 checksum of data in the encoding S3 expects
+func base64Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	_, err := s.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("S3Store.Delete: error deleting object from S3: %w", err)
+	}
+	return nil
+}
+
+func (s *S3Store) List(ctx context.Context, prefix string) ([]string, error) {
+	paginator := s3.NewListObjectsV2Paginator(s.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(prefix),
+	})
+	keys := []string{}
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("S3Store.List: error listing objects: %w", err)
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+	}
+	return keys, nil
+}
+
+// URL follows the addressing style of the client, so that it points at the configured endpoint
+func (s *S3Store) URL(key string) string {
+	if s.Endpoint == "" {
+		if s.UsePathStyle {
+			return fmt.Sprintf("https://s3.%s.amazonaws.com/%s/%s", s.Region, s.Bucket, key)
+		}
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.Bucket, s.Region, key)
+	}
+	if s.UsePathStyle {
+		return fmt.Sprintf("%s/%s/%s", s.Endpoint, s.Bucket, key)
+	}
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return fmt.Sprintf("%s/%s/%s", s.Endpoint, s.Bucket, key)
+	}
+	endpoint.Host = s.Bucket + "." + endpoint.Host
+	return fmt.Sprintf("%s/%s", endpoint.String(), key)
+}
@@ -0,0 +1,134 @@
+// Package storage stores the contents of json files. Contents are kept in a Store,
+// which can be backed by S3, the local filesystem, memory or postgres, and cached in redis.
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/pl3lee/restjson/internal/database"
+)
+
+// ErrNotFound is returned by Get when there is nothing stored under a key
+var ErrNotFound = errors.New("not found")
+
+// Store stores blobs under keys such as "{userId}/{fileId}.json".
+// Keys use / as a separator, and deleting a key that does not exist is not an error.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Put reads body until EOF and stores it under key, body can be a pipe that is still being written to
+	Put(ctx context.Context, key string, body io.Reader) (PutResult, error)
+	Delete(ctx context.Context, key string) error
+	// List returns the keys starting with prefix, sorted
+	List(ctx context.Context, prefix string) ([]string, error)
+	// URL is where the blob stored under key can be found, for display only
+	URL(key string) string
+}
+
+// PutResult describes a stored blob, as it was read from the body given to Put
+type PutResult struct {
+	Size int64
+	// SHA256 is the hex encoded sha256 checksum of the blob
+	SHA256 string
+}
+
+// checksumReader computes the size and checksum of everything read through it
+type checksumReader struct {
+	reader io.Reader
+	hash   hash.Hash
+	size   int64
+}
+
+func newChecksumReader(reader io.Reader) *checksumReader {
+	return &checksumReader{reader: reader, hash: sha256.New()}
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	c.hash.Write(p[:n])
+	c.size += int64(n)
+	return n, err
+}
+
+func (c *checksumReader) result() PutResult {
+	return PutResult{Size: c.size, SHA256: hex.EncodeToString(c.hash.Sum(nil))}
+}
+
+// Checksum returns the hex encoded sha256 checksum of data, as reported by Put
+func Checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// Config selects and configures a store, Backend is one of s3, filesystem, memory or postgres
+type Config struct {
+	Backend  string
+	S3Bucket string
+	S3Region string
+	// S3Endpoint points the client at an S3 compatible service such as MinIO, empty for AWS
+	S3Endpoint string
+	// S3UsePathStyle addresses buckets as {endpoint}/{bucket} instead of {bucket}.{endpoint}
+	S3UsePathStyle bool
+	// S3AccessKeyID and S3SecretAccessKey override the default AWS credential chain when both are set
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	Dir               string
+	Db                *database.Queries
+}
+
+// ConfigFromEnv reads the configuration of backend from the environment variables of the api:
+// S3_BUCKET, S3_REGION, S3_ENDPOINT, S3_USE_PATH_STYLE, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY for s3,
+// and STORAGE_DIR for filesystem. Db is only used by postgres.
+func ConfigFromEnv(backend string, db *database.Queries) (Config, error) {
+	usePathStyle := false
+	if value := os.Getenv("S3_USE_PATH_STYLE"); value != "" {
+		var err error
+		usePathStyle, err = strconv.ParseBool(value)
+		if err != nil {
+			return Config{}, errors.New("ConfigFromEnv: S3_USE_PATH_STYLE should be true or false")
+		}
+	}
+	return Config{
+		Backend:           backend,
+		S3Bucket:          os.Getenv("S3_BUCKET"),
+		S3Region:          os.Getenv("S3_REGION"),
+		S3Endpoint:        os.Getenv("S3_ENDPOINT"),
+		S3UsePathStyle:    usePathStyle,
+		S3AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		Dir:               os.Getenv("STORAGE_DIR"),
+		Db:                db,
+	}, nil
+}
+
+// New creates the store selected by cfg, an empty backend is s3
+func New(ctx context.Context, cfg Config) (Store, error) {
+	switch cfg.Backend {
+	case "", "s3":
+		if cfg.S3Bucket == "" || cfg.S3Region == "" {
+			return nil, errors.New("New: s3 storage needs a bucket and a region")
+		}
+		return newS3Store(ctx, cfg)
+	case "filesystem":
+		if cfg.Dir == "" {
+			return nil, errors.New("New: filesystem storage needs a directory")
+		}
+		return NewFileStore(cfg.Dir)
+	case "memory":
+		return NewMemoryStore(), nil
+	case "postgres":
+		if cfg.Db == nil {
+			return nil, errors.New("New: postgres storage needs a database")
+		}
+		return &PostgresStore{Db: cfg.Db}, nil
+	default:
+		return nil, fmt.Errorf("New: unknown backend %q, expected s3, filesystem, memory or postgres", cfg.Backend)
+	}
+}
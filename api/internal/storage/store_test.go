@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// testStore checks the behavior every Store has to share, on an empty store
+func testStore(t *testing.T, store Store) {
+	ctx := context.Background()
+
+	t.Run("get missing key", func(t *testing.T) {
+		if _, err := store.Get(ctx, "missing.json"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("got error %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("put and get", func(t *testing.T) {
+		data := []byte(`{"posts": []}`)
+		result, err := store.Put(ctx, "user/file.json", bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		if result.Size != int64(len(data)) || result.SHA256 != Checksum(data) {
+			t.Errorf("got result %+v, want size %d and checksum %s", result, len(data), Checksum(data))
+		}
+		got, err := store.Get(ctx, "user/file.json")
+		if err != nil {
+			t.Fatalf("Get: %v", err)
+		}
+		if !bytes.Equal(got, data) {
+			t.Errorf("got %q, want %q", got, data)
+		}
+
+		// changing what Get returned does not change what is stored
+		got[0] = '['
+		if again, _ := store.Get(ctx, "user/file.json"); !bytes.Equal(again, data) {
+			t.Errorf("got %q after changing a previous result, want %q", again, data)
+		}
+	})
+
+	t.Run("put overwrites", func(t *testing.T) {
+		store.Put(ctx, "overwrite.json", strings.NewReader(`{"version": 1}`))
+		if _, err := store.Put(ctx, "overwrite.json", strings.NewReader(`{"version": 2}`)); err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		if got, _ := store.Get(ctx, "overwrite.json"); string(got) != `{"version": 2}` {
+			t.Errorf("got %q, want the second version", got)
+		}
+	})
+
+	t.Run("put from a pipe", func(t *testing.T) {
+		data := bytes.Repeat([]byte("0123456789"), 100_000)
+		reader, writer := io.Pipe()
+		go func() {
+			// small writes, so that Put reads while the body is still being written
+			for chunk := range slices.Chunk(data, 1000) {
+				if _, err := writer.Write(chunk); err != nil {
+					return
+				}
+			}
+			writer.Close()
+		}()
+		result, err := store.Put(ctx, "pipe.json", reader)
+		if err != nil {
+			t.Fatalf("Put: %v", err)
+		}
+		if result.Size != int64(len(data)) || result.SHA256 != Checksum(data) {
+			t.Errorf("got result %+v, want size %d and checksum %s", result, len(data), Checksum(data))
+		}
+		if got, _ := store.Get(ctx, "pipe.json"); !bytes.Equal(got, data) {
+			t.Errorf("got %d bytes, want %d", len(got), len(data))
+		}
+	})
+
+	t.Run("failed put keeps the previous blob", func(t *testing.T) {
+		store.Put(ctx, "failed.json", strings.NewReader(`{"version": 1}`))
+		body := io.MultiReader(strings.NewReader(`{"version": `), iotest.ErrReader(errors.New("connection reset")))
+		if _, err := store.Put(ctx, "failed.json", body); err == nil {
+			t.Fatal("got no error from a body that failed")
+		}
+		if got, _ := store.Get(ctx, "failed.json"); string(got) != `{"version": 1}` {
+			t.Errorf("got %q, want the previous blob", got)
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		store.Put(ctx, "deleted.json", strings.NewReader(`{}`))
+		if err := store.Delete(ctx, "deleted.json"); err != nil {
+			t.Fatalf("Delete: %v", err)
+		}
+		if _, err := store.Get(ctx, "deleted.json"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("got error %v after delete, want ErrNotFound", err)
+		}
+		if err := store.Delete(ctx, "deleted.json"); err != nil {
+			t.Errorf("got error %v deleting a missing key, want none", err)
+		}
+	})
+
+	t.Run("list", func(t *testing.T) {
+		for _, key := range []string{"list/a/2/x.json", "list/a/1.json", "list/a/10.json", "list/ab.json", "list/b/1.json"} {
+			if _, err := store.Put(ctx, key, strings.NewReader(`{}`)); err != nil {
+				t.Fatalf("Put %s: %v", key, err)
+			}
+		}
+		for _, test := range []struct {
+			prefix string
+			want   []string
+		}{
+			{prefix: "list/a/", want: []string{"list/a/1.json", "list/a/10.json", "list/a/2/x.json"}},
+			{prefix: "list/a/1", want: []string{"list/a/1.json", "list/a/10.json"}},
+			{prefix: "list/a", want: []string{"list/a/1.json", "list/a/10.json", "list/a/2/x.json", "list/ab.json"}},
+			{prefix: "list/c/", want: []string{}},
+		} {
+			got, err := store.List(ctx, test.prefix)
+			if err != nil {
+				t.Fatalf("List %q: %v", test.prefix, err)
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("List %q: got %v, want %v", test.prefix, got, test.want)
+			}
+		}
+	})
+
+	t.Run("url", func(t *testing.T) {
+		url := store.URL("user/file.json")
+		if !strings.HasSuffix(url, "/user/file.json") || !strings.Contains(url, "://") {
+			t.Errorf("got url %q, want an absolute url ending with the key", url)
+		}
+		if store.URL("user/other.json") == url {
+			t.Errorf("got the same url %q for different keys", url)
+		}
+	})
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, NewMemoryStore())
+}
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testStore(t, store)
+
+	// keys cannot escape the directory of the store
+	for _, key := range []string{"../outside.json", "/absolute.json", "a/../../outside.json", ""} {
+		if _, err := store.Put(context.Background(), key, strings.NewReader(`{}`)); err == nil {
+			t.Errorf("Put %q: got no error, want an invalid key", key)
+		}
+	}
+}
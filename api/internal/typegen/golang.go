@@ -0,0 +1,90 @@
+package typegen
+
+import (
+	"fmt"
+	"go/format"
+	"strings"
+)
+
+const defaultGoPackage = "types"
+
+func (g *generator) golang() (string, error) {
+	packageName := g.options.Package
+	if packageName == "" {
+		packageName = defaultGoPackage
+	}
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "package %s\n", packageName)
+	for _, declaration := range g.declarations {
+		builder.WriteString("\n")
+		if declaration.alias != nil {
+			fmt.Fprintf(&builder, "type %s %s\n", declaration.name, goType(declaration.alias, false))
+			continue
+		}
+		fmt.Fprintf(&builder, "type %s struct {\n", declaration.name)
+		fieldNames := map[string]bool{}
+		for _, field := range declaration.fields {
+			name := goFieldName(field.name)
+			for i := 2; fieldNames[name]; i++ {
+				name = fmt.Sprintf("%s%d", goFieldName(field.name), i)
+			}
+			fieldNames[name] = true
+
+			tag := field.name
+			if field.optional {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&builder, "\t%s %s `json:%s`\n", name, goType(field.typ, field.optional), quoteString(tag))
+		}
+		builder.WriteString("}\n")
+	}
+
+	source, err := format.Source([]byte(builder.String()))
+	if err != nil {
+		return "", fmt.Errorf("golang: error formatting generated code: %w", err)
+	}
+	return string(source), nil
+}
+
+// goType returns the go type of ref, optional and nullable values are pointers unless the type can already be nil
+func goType(ref *typeRef, optional bool) string {
+	var result string
+	switch ref.kind {
+	case kindPrimitive:
+		switch ref.primitive {
+		case "integer":
+			result = "int"
+		case "number":
+			result = "float64"
+		case "boolean":
+			result = "bool"
+		case "null":
+			return "any"
+		default:
+			result = "string"
+		}
+	case kindEnum:
+		result = "string"
+	case kindArray:
+		return "[]" + goType(ref.elem, false)
+	case kindNamed:
+		result = ref.name
+	default:
+		// unions and unknown values
+		return "any"
+	}
+	if optional || ref.nullable {
+		result = "*" + result
+	}
+	return result
+}
+
+// goFieldName exports a json field name, using the go spelling of id, so userId becomes UserID
+func goFieldName(name string) string {
+	fieldName := pascalCase(name)
+	if strings.HasSuffix(fieldName, "Id") {
+		fieldName = strings.TrimSuffix(fieldName, "Id") + "ID"
+	}
+	return fieldName
+}
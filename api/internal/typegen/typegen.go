@@ -0,0 +1,254 @@
+// Package typegen generates TypeScript, Go and Zod type definitions from JSON Schemas,
+// such as the ones inferred from the contents of a json file.
+package typegen
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+const (
+	LangTypeScript = "typescript"
+	LangGo         = "go"
+	LangZod        = "zod"
+)
+
+// Definition is a named type to generate from a JSON Schema
+type Definition struct {
+	Name   string
+	Schema map[string]any
+}
+
+type Options struct {
+	// Package is the package of generated go code, types by default
+	Package string
+	// Singular names the type of the items of an array field, for example comments into comment
+	Singular func(string) string
+}
+
+// IsValidLang reports whether types can be generated for lang
+func IsValidLang(lang string) bool {
+	return lang == LangTypeScript || lang == LangGo || lang == LangZod
+}
+
+// Generate returns the source code of the definitions in lang.
+// Objects nested in a definition get their own named types, named after the definition and the field.
+func Generate(lang string, definitions []Definition, options Options) (string, error) {
+	if options.Singular == nil {
+		options.Singular = func(name string) string { return name }
+	}
+	g := &generator{
+		options: options,
+		names:   map[string]bool{},
+	}
+	for _, definition := range definitions {
+		g.define(definition)
+	}
+
+	switch lang {
+	case LangTypeScript:
+		return g.typeScript(), nil
+	case LangGo:
+		return g.golang()
+	case LangZod:
+		return g.zod(), nil
+	}
+	return "", fmt.Errorf("Generate: unknown language %q", lang)
+}
+
+type kind int
+
+const (
+	kindUnknown kind = iota
+	kindPrimitive
+	kindEnum
+	kindArray
+	kindNamed
+	kindUnion
+)
+
+// typeRef is the type of a value, independent of the language
+type typeRef struct {
+	kind      kind
+	primitive string
+	format    string
+	enum      []string
+	elem      *typeRef
+	name      string
+	options   []*typeRef
+	nullable  bool
+}
+
+type field struct {
+	name     string
+	optional bool
+	typ      *typeRef
+}
+
+// declaration is either an object with fields or an alias of another type
+type declaration struct {
+	name   string
+	fields []field
+	alias  *typeRef
+}
+
+type generator struct {
+	options      Options
+	names        map[string]bool
+	declarations []declaration
+}
+
+func (g *generator) define(definition Definition) {
+	name := g.uniqueName(definition.Name)
+	if schemaTypes(definition.Schema)["object"] && len(schemaTypes(definition.Schema)) == 1 {
+		g.object(name, definition.Schema)
+		return
+	}
+	ref := g.resolve(definition.Schema, name+"Item")
+	g.declarations = append(g.declarations, declaration{name: name, alias: ref})
+}
+
+// object declares an object type, after the types nested in it so that they are declared before they are used
+func (g *generator) object(name string, schema map[string]any) {
+	properties, _ := schema["properties"].(map[string]any)
+	required := map[string]bool{}
+	if requiredList, ok := schema["required"].([]any); ok {
+		for _, property := range requiredList {
+			if property, ok := property.(string); ok {
+				required[property] = true
+			}
+		}
+	}
+
+	fields := []field{}
+	for _, property := range sortedKeys(properties) {
+		propertySchema, _ := properties[property].(map[string]any)
+		nestedName := name + pascalCase(property)
+		if schemaTypes(propertySchema)["array"] {
+			nestedName = name + pascalCase(g.options.Singular(property))
+		}
+		fields = append(fields, field{
+			name:     property,
+			optional: !required[property],
+			typ:      g.resolve(propertySchema, nestedName),
+		})
+	}
+	g.declarations = append(g.declarations, declaration{name: name, fields: fields})
+}
+
+// resolve converts a schema into a type, declaring the objects it contains with names based on nestedName
+func (g *generator) resolve(schema map[string]any, nestedName string) *typeRef {
+	types := schemaTypes(schema)
+	nullable := types["null"]
+	delete(types, "null")
+
+	var ref *typeRef
+	switch len(types) {
+	case 0:
+		if nullable {
+			return &typeRef{kind: kindPrimitive, primitive: "null"}
+		}
+		return &typeRef{kind: kindUnknown}
+	case 1:
+		for t := range types {
+			ref = g.resolveType(t, schema, nestedName)
+		}
+	default:
+		ref = &typeRef{kind: kindUnion}
+		// integers are numbers for every language but go, which has no union anyway
+		for _, t := range []string{"object", "array", "string", "integer", "number", "boolean"} {
+			if types[t] {
+				ref.options = append(ref.options, g.resolveType(t, schema, nestedName))
+			}
+		}
+	}
+	ref.nullable = nullable
+	return ref
+}
+
+func (g *generator) resolveType(t string, schema map[string]any, nestedName string) *typeRef {
+	switch t {
+	case "object":
+		properties, _ := schema["properties"].(map[string]any)
+		if len(properties) == 0 {
+			return &typeRef{kind: kindUnknown}
+		}
+		name := g.uniqueName(nestedName)
+		g.object(name, schema)
+		return &typeRef{kind: kindNamed, name: name}
+	case "array":
+		items, _ := schema["items"].(map[string]any)
+		return &typeRef{kind: kindArray, elem: g.resolve(items, nestedName)}
+	case "string":
+		if enum, ok := schema["enum"].([]any); ok {
+			values := []string{}
+			for _, value := range enum {
+				if value, ok := value.(string); ok {
+					values = append(values, value)
+				}
+			}
+			return &typeRef{kind: kindEnum, enum: values}
+		}
+		format, _ := schema["format"].(string)
+		return &typeRef{kind: kindPrimitive, primitive: "string", format: format}
+	}
+	return &typeRef{kind: kindPrimitive, primitive: t}
+}
+
+func (g *generator) uniqueName(name string) string {
+	unique := name
+	for i := 2; g.names[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	g.names[unique] = true
+	return unique
+}
+
+func schemaTypes(schema map[string]any) map[string]bool {
+	types := map[string]bool{}
+	switch t := schema["type"].(type) {
+	case string:
+		types[t] = true
+	case []any:
+		for _, name := range t {
+			if name, ok := name.(string); ok {
+				types[name] = true
+			}
+		}
+	}
+	return types
+}
+
+// pascalCase turns a key like blog_posts, blog-posts or blogPosts into BlogPosts
+func pascalCase(name string) string {
+	var builder strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			builder.WriteRune(unicode.ToUpper(r))
+			upper = false
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	result := builder.String()
+	if result == "" || unicode.IsDigit(rune(result[0])) {
+		result = "T" + result
+	}
+	return result
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
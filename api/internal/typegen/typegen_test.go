@@ -0,0 +1,75 @@
+package typegen
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pl3lee/restjson/internal/jsonschema"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestGenerateGolden generates the types of testdata/contents.json, like the types of a json file,
+// and compares them with the golden files. Run go test ./internal/typegen -update after changing the output.
+func TestGenerateGolden(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "contents.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	contents := map[string]any{}
+	if err := json.Unmarshal(data, &contents); err != nil {
+		t.Fatal(err)
+	}
+	definitions := []Definition{}
+	for _, key := range sortedKeys(contents) {
+		definitions = append(definitions, Definition{Name: pascalCase(key), Schema: jsonschema.Infer(contents[key])})
+	}
+	options := Options{Singular: func(name string) string { return strings.TrimSuffix(name, "s") }}
+
+	for _, test := range []struct {
+		lang   string
+		golden string
+	}{
+		{LangTypeScript, "types.ts.golden"},
+		{LangGo, "types.go.golden"},
+		{LangZod, "types.zod.ts.golden"},
+	} {
+		t.Run(test.lang, func(t *testing.T) {
+			source, err := Generate(test.lang, definitions, options)
+			if err != nil {
+				t.Fatalf("Generate: %v", err)
+			}
+			path := filepath.Join("testdata", test.golden)
+			if *update {
+				if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if source != string(want) {
+				t.Errorf("generated %s differs from %s, got\n%s", test.lang, path, source)
+			}
+		})
+	}
+}
+
+func TestGenerateGoPackage(t *testing.T) {
+	source, err := Generate(LangGo, []Definition{{Name: "Settings", Schema: map[string]any{"type": "string"}}}, Options{Package: "api"})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if want := "package api\n\ntype Settings string\n"; source != want {
+		t.Errorf("got\n%s\nwant\n%s", source, want)
+	}
+	if _, err := Generate("rust", nil, Options{}); err == nil {
+		t.Error("Generate succeeded for an unknown language")
+	}
+}
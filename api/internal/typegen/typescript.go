@@ -0,0 +1,93 @@
+package typegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var identifierPattern = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*$`)
+
+func (g *generator) typeScript() string {
+	var builder strings.Builder
+	for i, declaration := range g.declarations {
+		if i > 0 {
+			builder.WriteString("\n")
+		}
+		if declaration.alias != nil {
+			fmt.Fprintf(&builder, "export type %s = %s;\n", declaration.name, typeScriptType(declaration.alias))
+			continue
+		}
+		fmt.Fprintf(&builder, "export interface %s {\n", declaration.name)
+		for _, field := range declaration.fields {
+			optional := ""
+			if field.optional {
+				optional = "?"
+			}
+			fmt.Fprintf(&builder, "  %s%s: %s;\n", propertyName(field.name), optional, typeScriptType(field.typ))
+		}
+		builder.WriteString("}\n")
+	}
+	return builder.String()
+}
+
+func typeScriptType(ref *typeRef) string {
+	var result string
+	switch ref.kind {
+	case kindPrimitive:
+		switch ref.primitive {
+		case "integer", "number":
+			result = "number"
+		default:
+			result = ref.primitive
+		}
+	case kindEnum:
+		values := []string{}
+		for _, value := range ref.enum {
+			values = append(values, quoteString(value))
+		}
+		result = strings.Join(values, " | ")
+	case kindArray:
+		elem := typeScriptType(ref.elem)
+		if strings.Contains(elem, " ") {
+			elem = "(" + elem + ")"
+		}
+		result = elem + "[]"
+	case kindNamed:
+		result = ref.name
+	case kindUnion:
+		options := []string{}
+		for _, option := range ref.options {
+			option := typeScriptType(option)
+			// integer and number are both number
+			if !slices.Contains(options, option) {
+				options = append(options, option)
+			}
+		}
+		result = strings.Join(options, " | ")
+	default:
+		result = "unknown"
+	}
+	if ref.nullable && ref.kind != kindUnknown {
+		result += " | null"
+	}
+	return result
+}
+
+// propertyName quotes property names that are not valid identifiers
+func propertyName(name string) string {
+	if identifierPattern.MatchString(name) {
+		return name
+	}
+	return quoteString(name)
+}
+
+func quoteString(s string) string {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return `""`
+	}
+	return string(data)
+}
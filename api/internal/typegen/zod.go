@@ -0,0 +1,79 @@
+package typegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// zodFormats are the string formats zod can check
+var zodFormats = map[string]string{
+	"email":     ".email()",
+	"uuid":      ".uuid()",
+	"uri":       ".url()",
+	"date-time": ".datetime()",
+	"date":      ".date()",
+}
+
+func (g *generator) zod() string {
+	var builder strings.Builder
+	builder.WriteString("import { z } from \"zod\";\n")
+	for _, declaration := range g.declarations {
+		builder.WriteString("\n")
+		if declaration.alias != nil {
+			fmt.Fprintf(&builder, "export const %sSchema = %s;\n", declaration.name, zodType(declaration.alias))
+		} else {
+			fmt.Fprintf(&builder, "export const %sSchema = z.object({\n", declaration.name)
+			for _, field := range declaration.fields {
+				optional := ""
+				if field.optional {
+					optional = ".optional()"
+				}
+				fmt.Fprintf(&builder, "  %s: %s%s,\n", propertyName(field.name), zodType(field.typ), optional)
+			}
+			builder.WriteString("});\n")
+		}
+		fmt.Fprintf(&builder, "export type %s = z.infer<typeof %sSchema>;\n", declaration.name, declaration.name)
+	}
+	return builder.String()
+}
+
+func zodType(ref *typeRef) string {
+	var result string
+	switch ref.kind {
+	case kindPrimitive:
+		switch ref.primitive {
+		case "integer":
+			result = "z.number().int()"
+		case "number":
+			result = "z.number()"
+		case "boolean":
+			result = "z.boolean()"
+		case "null":
+			result = "z.null()"
+		default:
+			result = "z.string()" + zodFormats[ref.format]
+		}
+	case kindEnum:
+		values := []string{}
+		for _, value := range ref.enum {
+			values = append(values, quoteString(value))
+		}
+		result = fmt.Sprintf("z.enum([%s])", strings.Join(values, ", "))
+	case kindArray:
+		result = fmt.Sprintf("z.array(%s)", zodType(ref.elem))
+	case kindNamed:
+		result = ref.name + "Schema"
+	case kindUnion:
+		options := []string{}
+		for _, option := range ref.options {
+			options = append(options, zodType(option))
+		}
+		result = fmt.Sprintf("z.union([%s])", strings.Join(options, ", "))
+	default:
+		result = "z.unknown()"
+	}
+	if ref.nullable && ref.kind != kindUnknown {
+		result += ".nullable()"
+	}
+	return result
+}
@@ -9,10 +9,17 @@ import (
 
 // ErrorResponse represents the structure of error responses
 type ErrorResponse struct {
-	Error string `json:"error" example:"Invalid input"`
+	Error   string `json:"error" example:"Invalid input"`
+	Details any    `json:"details,omitempty"`
 }
 
 func RespondWithError(w http.ResponseWriter, code int, msg string, err error) {
+	RespondWithErrorDetails(w, code, msg, nil, err)
+}
+
+// RespondWithErrorDetails responds like RespondWithError, but also includes
+// machine readable details about the error in the response body
+func RespondWithErrorDetails(w http.ResponseWriter, code int, msg string, details any, err error) {
 	if err != nil {
 		log.Println(err)
 	}
@@ -20,7 +27,8 @@ func RespondWithError(w http.ResponseWriter, code int, msg string, err error) {
 		log.Printf("Responding with 5XX error: %s", msg)
 	}
 	RespondWithJSON(w, code, ErrorResponse{
-		Error: msg,
+		Error:   msg,
+		Details: details,
 	})
 }
 
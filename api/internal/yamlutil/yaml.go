@@ -0,0 +1,215 @@
+// Package yamlutil encodes values as YAML by way of their json encoding,
+// so json struct tags apply and struct fields keep their order.
+package yamlutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// orderedMap is a decoded json object that remembers the order of its keys
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+// Marshal returns the YAML encoding of v
+func Marshal(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("Marshal: error marshalling json: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	node, err := decodeOrdered(decoder)
+	if err != nil {
+		return nil, fmt.Errorf("Marshal: error decoding json: %w", err)
+	}
+
+	var buf bytes.Buffer
+	switch node := node.(type) {
+	case *orderedMap:
+		if len(node.keys) == 0 {
+			buf.WriteString("{}\n")
+		} else {
+			writeMap(&buf, node, 0)
+		}
+	case []any:
+		if len(node) == 0 {
+			buf.WriteString("[]\n")
+		} else {
+			writeList(&buf, node, 0)
+		}
+	default:
+		buf.WriteString(scalar(node))
+		buf.WriteString("\n")
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeOrdered(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		object := &orderedMap{values: map[string]any{}}
+		for decoder.More() {
+			keyToken, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyToken.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected object key, got %v", keyToken)
+			}
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			if _, exists := object.values[key]; !exists {
+				object.keys = append(object.keys, key)
+			}
+			object.values[key] = value
+		}
+		if _, err := decoder.Token(); err != nil && err != io.EOF {
+			return nil, err
+		}
+		return object, nil
+	case json.Delim('['):
+		list := []any{}
+		for decoder.More() {
+			value, err := decodeOrdered(decoder)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		if _, err := decoder.Token(); err != nil && err != io.EOF {
+			return nil, err
+		}
+		return list, nil
+	}
+	return token, nil
+}
+
+func writeMap(buf *bytes.Buffer, object *orderedMap, indent int) {
+	for i, key := range object.keys {
+		// the first key of a map in a list goes on the same line as the dash
+		if i > 0 || buf.Len() == 0 || buf.Bytes()[buf.Len()-1] == '\n' {
+			buf.WriteString(strings.Repeat(" ", indent))
+		}
+		buf.WriteString(scalar(key))
+		buf.WriteString(":")
+		writeValue(buf, object.values[key], indent)
+	}
+}
+
+func writeList(buf *bytes.Buffer, list []any, indent int) {
+	for _, item := range list {
+		buf.WriteString(strings.Repeat(" ", indent))
+		buf.WriteString("-")
+		switch item := item.(type) {
+		case *orderedMap:
+			if len(item.keys) == 0 {
+				buf.WriteString(" {}\n")
+				continue
+			}
+			buf.WriteString(" ")
+			writeMap(buf, item, indent+2)
+		case []any:
+			if len(item) == 0 {
+				buf.WriteString(" []\n")
+				continue
+			}
+			buf.WriteString("\n")
+			writeList(buf, item, indent+2)
+		default:
+			buf.WriteString(" ")
+			buf.WriteString(scalar(item))
+			buf.WriteString("\n")
+		}
+	}
+}
+
+// writeValue writes the value of a map key, which is already written at indent
+func writeValue(buf *bytes.Buffer, value any, indent int) {
+	switch value := value.(type) {
+	case *orderedMap:
+		if len(value.keys) == 0 {
+			buf.WriteString(" {}\n")
+			return
+		}
+		buf.WriteString("\n")
+		writeMap(buf, value, indent+2)
+	case []any:
+		if len(value) == 0 {
+			buf.WriteString(" []\n")
+			return
+		}
+		buf.WriteString("\n")
+		writeList(buf, value, indent+2)
+	default:
+		buf.WriteString(" ")
+		buf.WriteString(scalar(value))
+		buf.WriteString("\n")
+	}
+}
+
+// scalar formats a json scalar, quoting strings that YAML would otherwise read as something else
+func scalar(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		if value {
+			return "true"
+		}
+		return "false"
+	case json.Number:
+		return value.String()
+	case string:
+		if needsQuotes(value) {
+			return quote(value)
+		}
+		return value
+	}
+	return quote(fmt.Sprint(value))
+}
+
+func needsQuotes(s string) bool {
+	if s == "" || strings.TrimSpace(s) != s {
+		return true
+	}
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", "y", "n", ".inf", "-.inf", ".nan":
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`0123456789+.") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
+
+// quote uses a json string, which is also a valid double quoted YAML string
+func quote(s string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(s); err != nil {
+		return `""`
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
@@ -0,0 +1,340 @@
+package yamlutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// trickyStrings are read as something other than the same string when they are written as plain YAML scalars
+var trickyStrings = []string{
+	"", " padded", "padded ", "on", "Off", "YES", "no", "y", "N", "true", "False", "null", "Null", "~",
+	"1", "-1", "1e3", "1.5", ".5", "+1", "0x1F", "0o17", "0755", "1_000", "1:20", ".inf", "-.Inf", ".NaN",
+	"-", "- item", "-draft", "?", "? key", ":", ":colon", "a: b", "a:", "#tag", "x #y", "[list", "]", "{map", "}", ",",
+	"&anchor", "*alias", "!tag", "|", ">", "'single'", `"double"`, "%directive", "@at", "`tick",
+	"line\nbreak", "tab\tseparated", "bell\a", "{fileId}",
+}
+
+// plainStrings are written without quotes
+var plainStrings = []string{"plain", "a:b", "x#y", "with spaces", "café", "emoji 😀", "posts", "application/json", "back\\slash"}
+
+func TestMarshalRoundTrip(t *testing.T) {
+	values := []any{
+		map[string]any{"a": 1, "b": []any{1, "two", 3.5, true, nil}, "c": map[string]any{"d": map[string]any{}}},
+		[]any{[]any{1, []any{2, []any{}}}, map[string]any{}, []any{map[string]any{"a": []any{map[string]any{"b": 1}}}}},
+		map[string]any{"numbers": []any{0, -2.5, 1e21, 1.5e-7, 9007199254740993}},
+		"scalar",
+		42,
+		nil,
+		map[string]any{},
+		[]any{},
+	}
+	for _, s := range append(trickyStrings, plainStrings...) {
+		values = append(values,
+			s,
+			map[string]any{s: s},
+			[]any{s, map[string]any{s: []any{s}}},
+		)
+	}
+
+	for _, value := range values {
+		data, err := Marshal(value)
+		if err != nil {
+			t.Fatalf("Marshal(%#v): %v", value, err)
+		}
+		decoded, err := decodeYaml(data)
+		if err != nil {
+			t.Errorf("Marshal(%#v) is not valid YAML: %v\n%s", value, err, data)
+			continue
+		}
+		if want := jsonValue(t, value); !reflect.DeepEqual(decoded, want) {
+			t.Errorf("Marshal(%#v) reads back as %#v\n%s", value, decoded, data)
+		}
+	}
+}
+
+func TestMarshalQuoting(t *testing.T) {
+	for _, s := range trickyStrings {
+		if data, _ := Marshal(s); !strings.HasPrefix(string(data), `"`) {
+			t.Errorf("Marshal(%q) = %s, want a quoted string", s, data)
+		}
+	}
+	for _, s := range plainStrings {
+		if data, _ := Marshal(s); string(data) != s+"\n" {
+			t.Errorf("Marshal(%q) = %s, want a plain string", s, data)
+		}
+	}
+}
+
+func TestMarshalOrder(t *testing.T) {
+	type document struct {
+		Title    string         `json:"title"`
+		Items    []any          `json:"items"`
+		Omitted  string         `json:"omitted,omitempty"`
+		Metadata map[string]any `json:"metadata"`
+	}
+	data, err := Marshal(document{
+		Title: "on",
+		Items: []any{1, map[string]any{"y": []any{true}, "x": 1}, []any{}, map[string]any{}, []any{"nested"}},
+		Metadata: map[string]any{
+			"b": nil,
+			"a": "1e3",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	// struct fields keep their order, map keys are sorted like encoding/json
+	want := `title: "on"
+items:
+  - 1
+  - x: 1
+    "y":
+      - true
+  - []
+  - {}
+  -
+    - nested
+metadata:
+  a: "1e3"
+  b: null
+`
+	if string(data) != want {
+		t.Errorf("got\n%s\nwant\n%s", data, want)
+	}
+}
+
+func jsonValue(t *testing.T, value any) any {
+	t.Helper()
+	data, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded any
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+// decodeYaml reads the block style YAML written by Marshal. Plain scalars are resolved with the YAML 1.1 rules,
+// which are the loosest ones, so a string that any common parser would read as a bool, null or number is caught.
+func decodeYaml(data []byte) (any, error) {
+	d := &yamlDecoder{}
+	for number, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		text := strings.TrimLeft(line, " ")
+		if strings.HasPrefix(text, "\t") {
+			return nil, fmt.Errorf("line %d: tabs cannot indent", number+1)
+		}
+		d.lines = append(d.lines, yamlLine{indent: len(line) - len(text), text: text, number: number + 1})
+	}
+	if len(d.lines) == 1 && !d.isListItem(0) {
+		if _, _, ok, _ := splitKey(d.lines[0].text); !ok {
+			return parseScalar(d.lines[0].text)
+		}
+	}
+	value, err := d.parseBlock(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.pos < len(d.lines) {
+		return nil, fmt.Errorf("line %d: unexpected indentation", d.lines[d.pos].number)
+	}
+	return value, nil
+}
+
+type yamlLine struct {
+	indent int
+	text   string
+	number int
+}
+
+type yamlDecoder struct {
+	lines []yamlLine
+	pos   int
+}
+
+func (d *yamlDecoder) isListItem(pos int) bool {
+	text := d.lines[pos].text
+	return text == "-" || strings.HasPrefix(text, "- ")
+}
+
+func (d *yamlDecoder) parseBlock(indent int) (any, error) {
+	if d.isListItem(d.pos) {
+		return d.parseList(indent)
+	}
+	return d.parseMap(indent)
+}
+
+func (d *yamlDecoder) parseMap(indent int) (any, error) {
+	object := map[string]any{}
+	for d.pos < len(d.lines) && d.lines[d.pos].indent == indent && !d.isListItem(d.pos) {
+		line := d.lines[d.pos]
+		key, rest, ok, err := splitKey(line.text)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected a key in %q", line.number, line.text)
+		}
+		if _, exists := object[key]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", line.number, key)
+		}
+		d.pos++
+		value, err := d.parseValue(rest, indent)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		object[key] = value
+	}
+	return object, nil
+}
+
+func (d *yamlDecoder) parseList(indent int) (any, error) {
+	list := []any{}
+	for d.pos < len(d.lines) && d.lines[d.pos].indent == indent && d.isListItem(d.pos) {
+		line := d.lines[d.pos]
+		rest := strings.TrimPrefix(strings.TrimPrefix(line.text, "-"), " ")
+		if _, _, ok, _ := splitKey(rest); ok {
+			// a map whose first key is on the line of the dash
+			d.lines[d.pos] = yamlLine{indent: indent + 2, text: rest, number: line.number}
+			item, err := d.parseMap(indent + 2)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+			continue
+		}
+		d.pos++
+		item, err := d.parseValue(rest, indent)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line.number, err)
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// parseValue parses the value after a key or dash, which is a block on the next lines when rest is empty
+func (d *yamlDecoder) parseValue(rest string, indent int) (any, error) {
+	if rest != "" {
+		return parseScalar(rest)
+	}
+	if d.pos < len(d.lines) && d.lines[d.pos].indent > indent {
+		return d.parseBlock(d.lines[d.pos].indent)
+	}
+	return nil, nil
+}
+
+// splitKey splits "key: value" into the key and the value, ok is false when text is not a key
+func splitKey(text string) (string, string, bool, error) {
+	var key, rest string
+	if strings.HasPrefix(text, `"`) {
+		end := quotedEnd(text)
+		if end == -1 || !strings.HasPrefix(text[end:], ":") {
+			return "", "", false, nil
+		}
+		if err := json.Unmarshal([]byte(text[:end]), &key); err != nil {
+			return "", "", false, err
+		}
+		rest = text[end+1:]
+	} else {
+		i := strings.Index(text, ": ")
+		if i == -1 && strings.HasSuffix(text, ":") {
+			i = len(text) - 1
+		}
+		if i == -1 {
+			return "", "", false, nil
+		}
+		value, err := parseScalar(text[:i])
+		if err != nil {
+			return "", "", false, err
+		}
+		var isString bool
+		if key, isString = value.(string); !isString {
+			return "", "", false, fmt.Errorf("key %q is read as %#v", text[:i], value)
+		}
+		rest = text[i+1:]
+	}
+	if rest != "" && !strings.HasPrefix(rest, " ") {
+		return "", "", false, nil
+	}
+	return key, strings.TrimPrefix(rest, " "), true, nil
+}
+
+// quotedEnd returns the index after the double quoted string text starts with, or -1
+func quotedEnd(text string) int {
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		}
+	}
+	return -1
+}
+
+var (
+	yamlNulls = []string{"", "~", "null", "Null", "NULL"}
+	yamlBools = map[string]bool{
+		"y": true, "Y": true, "yes": true, "Yes": true, "YES": true, "true": true, "True": true, "TRUE": true, "on": true, "On": true, "ON": true,
+		"n": false, "N": false, "no": false, "No": false, "NO": false, "false": false, "False": false, "FALSE": false, "off": false, "Off": false, "OFF": false,
+	}
+	jsonNumber = regexp.MustCompile(`-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][-+]?[0-9]+)?`)
+	yamlNumber = regexp.MustCompile(`^([-+]?(\.[0-9]+|[0-9][0-9_]*(\.[0-9_]*)?)([eE][-+]?[0-9]+)?|[-+]?0x[0-9a-fA-F_]+|0o[0-7]+|[-+]?[0-9][0-9_]*(:[0-5]?[0-9])+(\.[0-9_]*)?|[-+]?\.(inf|Inf|INF)|\.(nan|NaN|NAN))$`)
+)
+
+// yamlOther is a plain scalar that YAML reads as a number encoding/json would not write
+type yamlOther string
+
+func parseScalar(text string) (any, error) {
+	switch {
+	case text == "{}":
+		return map[string]any{}, nil
+	case text == "[]":
+		return []any{}, nil
+	case strings.HasPrefix(text, `"`):
+		if quotedEnd(text) != len(text) {
+			return nil, fmt.Errorf("unexpected text after %q", text)
+		}
+		var s string
+		err := json.Unmarshal([]byte(text), &s)
+		return s, err
+	}
+
+	// the first character of a plain scalar cannot be an indicator, except -, ? and : followed by a non space
+	if strings.ContainsAny(text[:1], ",[]{}#&*!|>'\"%@`") ||
+		(strings.ContainsAny(text[:1], "-?:") && (len(text) == 1 || text[1] == ' ')) {
+		return nil, fmt.Errorf("plain scalar %q starts with an indicator", text)
+	}
+	if strings.Contains(text, ": ") || strings.HasSuffix(text, ":") {
+		return nil, fmt.Errorf("plain scalar %q contains a mapping", text)
+	}
+	if i := strings.Index(text, " #"); i != -1 {
+		text = text[:i]
+	}
+	text = strings.TrimRight(text, " ")
+
+	for _, null := range yamlNulls {
+		if text == null {
+			return nil, nil
+		}
+	}
+	if b, ok := yamlBools[text]; ok {
+		return b, nil
+	}
+	if yamlNumber.MatchString(text) {
+		// leading zeros are octal in YAML 1.1
+		number, err := strconv.ParseFloat(text, 64)
+		if err != nil || strings.ContainsAny(text, "_xo:") || jsonNumber.FindString(text) != text {
+			return yamlOther(text), nil
+		}
+		return number, nil
+	}
+	return text, nil
+}
@@ -22,3 +22,20 @@ RETURNING *;
 -- name: DeleteJsonFile :exec
 DELETE FROM json_files
 WHERE id=$1;
+
+-- name: UpdateJsonFileSettings :one
+UPDATE json_files
+SET id_strategy=$2, id_field=$3, resource_id_fields=$4, foreign_key_suffix=$5, updated_at=NOW()
+WHERE id=$1
+RETURNING *;
+
+-- name: UpdateJsonFileRelationships :one
+UPDATE json_files
+SET relationships=$2, updated_at=NOW()
+WHERE id=$1
+RETURNING *;
+
+-- name: UpdateJsonFileUrl :exec
+UPDATE json_files
+SET url=$2
+WHERE id=$1;
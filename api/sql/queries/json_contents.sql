@@ -0,0 +1,20 @@
+-- name: UpsertJsonContents :exec
+INSERT INTO json_contents (key, contents, raw)
+VALUES (@key, @raw::text::jsonb, @raw::text)
+ON CONFLICT (key)
+DO UPDATE SET contents=EXCLUDED.contents, raw=EXCLUDED.raw, updated_at=NOW();
+
+-- name: GetJsonContents :one
+SELECT COALESCE(raw, contents::text)::text AS contents
+FROM json_contents
+WHERE key=$1;
+
+-- name: DeleteJsonContents :exec
+DELETE FROM json_contents
+WHERE key=$1;
+
+-- name: ListJsonContentsKeys :many
+SELECT key
+FROM json_contents
+WHERE starts_with(key, @prefix::text)
+ORDER BY key COLLATE "C";
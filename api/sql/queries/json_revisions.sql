@@ -0,0 +1,39 @@
+-- name: CreateJsonRevision :one
+INSERT INTO json_revisions (json_file_id, revision, user_id, source, api_key_id, api_key_name, size, checksum)
+VALUES ($1, (SELECT COALESCE(MAX(revision), 0) + 1 FROM json_revisions WHERE json_file_id=$1), $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetJsonRevisions :many
+SELECT *
+FROM json_revisions
+WHERE json_file_id=$1
+ORDER BY revision DESC;
+
+-- name: GetJsonRevision :one
+SELECT *
+FROM json_revisions
+WHERE json_file_id=$1 AND revision=$2;
+
+-- name: DeleteJsonRevision :exec
+DELETE FROM json_revisions
+WHERE json_file_id=$1 AND revision=$2;
+
+-- name: DeleteOldJsonRevisions :many
+DELETE FROM json_revisions
+WHERE json_file_id=@json_file_id
+AND revision <= (SELECT MAX(revision) FROM json_revisions WHERE json_file_id=@json_file_id) - @keep::int
+AND revision NOT IN (SELECT revision FROM json_snapshots WHERE json_file_id=@json_file_id)
+RETURNING revision;
+
+-- name: GetLatestJsonRevision :one
+SELECT *
+FROM json_revisions
+WHERE json_file_id=$1
+ORDER BY revision DESC
+LIMIT 1;
+
+-- name: GetLatestJsonRevisionSizes :many
+SELECT DISTINCT ON (json_file_id) json_file_id, size
+FROM json_revisions
+WHERE json_file_id IN (SELECT id FROM json_files WHERE user_id=$1)
+ORDER BY json_file_id, revision DESC;
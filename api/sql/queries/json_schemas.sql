@@ -0,0 +1,16 @@
+-- name: UpsertJsonSchema :one
+INSERT INTO json_schemas (json_file_id, resource, schema)
+VALUES ($1, $2, $3)
+ON CONFLICT (json_file_id, resource)
+DO UPDATE SET schema=EXCLUDED.schema, updated_at=NOW()
+RETURNING *;
+
+-- name: GetJsonSchemas :many
+SELECT *
+FROM json_schemas
+WHERE json_file_id=$1
+ORDER BY resource;
+
+-- name: DeleteJsonSchema :exec
+DELETE FROM json_schemas
+WHERE json_file_id=$1 AND resource=$2;
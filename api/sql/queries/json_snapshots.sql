@@ -0,0 +1,21 @@
+-- name: UpsertJsonSnapshot :one
+INSERT INTO json_snapshots (json_file_id, name, revision)
+VALUES ($1, $2, $3)
+ON CONFLICT (json_file_id, name)
+DO UPDATE SET revision=EXCLUDED.revision, updated_at=NOW()
+RETURNING *;
+
+-- name: GetJsonSnapshots :many
+SELECT *
+FROM json_snapshots
+WHERE json_file_id=$1
+ORDER BY name;
+
+-- name: GetJsonSnapshot :one
+SELECT *
+FROM json_snapshots
+WHERE json_file_id=$1 AND name=$2;
+
+-- name: DeleteJsonSnapshot :exec
+DELETE FROM json_snapshots
+WHERE json_file_id=$1 AND name=$2;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE json_files
+ADD id_strategy TEXT NOT NULL DEFAULT 'increment';
+
+-- +goose Down
+ALTER TABLE json_files
+DROP COLUMN id_strategy;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE json_files
+ADD id_field TEXT NOT NULL DEFAULT 'id',
+ADD resource_id_fields JSONB NOT NULL DEFAULT '{}';
+
+-- +goose Down
+ALTER TABLE json_files
+DROP COLUMN id_field,
+DROP COLUMN resource_id_fields;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE json_files
+ADD foreign_key_suffix TEXT NOT NULL DEFAULT 'Id';
+
+-- +goose Down
+ALTER TABLE json_files
+DROP COLUMN foreign_key_suffix;
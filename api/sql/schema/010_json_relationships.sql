@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE json_files
+ADD relationships JSONB NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE json_files
+DROP COLUMN relationships;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE json_schemas (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  json_file_id UUID NOT NULL,
+  -- empty for the schema of the whole file
+  resource TEXT NOT NULL DEFAULT '',
+  schema JSONB NOT NULL,
+  CONSTRAINT fk_json_file
+  FOREIGN KEY (json_file_id) REFERENCES json_files(id)
+  ON DELETE CASCADE,
+  UNIQUE (json_file_id, resource)
+);
+
+-- +goose Down
+DROP TABLE json_schemas;
@@ -0,0 +1,27 @@
+-- +goose Up
+CREATE TABLE json_revisions (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  json_file_id UUID NOT NULL,
+  revision INTEGER NOT NULL,
+  user_id UUID NOT NULL,
+  -- 'session' for writes from the web app, 'api_key' for writes through the public api
+  source TEXT NOT NULL,
+  -- the name is kept after the api key is deleted
+  api_key_id UUID,
+  api_key_name TEXT NOT NULL DEFAULT '',
+  size BIGINT NOT NULL,
+  CONSTRAINT fk_json_file
+  FOREIGN KEY (json_file_id) REFERENCES json_files(id)
+  ON DELETE CASCADE,
+  CONSTRAINT fk_user
+  FOREIGN KEY (user_id) REFERENCES users(id)
+  ON DELETE CASCADE,
+  CONSTRAINT fk_api_key
+  FOREIGN KEY (api_key_id) REFERENCES api_keys(id)
+  ON DELETE SET NULL,
+  UNIQUE (json_file_id, revision)
+);
+
+-- +goose Down
+DROP TABLE json_revisions;
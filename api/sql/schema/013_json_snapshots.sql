@@ -0,0 +1,19 @@
+-- +goose Up
+CREATE TABLE json_snapshots (
+  id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  json_file_id UUID NOT NULL,
+  name TEXT NOT NULL,
+  revision INTEGER NOT NULL,
+  CONSTRAINT fk_json_file
+  FOREIGN KEY (json_file_id) REFERENCES json_files(id)
+  ON DELETE CASCADE,
+  CONSTRAINT fk_json_revision
+  FOREIGN KEY (json_file_id, revision) REFERENCES json_revisions(json_file_id, revision)
+  ON DELETE CASCADE,
+  UNIQUE (json_file_id, name)
+);
+
+-- +goose Down
+DROP TABLE json_snapshots;
@@ -0,0 +1,12 @@
+-- +goose Up
+-- contents of json files and their revisions when STORAGE_BACKEND is postgres,
+-- keyed like objects in the bucket, e.g. {userId}/{fileId}.json
+CREATE TABLE json_contents (
+  key TEXT PRIMARY KEY,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  contents JSONB NOT NULL
+);
+
+-- +goose Down
+DROP TABLE json_contents;
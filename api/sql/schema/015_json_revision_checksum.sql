@@ -0,0 +1,8 @@
+-- +goose Up
+-- hex encoded sha256 of the stored contents, empty for revisions recorded before checksums
+ALTER TABLE json_revisions
+ADD checksum TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE json_revisions
+DROP COLUMN checksum;
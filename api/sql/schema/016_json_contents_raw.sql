@@ -0,0 +1,9 @@
+-- +goose Up
+-- contents exactly as they were written, since jsonb normalizes whitespace and key order,
+-- null for contents saved before this column
+ALTER TABLE json_contents
+ADD raw TEXT;
+
+-- +goose Down
+ALTER TABLE json_contents
+DROP COLUMN raw;